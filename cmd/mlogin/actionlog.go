@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// ActionRecord is one mutating action mlogin performed, kept so shared
+// admin machines have a trail and changes can be undone by hand.
+type ActionRecord struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	UID    int       `json:"uid"`
+	Action string    `json:"action"`
+	Target string    `json:"target"`
+	Scope  string    `json:"scope,omitempty"`
+	// Previous is the state before the action, when it could be read.
+	Previous string `json:"previous,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func actionLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Application Support", "mlogin", "actions.jsonl"), nil
+}
+
+// actionUser names who ran mlogin, including the admin behind sudo.
+func actionUser() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != name {
+		name += " (sudo by " + sudoUser + ")"
+	}
+	return name
+}
+
+// recordAction appends the outcome of an action to the JSONL log and the
+// unified log. The action has already happened, so failing to record it
+// only warns. Nothing is recorded under --dry-run.
+func recordAction(action, target, scope, previous string, actionErr error) {
+	if dryRun {
+		return
+	}
+	r := ActionRecord{
+		Time:     time.Now().UTC(),
+		User:     actionUser(),
+		UID:      os.Getuid(),
+		Action:   action,
+		Target:   target,
+		Scope:    scope,
+		Previous: previous,
+	}
+	if actionErr != nil {
+		r.Error = actionErr.Error()
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	// logger hands the message to os_log on current macOS versions.
+	exec.Command("logger", "-t", "mlogin", string(data)).Run()
+
+	path, err := actionLogPath()
+	if err == nil {
+		err = appendActionLog(path, data)
+	}
+	if err != nil {
+		warn("could not record action:", err)
+	}
+}
+
+func appendActionLog(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readActionLog(path string) ([]ActionRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []ActionRecord
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var r ActionRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+// launchdPreviousState reads whether label is enabled or disabled in
+// domain before it is changed; it is empty when that cannot be read.
+func launchdPreviousState(c mlogin.Client, domain, label string) string {
+	disabled, err := getDisabledLabels(c, domain)
+	if err != nil {
+		return ""
+	}
+	if v, ok := disabled[label]; ok && v {
+		return "disabled"
+	}
+	return "enabled"
+}
+
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	n := fs.Int("n", 50, "show at most this many of the most recent actions (0 for all)")
+	target := fs.String("target", "", "only show actions whose target contains this")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := actionLogPath()
+	if err != nil {
+		return err
+	}
+	records, err := readActionLog(path)
+	if err != nil {
+		return err
+	}
+	records = filterActions(records, *target, *n)
+	if *jsonOut || outputFormat != "" {
+		if records == nil {
+			records = []ActionRecord{}
+		}
+		return writeOutput(records)
+	}
+	printActionRecords(records)
+	return nil
+}
+
+// filterActions keeps the records whose target contains target, then the
+// last n of them.
+func filterActions(records []ActionRecord, target string, n int) []ActionRecord {
+	var out []ActionRecord
+	for _, r := range records {
+		if target == "" || strings.Contains(r.Target, target) {
+			out = append(out, r)
+		}
+	}
+	if n > 0 && len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
+
+func printActionRecords(records []ActionRecord) {
+	if len(records) == 0 {
+		fmt.Println("No actions recorded")
+		return
+	}
+	fmt.Printf("%-19s %-12s %-20s %-10s %-8s %s\n", "TIME", "USER", "ACTION", "PREVIOUS", "RESULT", "TARGET")
+	for _, r := range records {
+		result := "ok"
+		if r.Error != "" {
+			result = "failed"
+		}
+		fmt.Printf("%-19s %-12s %-20s %-10s %-8s %s\n", r.Time.Local().Format("2006-01-02 15:04:05"), r.User, r.Action, orDash(r.Previous), result, r.Target)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestActionLogRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mlogin", "actions.jsonl")
+	for i, target := range []string{"gui/501/com.a", "gui/501/com.b", "gui/501/com.a"} {
+		data, err := json.Marshal(ActionRecord{Time: time.Unix(int64(i), 0).UTC(), Action: "background disable", Target: target, Previous: "enabled"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := appendActionLog(path, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := readActionLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[2].Previous != "enabled" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	filtered := filterActions(records, "com.a", 1)
+	if len(filtered) != 1 || filtered[0].Time.Unix() != 2 {
+		t.Fatalf("expected the latest com.a action, got %+v", filtered)
+	}
+
+	if missing, err := readActionLog(filepath.Join(t.TempDir(), "none.jsonl")); err != nil || missing != nil {
+		t.Fatalf("expected no records for a missing log, got %v, %v", missing, err)
+	}
+}
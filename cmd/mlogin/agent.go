@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// mloginAgentLabel is the LaunchAgent that keeps mlogin itself running.
+const mloginAgentLabel = "io.github.j4n-e4t.mlogin.agent"
+
+type AgentStatus struct {
+	Label     string   `json:"label"`
+	Path      string   `json:"path"`
+	Installed bool     `json:"installed"`
+	Loaded    bool     `json:"loaded"`
+	State     string   `json:"state,omitempty"`
+	Command   []string `json:"command,omitempty"`
+	Log       string   `json:"log,omitempty"`
+}
+
+func runAgent(args []string) error {
+	c := client()
+	if len(args) == 0 {
+		return usagef("missing agent subcommand")
+	}
+	switch args[0] {
+	case "install":
+		fs := flag.NewFlagSet("agent install", flag.ContinueOnError)
+		mode := fs.String("mode", "watch", "watch (run mlogin watch --notify continuously) or baseline (run mlogin baseline check on a schedule)")
+		interval := fs.Duration("interval", time.Hour, "how often baseline mode checks")
+		force := fs.Bool("force", false, "replace an existing agent")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return installAgent(c, *mode, *interval, *force)
+	case "uninstall":
+		fs := flag.NewFlagSet("agent uninstall", flag.ContinueOnError)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return uninstallAgent(c)
+	case "status":
+		fs := flag.NewFlagSet("agent status", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		status, err := agentStatus(c)
+		if err != nil {
+			return err
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(status)
+		}
+		printAgentStatus(status)
+		return nil
+	default:
+		return usagef("unknown agent subcommand %q", args[0])
+	}
+}
+
+func agentLogPath() (string, error) {
+	home, err := launchUserHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Logs", "mlogin-agent.log"), nil
+}
+
+// agentPlist builds the LaunchAgent for mode. Watch mode stays running and
+// is restarted by launchd if it exits; baseline mode runs once per interval.
+func agentPlist(exe, mode string, interval time.Duration, logPath string) (map[string]any, error) {
+	spec := backgroundSpec{label: mloginAgentLabel, program: exe, runAtLoad: true, logPath: logPath}
+	switch mode {
+	case "watch":
+		spec.args = []string{"watch", "--notify"}
+	case "baseline":
+		if interval < time.Minute {
+			return nil, usagef("--interval must be at least 1m")
+		}
+		spec.args = []string{"baseline", "check"}
+		spec.interval = int(interval.Seconds())
+	default:
+		return nil, usagef("--mode must be watch or baseline")
+	}
+	m := spec.launchdPlist()
+	if mode == "watch" {
+		m["KeepAlive"] = true
+		// Don't respawn faster than this if watch keeps failing.
+		m["ThrottleInterval"] = 60
+	}
+	m["ProcessType"] = "Background"
+	return m, nil
+}
+
+// stableExecutable returns a path to exe that survives upgrades: a binary
+// inside a Homebrew Cellar is replaced by the prefix's bin/ symlink, since
+// brew upgrade deletes the versioned Cellar directory. Other paths are kept
+// as given; resolving symlinks would lead into the Cellar.
+func stableExecutable(exe string) string {
+	prefix, _, ok := strings.Cut(exe, "/Cellar/")
+	if !ok {
+		return exe
+	}
+	link := filepath.Join(prefix, "bin", filepath.Base(exe))
+	if target, err := filepath.EvalSymlinks(link); err == nil && filepath.Base(target) == filepath.Base(exe) {
+		return link
+	}
+	return exe
+}
+
+func installAgent(c mlogin.Client, mode string, interval time.Duration, force bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	exe = stableExecutable(exe)
+	if strings.HasPrefix(exe, os.TempDir()) || strings.Contains(exe, "/go-build") {
+		return fmt.Errorf("%s looks like a temporary build; install mlogin first so the agent has a stable path", exe)
+	}
+	logPath, err := agentLogPath()
+	if err != nil {
+		return err
+	}
+	m, err := agentPlist(exe, mode, interval, logPath)
+	if err != nil {
+		return err
+	}
+	path, err := plistPathFor(mloginAgentLabel, "user")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("agent already installed at %s (use --force to replace it)", path)
+	}
+	data, err := encodePlistXML(m)
+	if err != nil {
+		return err
+	}
+	if !dryRunf("write %s", path) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("write plist %s: %w", path, err)
+		}
+	}
+	successf("created %s", path)
+	return reloadBackgroundPlist(c, BackgroundItem{Label: mloginAgentLabel, Path: path, Scope: "user"})
+}
+
+func uninstallAgent(c mlogin.Client) error {
+	path, err := plistPathFor(mloginAgentLabel, "user")
+	if err != nil {
+		return err
+	}
+	domain, err := launchDomain(c, "user")
+	if err != nil {
+		return err
+	}
+	if err := c.UnloadService(context.Background(), domain+"/"+mloginAgentLabel); err != nil && !isIgnorableBootoutError(err) {
+		return fmt.Errorf("bootout failed for %s: %w", mloginAgentLabel, err)
+	}
+	if dryRunf("remove %s", path) {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return errors.New("agent is not installed")
+		}
+		return err
+	}
+	successf("removed %s", path)
+	return nil
+}
+
+func agentStatus(c mlogin.Client) (AgentStatus, error) {
+	path, err := plistPathFor(mloginAgentLabel, "user")
+	if err != nil {
+		return AgentStatus{}, err
+	}
+	status := AgentStatus{Label: mloginAgentLabel, Path: path}
+	if plist, err := readPlist(path); err == nil {
+		status.Installed = true
+		status.Command = plistStrings(plist, "ProgramArguments")
+		status.Log = plistString(plist, "StandardOutPath")
+	} else if _, statErr := os.Stat(path); statErr == nil {
+		status.Installed = true
+	}
+	if b, err := printLaunchService(c, BackgroundItem{Label: mloginAgentLabel, Scope: "user"}); err == nil {
+		status.Loaded = true
+		status.State = b.values["state"]
+	}
+	return status, nil
+}
+
+func printAgentStatus(s AgentStatus) {
+	if !s.Installed {
+		fmt.Println("mlogin agent is not installed (run mlogin agent install)")
+		return
+	}
+	fmt.Printf("Label:   %s\n", s.Label)
+	fmt.Printf("Plist:   %s\n", s.Path)
+	fmt.Printf("Command: %s\n", orDash(shellJoin(s.Command)))
+	fmt.Printf("Loaded:  %t\n", s.Loaded)
+	if s.Loaded {
+		fmt.Printf("State:   %s\n", orDash(s.State))
+	}
+	fmt.Printf("Log:     %s\n", orDash(s.Log))
+}
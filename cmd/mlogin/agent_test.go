@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAgentPlist(t *testing.T) {
+	m, err := agentPlist("/usr/local/bin/mlogin", "watch", 0, "/tmp/mlogin-agent.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m["ProgramArguments"].([]string), []string{"/usr/local/bin/mlogin", "watch", "--notify"}) || m["KeepAlive"] != true {
+		t.Fatalf("unexpected watch agent: %v", m)
+	}
+	if _, ok := m["StartInterval"]; ok {
+		t.Fatalf("watch agent should not have a StartInterval: %v", m)
+	}
+
+	m, err = agentPlist("/usr/local/bin/mlogin", "baseline", 2*time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m["ProgramArguments"].([]string), []string{"/usr/local/bin/mlogin", "baseline", "check"}) || m["StartInterval"] != 7200 {
+		t.Fatalf("unexpected baseline agent: %v", m)
+	}
+	if _, ok := m["KeepAlive"]; ok {
+		t.Fatalf("baseline agent should not be kept alive: %v", m)
+	}
+
+	if _, err := agentPlist("/usr/local/bin/mlogin", "baseline", time.Second, ""); err == nil {
+		t.Fatalf("expected an error for a sub-minute interval")
+	}
+	if _, err := agentPlist("/usr/local/bin/mlogin", "daemon", 0, ""); err == nil {
+		t.Fatalf("expected an error for an unknown mode")
+	}
+}
+
+func TestStableExecutable(t *testing.T) {
+	prefix := t.TempDir()
+	exe := filepath.Join(prefix, "Cellar", "mlogin", "1.2.0", "bin", "mlogin")
+	if err := os.MkdirAll(filepath.Dir(exe), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exe, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := stableExecutable(exe); got != exe {
+		t.Fatalf("without a bin/ link, stableExecutable = %q", got)
+	}
+	link := filepath.Join(prefix, "bin", "mlogin")
+	if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../Cellar/mlogin/1.2.0/bin/mlogin", link); err != nil {
+		t.Fatal(err)
+	}
+	if got := stableExecutable(exe); got != link {
+		t.Fatalf("stableExecutable = %q, want %q", got, link)
+	}
+	if got := stableExecutable("/usr/local/bin/mlogin"); got != "/usr/local/bin/mlogin" {
+		t.Fatalf("stableExecutable = %q", got)
+	}
+}
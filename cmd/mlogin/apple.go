@@ -0,0 +1,49 @@
+package main
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed apple_labels.txt
+var appleLabelsFile string
+
+var appleLabels = func() map[string]bool {
+	m := map[string]bool{}
+	for _, label := range parseLabelLines(appleLabelsFile) {
+		m[label] = true
+	}
+	return m
+}()
+
+// parseLabelLines reads one label per line, skipping blanks and # comments.
+func parseLabelLines(data string) []string {
+	var labels []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		labels = append(labels, line)
+	}
+	return labels
+}
+
+// isAppleLabel reports whether label belongs to Apple or another first-party
+// component shipped with macOS.
+func isAppleLabel(label string) bool {
+	return strings.HasPrefix(label, "com.apple.") || appleLabels[label]
+}
+
+// withoutAppleItems drops Apple/first-party items so third-party entries
+// stand out.
+func withoutAppleItems(items []BackgroundItem) []BackgroundItem {
+	out := make([]BackgroundItem, 0, len(items))
+	for _, it := range items {
+		if it.Provenance == "apple" || isAppleLabel(it.Label) {
+			continue
+		}
+		out = append(out, it)
+	}
+	return out
+}
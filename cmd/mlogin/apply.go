@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// Manifest declares the desired login items and launchd item states for
+// `mlogin apply`.
+type Manifest struct {
+	LoginItems []ManifestLoginItem `json:"login_items"`
+	Background struct {
+		// Enabled and Disabled hold labels or glob patterns.
+		Enabled  []string `json:"enabled"`
+		Disabled []string `json:"disabled"`
+	} `json:"background"`
+	Policy struct {
+		// UnlistedLoginItems is "keep" (default) or "remove".
+		UnlistedLoginItems string `json:"unlisted_login_items"`
+		// UnlistedBackground is "keep" (default) or "disable".
+		UnlistedBackground string `json:"unlisted_background"`
+	} `json:"policy"`
+}
+
+type ManifestLoginItem struct {
+	Path   string `json:"path"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+// UnmarshalJSON also accepts a bare path.
+func (it *ManifestLoginItem) UnmarshalJSON(data []byte) error {
+	var p string
+	if err := json.Unmarshal(data, &p); err == nil {
+		*it = ManifestLoginItem{Path: p}
+		return nil
+	}
+	type plain ManifestLoginItem
+	return json.Unmarshal(data, (*plain)(it))
+}
+
+// applyAction is one change needed to converge on a manifest.
+type applyAction struct {
+	verb  string // add, remove, enable, disable
+	login LoginItem
+	item  BackgroundItem
+}
+
+func (a applyAction) String() string {
+	switch a.verb {
+	case "add", "remove":
+		return a.verb + " login item " + a.login.Path
+	default:
+		return a.verb + " " + a.item.Label + " (" + a.item.Scope + ")"
+	}
+}
+
+func runApply(args []string) error {
+	c := client()
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "apply without asking")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		return usagef("usage: mlogin apply <manifest.yaml> [--dry-run] [--yes]")
+	}
+	m, err := readManifest(file)
+	if err != nil {
+		return err
+	}
+	return applyManifest(c, m, *yes)
+}
+
+// applyManifest converges the current login and third-party background
+// items on m.
+func applyManifest(c mlogin.Client, m Manifest, yes bool) error {
+	login, err := listLoginItems(c)
+	if err != nil {
+		return err
+	}
+	items, _, err := listBackgroundItems(c, "all", false)
+	if err != nil {
+		return err
+	}
+	actions, warnings, err := planApply(m, login, withoutAppleItems(items))
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		warn(w)
+	}
+	return executeApply(c, actions, yes)
+}
+
+func readManifest(file string) (Manifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var m Manifest
+	if err := readYAML(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("parse %s: %w", file, err)
+	}
+	// Relative login item paths are relative to the manifest, not to
+	// wherever mlogin runs; System Events reports absolute ones.
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return Manifest{}, err
+	}
+	for i, it := range m.LoginItems {
+		if !filepath.IsAbs(it.Path) {
+			m.LoginItems[i].Path = filepath.Join(dir, it.Path)
+		}
+	}
+	return m, nil
+}
+
+// planApply compares the manifest with the current login and background
+// items and returns the actions that converge them, plus warnings for
+// manifest entries that match nothing.
+func planApply(m Manifest, login []LoginItem, items []BackgroundItem) ([]applyAction, []string, error) {
+	switch m.Policy.UnlistedLoginItems {
+	case "", "keep", "remove":
+	default:
+		return nil, nil, fmt.Errorf("policy.unlisted_login_items must be keep or remove, not %q", m.Policy.UnlistedLoginItems)
+	}
+	switch m.Policy.UnlistedBackground {
+	case "", "keep", "disable":
+	default:
+		return nil, nil, fmt.Errorf("policy.unlisted_background must be keep or disable, not %q", m.Policy.UnlistedBackground)
+	}
+	for _, p := range append(append([]string{}, m.Background.Enabled...), m.Background.Disabled...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, nil, fmt.Errorf("invalid label pattern %q: %w", p, err)
+		}
+	}
+
+	var actions []applyAction
+	var warnings []string
+	present := map[string]bool{}
+	for _, it := range login {
+		present[filepath.Clean(it.Path)] = true
+	}
+	wanted := map[string]bool{}
+	for _, it := range m.LoginItems {
+		p := filepath.Clean(it.Path)
+		wanted[p] = true
+		if !present[p] {
+			actions = append(actions, applyAction{verb: "add", login: LoginItem{Name: strings.TrimSuffix(filepath.Base(p), ".app"), Path: p, Hidden: it.Hidden}})
+		}
+	}
+	if m.Policy.UnlistedLoginItems == "remove" {
+		for _, it := range login {
+			if !wanted[filepath.Clean(it.Path)] {
+				actions = append(actions, applyAction{verb: "remove", login: it})
+			}
+		}
+	}
+
+	matched := map[string]bool{}
+	matchAny := func(patterns []string, label string) bool {
+		hit := false
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, label); ok {
+				matched[p] = true
+				hit = true
+			}
+		}
+		return hit
+	}
+	for _, it := range items {
+		if it.Provenance == "legacy" {
+			continue
+		}
+		enable := matchAny(m.Background.Enabled, it.Label)
+		disable := matchAny(m.Background.Disabled, it.Label)
+		if enable && disable {
+			return nil, nil, fmt.Errorf("%s matches both background.enabled and background.disabled", it.Label)
+		}
+		if !enable && !disable && m.Policy.UnlistedBackground == "disable" {
+			disable = true
+		}
+		isDisabled := it.Disabled != nil && *it.Disabled
+		switch {
+		case enable && isDisabled:
+			actions = append(actions, applyAction{verb: "enable", item: it})
+		case disable && !isDisabled:
+			actions = append(actions, applyAction{verb: "disable", item: it})
+		}
+	}
+	for _, p := range append(append([]string{}, m.Background.Enabled...), m.Background.Disabled...) {
+		if !matched[p] {
+			warnings = append(warnings, fmt.Sprintf("no background item matches %q", p))
+		}
+	}
+	return actions, warnings, nil
+}
+
+// executeApply lists the planned changes, asks unless yes is set, and
+// applies them, continuing past individual failures.
+func executeApply(c mlogin.Client, actions []applyAction, yes bool) error {
+	if len(actions) == 0 {
+		successf("Already up to date")
+		return nil
+	}
+	fmt.Printf("%d change(s):\n", len(actions))
+	for _, a := range actions {
+		fmt.Printf("  %s\n", a)
+	}
+	if err := confirmAction("Apply these changes?", yes); err != nil {
+		return err
+	}
+	failed := 0
+	for _, a := range actions {
+		var err error
+		switch a.verb {
+		case "add":
+			if err = addLoginItem(c, a.login.Path, a.login.Hidden); err == nil {
+				successf("added login item: %s", a.login.Path)
+			}
+		case "remove":
+			// By path only: another login item may share the name.
+			if err = removeLoginItem(c, "", a.login.Path); err == nil {
+				successf("removed login item: %s", a.login.Path)
+			}
+		default:
+			err = runBackgroundVerb(c, a.verb, a.item, false)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: %v\n", a, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d change(s) failed", failed, len(actions))
+	}
+	return nil
+}
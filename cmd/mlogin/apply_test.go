@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func TestPlanApply(t *testing.T) {
+	var m Manifest
+	doc := `login_items:
+  - /Applications/Slack.app
+  - path: /Applications/Rectangle.app
+    hidden: true
+background:
+  enabled: [com.example.agent]
+  disabled: ["com.adobe.*", com.missing.agent]
+policy:
+  unlisted_login_items: remove
+`
+	if err := readYAML([]byte(doc), &m); err != nil {
+		t.Fatalf("readYAML: %v", err)
+	}
+	yes, no := true, false
+	login := []LoginItem{
+		{Name: "Slack", Path: "/Applications/Slack.app"},
+		{Name: "Spotify", Path: "/Applications/Spotify.app"},
+	}
+	items := []BackgroundItem{
+		{Label: "com.example.agent", Scope: "user", Disabled: &yes},
+		{Label: "com.adobe.ccxprocess", Scope: "user", Disabled: &no},
+		{Label: "com.adobe.already", Scope: "user", Disabled: &yes},
+		{Label: "com.other.agent", Scope: "user", Disabled: &no},
+	}
+	actions, warnings, err := planApply(m, login, items)
+	if err != nil {
+		t.Fatalf("planApply: %v", err)
+	}
+	var got []string
+	for _, a := range actions {
+		got = append(got, a.String())
+	}
+	want := []string{
+		"add login item /Applications/Rectangle.app",
+		"remove login item /Applications/Spotify.app",
+		"enable com.example.agent (user)",
+		"disable com.adobe.ccxprocess (user)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("actions = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("actions = %q, want %q", got, want)
+		}
+	}
+	if !actions[0].login.Hidden {
+		t.Fatalf("expected Rectangle to be added hidden")
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected a warning for com.missing.agent, got %q", warnings)
+	}
+
+	m.Background.Enabled = append(m.Background.Enabled, "com.adobe.ccxprocess")
+	if _, _, err := planApply(m, login, items); err == nil {
+		t.Fatalf("expected a conflict error")
+	}
+}
+
+func TestApplyRemovesByPath(t *testing.T) {
+	m := Manifest{LoginItems: []ManifestLoginItem{{Path: "/Applications/Foo.app"}}}
+	m.Policy.UnlistedLoginItems = "remove"
+	login := []LoginItem{
+		{Name: "Foo", Path: "/Applications/Foo.app"},
+		{Name: "Foo", Path: "/Users/me/Downloads/Foo.app"},
+	}
+	actions, _, err := planApply(m, login, nil)
+	if err != nil || len(actions) != 1 || actions[0].String() != "remove login item /Users/me/Downloads/Foo.app" {
+		t.Fatalf("planApply = %v, %v", actions, err)
+	}
+
+	var buf bytes.Buffer
+	dryRun, dryRunOutput = true, &buf
+	defer func() { dryRun, dryRunOutput = false, os.Stdout }()
+	c := mlogin.Client{Runner: dryRunner{next: &cannedRunner{}}}
+	if err := executeApply(c, actions, true); err != nil {
+		t.Fatalf("executeApply: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "REMOVE_PATH='/Users/me/Downloads/Foo.app'") || strings.Contains(out, "REMOVE_NAME='Foo'") {
+		t.Fatalf("expected removal by path only, got %q", out)
+	}
+}
+
+func TestReadManifestResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "manifest.yaml")
+	if err := os.WriteFile(file, []byte("login_items:\n  - Apps/Foo.app\n  - /Applications/Bar.app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := readManifest(file)
+	if err != nil {
+		t.Fatalf("readManifest: %v", err)
+	}
+	if m.LoginItems[0].Path != filepath.Join(dir, "Apps/Foo.app") || m.LoginItems[1].Path != "/Applications/Bar.app" {
+		t.Fatalf("unexpected paths %+v", m.LoginItems)
+	}
+}
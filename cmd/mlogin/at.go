@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+type AtJob struct {
+	ID    string `json:"id"`
+	When  string `json:"when"`
+	Queue string `json:"queue,omitempty"`
+	User  string `json:"user,omitempty"`
+}
+
+// atrunLabel is the launchd job that runs queued at jobs. macOS ships it
+// disabled, so jobs queue up but never run until it is enabled.
+const atrunLabel = "com.apple.atrun"
+
+func runAt(args []string) error {
+	c := client()
+	if len(args) == 0 {
+		return usagef("missing at subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("at list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		jobs, err := listAtJobs()
+		if err != nil {
+			return err
+		}
+		warnIfAtrunDisabled(c)
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(jobs)
+		}
+		printAtJobs(jobs)
+		return nil
+	case "remove":
+		fs := flag.NewFlagSet("at remove", flag.ContinueOnError)
+		id := fs.String("id", "", "job id shown by at list")
+		yes := fs.Bool("yes", false, "remove without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *id == "" {
+			return usagef("--id is required")
+		}
+		if err := confirmAction(fmt.Sprintf("Remove at job %s?", *id), *yes); err != nil {
+			return err
+		}
+		if dryRunCommand("atrm", *id) {
+			return nil
+		}
+		cmd, done := toolCommand("atrm", *id)
+		out, err := cmd.CombinedOutput()
+		if err = done(err); err != nil {
+			err = fmt.Errorf("atrm %s: %w: %s", *id, err, strings.TrimSpace(string(out)))
+		}
+		recordAction("at remove", "at job "+*id, "", "", err)
+		if err != nil {
+			return err
+		}
+		successf("removed at job %s", *id)
+		return nil
+	default:
+		return usagef("unknown at subcommand %q", args[0])
+	}
+}
+
+func listAtJobs() ([]AtJob, error) {
+	cmd, done := toolCommand("atq")
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		return nil, fmt.Errorf("atq: %w", err)
+	}
+	return parseAtq(string(out)), nil
+}
+
+// parseAtq parses atq rows: "<id>\t<date>", optionally followed by the
+// queue letter and owner as printed when running as root.
+func parseAtq(out string) []AtJob {
+	var jobs []AtJob
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		job := AtJob{ID: fields[0]}
+		date := fields[1:]
+		if n := len(date); n >= 7 && len(date[n-2]) == 1 {
+			job.Queue, job.User = date[n-2], date[n-1]
+			date = date[:n-2]
+		}
+		job.When = strings.Join(date, " ")
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// warnIfAtrunDisabled notes on stderr that queued jobs will not run while
+// atrun is disabled. A label missing from print-disabled falls back to the
+// plist's Disabled key, which is set for atrun.
+func warnIfAtrunDisabled(c mlogin.Client) {
+	disabled, err := getDisabledLabels(c, "system")
+	if err != nil {
+		return
+	}
+	if isDisabled, ok := disabled[atrunLabel]; ok && !isDisabled {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "note: %s is disabled, so at jobs will not run; enable it with: sudo launchctl enable system/%s && sudo launchctl bootstrap system /System/Library/LaunchDaemons/%s.plist\n", atrunLabel, atrunLabel, atrunLabel)
+}
+
+func printAtJobs(jobs []AtJob) {
+	if len(jobs) == 0 {
+		fmt.Println("No at jobs queued")
+		return
+	}
+	fmt.Printf("%-6s %-26s %-5s %s\n", "ID", "WHEN", "QUEUE", "USER")
+	for _, j := range jobs {
+		fmt.Printf("%-6s %-26s %-5s %s\n", j.ID, j.When, orDash(j.Queue), orDash(j.User))
+	}
+}
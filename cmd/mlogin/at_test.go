@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestParseAtq(t *testing.T) {
+	out := "13\tFri Oct 17 09:00:00 2026\n14\tSat Oct 18 10:30:00 2026 a root\n"
+	jobs := parseAtq(out)
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	if jobs[0].ID != "13" || jobs[0].When != "Fri Oct 17 09:00:00 2026" || jobs[0].User != "" {
+		t.Fatalf("unexpected first job: %+v", jobs[0])
+	}
+	if jobs[1].Queue != "a" || jobs[1].User != "root" || jobs[1].When != "Sat Oct 18 10:30:00 2026" {
+		t.Fatalf("unexpected second job: %+v", jobs[1])
+	}
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// AuditEntry is one persistence item in the consolidated audit report,
+// normalized across surfaces.
+type AuditEntry struct {
+	Name string `json:"name"`
+	// Path is the file that installs the item (plist, bundle, crontab).
+	Path string `json:"path,omitempty"`
+	// Program is the executable or script the item runs.
+	Program string `json:"program,omitempty"`
+	// Command is the full command line, when the surface has one.
+	Command      string               `json:"command,omitempty"`
+	Detail       string               `json:"detail,omitempty"`
+	Risk         int                  `json:"risk,omitempty"`
+	RiskReasons  []string             `json:"risk_reasons,omitempty"`
+	Verification *ProgramVerification `json:"verification,omitempty"`
+	SHA256       string               `json:"sha256,omitempty"`
+}
+
+type AuditSurface struct {
+	Surface  string       `json:"surface"`
+	Entries  []AuditEntry `json:"entries"`
+	Warnings []string     `json:"warnings,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type auditProvider struct {
+	surface string
+	// collect reads the surface, running any tools through c.
+	collect func(c mlogin.Client) ([]AuditEntry, []string, error)
+}
+
+// auditProviders are run in order by `mlogin audit`. A failing provider is
+// reported in its surface and does not stop the others.
+var auditProviders = []auditProvider{
+	{"login items", auditLoginItems},
+	{"launchd", auditLaunchd},
+	{"background task management", auditBTM},
+	{"system extensions", auditSystemExtensions},
+	{"kernel extensions", auditKexts},
+	{"cron", auditCron},
+	{"at", auditAt},
+	{"login hooks", auditHooks},
+	{"emond", auditEmond},
+	{"folder actions", auditFolderActions},
+	{"authorization plugins", auditAuthPlugins},
+	{"privileged helpers", auditHelpers},
+	{"configuration profiles", auditProfiles},
+	{"environment", auditEnvInjection},
+}
+
+func runAudit(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	suspiciousOnly := fs.Bool("suspicious-only", false, "only report entries that score as suspicious")
+	verify := fs.Bool("verify", false, "assess each program with Gatekeeper and check its quarantine attribute")
+	hashes := fs.Bool("hashes", false, "compute the SHA-256 of each program")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	report := runAuditProviders(c, auditProviders)
+	for i := range report {
+		if *verify {
+			verifyAuditEntries(report[i].Entries)
+		}
+		if *hashes {
+			for j, e := range report[i].Entries {
+				if e.Program != "" {
+					report[i].Entries[j].SHA256, _ = programSHA256(e.Program)
+				}
+			}
+		}
+	}
+	if *suspiciousOnly {
+		for i := range report {
+			report[i].Entries = suspiciousEntries(report[i].Entries)
+		}
+	}
+	if outputFormat == outputJamfEA {
+		printJamfEA(jamfAuditReport(report))
+		return nil
+	}
+	if *jsonOut || outputFormat != "" {
+		return writeOutput(report)
+	}
+	printAuditReport(report)
+	return nil
+}
+
+func runAuditProviders(c mlogin.Client, providers []auditProvider) []AuditSurface {
+	report := make([]AuditSurface, 0, len(providers))
+	for _, p := range providers {
+		entries, warnings, err := p.collect(c)
+		s := AuditSurface{Surface: p.surface, Entries: entries, Warnings: warnings}
+		if s.Entries == nil {
+			s.Entries = []AuditEntry{}
+		}
+		for i, e := range s.Entries {
+			s.Entries[i].Risk, s.Entries[i].RiskReasons = assessRisk(e.Name, e.Program, e.Command, codesignRiskSignature)
+		}
+		if err != nil {
+			s.Error = err.Error()
+		}
+		report = append(report, s)
+	}
+	return report
+}
+
+// verifyAuditEntries assesses each entry's program with Gatekeeper. Flags
+// raise the entry's risk so --suspicious-only picks them up.
+func verifyAuditEntries(entries []AuditEntry) {
+	for i := range entries {
+		program := entries[i].Program
+		if program == "" {
+			continue
+		}
+		if _, err := os.Stat(program); err != nil {
+			continue
+		}
+		v := verifyProgram(program)
+		entries[i].Verification = &v
+		for _, f := range v.Flags {
+			entries[i].Risk += 2
+			entries[i].RiskReasons = append(entries[i].RiskReasons, f)
+		}
+	}
+}
+
+func suspiciousEntries(entries []AuditEntry) []AuditEntry {
+	out := make([]AuditEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.Risk >= suspiciousRisk {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func auditLoginItems(c mlogin.Client) ([]AuditEntry, []string, error) {
+	items, err := listLoginItems(c)
+	var entries []AuditEntry
+	for _, it := range items {
+		entries = append(entries, AuditEntry{Name: it.Name, Path: it.Path, Program: it.Path})
+	}
+	return entries, nil, err
+}
+
+func auditLaunchd(c mlogin.Client) ([]AuditEntry, []string, error) {
+	items, warnings, err := listBackgroundItems(c, "all", false)
+	var entries []AuditEntry
+	for _, it := range items {
+		e := AuditEntry{Name: it.Label, Path: it.Path, Detail: launchdAuditDetail(it)}
+		if it.Path != "" && it.Kind != "startup" {
+			if plist, err := readPlist(it.Path); err == nil {
+				e.Program = plistProgram(plist)
+				e.Command = strings.Join(plistStrings(plist, "ProgramArguments"), " ")
+				if e.Command == "" {
+					e.Command = e.Program
+				}
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, warnings, err
+}
+
+func launchdAuditDetail(it BackgroundItem) string {
+	detail := it.Scope + " " + it.Kind
+	if it.Loaded {
+		detail += ", loaded"
+	}
+	if it.Disabled != nil && *it.Disabled {
+		detail += ", disabled"
+	}
+	if it.Provenance != "" {
+		detail += ", " + it.Provenance
+	}
+	return detail
+}
+
+func auditBTM(mlogin.Client) ([]AuditEntry, []string, error) {
+	items, err := listBTMItems()
+	var entries []AuditEntry
+	for _, it := range items {
+		detail := it.Type
+		if len(it.Disposition) > 0 {
+			detail += " [" + strings.Join(it.Disposition, ", ") + "]"
+		}
+		if it.ParentApp != "" {
+			detail += ", from " + it.ParentApp
+		}
+		entries = append(entries, AuditEntry{Name: orDash(it.Name), Path: fileURLPath(it.URL), Program: it.ExecutablePath, Detail: detail})
+	}
+	return entries, nil, err
+}
+
+func auditSystemExtensions(c mlogin.Client) ([]AuditEntry, []string, error) {
+	items, err := listSystemExtensions(c)
+	var entries []AuditEntry
+	for _, it := range items {
+		entries = append(entries, AuditEntry{Name: it.BundleID, Path: it.HostApp, Detail: it.Category + ", " + it.State + ", team " + orDash(it.TeamID)})
+	}
+	return entries, nil, err
+}
+
+func auditKexts(mlogin.Client) ([]AuditEntry, []string, error) {
+	items, err := listKexts(false)
+	var entries []AuditEntry
+	for _, k := range items {
+		entries = append(entries, AuditEntry{Name: k.BundleID, Path: k.Path, Program: k.Path, Detail: "loaded, team " + orDash(k.TeamID)})
+	}
+	return entries, nil, err
+}
+
+func auditCron(mlogin.Client) ([]AuditEntry, []string, error) {
+	jobs, warnings, err := listCronEntries()
+	var entries []AuditEntry
+	for _, j := range jobs {
+		e := AuditEntry{Name: fmt.Sprintf("%s line %d", j.Source, j.Line), Command: j.Command, Detail: j.Schedule + " as " + orDash(j.User)}
+		if fields := strings.Fields(j.Command); len(fields) > 0 {
+			e.Program = fields[0]
+		}
+		if j.Source == systemCrontab {
+			e.Path = systemCrontab
+		}
+		entries = append(entries, e)
+	}
+	return entries, warnings, err
+}
+
+func auditAt(mlogin.Client) ([]AuditEntry, []string, error) {
+	jobs, err := listAtJobs()
+	var entries []AuditEntry
+	for _, j := range jobs {
+		entries = append(entries, AuditEntry{Name: "job " + j.ID, Detail: j.When + " as " + orDash(j.User)})
+	}
+	return entries, nil, err
+}
+
+func auditHooks(mlogin.Client) ([]AuditEntry, []string, error) {
+	var entries []AuditEntry
+	for _, h := range listLoginHooks() {
+		detail := h.Domain
+		if h.Missing {
+			detail += ", script missing"
+		}
+		entries = append(entries, AuditEntry{Name: h.Kind, Program: h.Script, Detail: detail})
+	}
+	return entries, nil, nil
+}
+
+func auditEmond(mlogin.Client) ([]AuditEntry, []string, error) {
+	report, warnings := scanEmond(false)
+	active := "inactive (no clients)"
+	if len(report.Clients) > 0 {
+		active = "active"
+	}
+	var entries []AuditEntry
+	for _, r := range report.Rules {
+		if !r.Enabled {
+			continue
+		}
+		entries = append(entries, AuditEntry{Name: r.Name, Path: r.File, Command: strings.Join(r.Actions, "; "), Detail: "emond " + active})
+	}
+	return entries, warnings, nil
+}
+
+func auditFolderActions(c mlogin.Client) ([]AuditEntry, []string, error) {
+	report, err := listFolderActions(c)
+	var entries []AuditEntry
+	for _, a := range report.Actions {
+		for _, s := range a.Scripts {
+			detail := "enabled"
+			if !report.Enabled || !a.Enabled || !s.Enabled {
+				detail = "disabled"
+			}
+			entries = append(entries, AuditEntry{Name: a.Folder, Program: s.Path, Detail: detail})
+		}
+	}
+	return entries, nil, err
+}
+
+func auditAuthPlugins(mlogin.Client) ([]AuditEntry, []string, error) {
+	plugins, warnings := listAuthPlugins(false)
+	var entries []AuditEntry
+	for _, p := range plugins {
+		entries = append(entries, AuditEntry{Name: p.Name, Path: p.Path, Program: p.Path, Detail: strings.Join(p.Mechanisms, ", ")})
+	}
+	return entries, warnings, nil
+}
+
+func auditHelpers(c mlogin.Client) ([]AuditEntry, []string, error) {
+	helpers, err := listPrivilegedHelpers(c)
+	var entries []AuditEntry
+	for _, h := range helpers {
+		detail := h.Label
+		if h.Orphaned {
+			detail = "orphaned"
+		}
+		entries = append(entries, AuditEntry{Name: filepath.Base(h.Path), Path: h.Plist, Program: h.Path, Detail: detail})
+	}
+	return entries, nil, err
+}
+
+func auditProfiles(mlogin.Client) ([]AuditEntry, []string, error) {
+	profiles, err := listConfigProfiles()
+	var entries []AuditEntry
+	for _, p := range profiles {
+		var controls []string
+		for _, pl := range p.Payloads {
+			if pl.Controls != "" {
+				controls = append(controls, pl.Controls)
+			}
+		}
+		detail := p.Level
+		if len(controls) > 0 {
+			detail += ", controls " + strings.Join(controls, ", ")
+		}
+		entries = append(entries, AuditEntry{Name: p.Identifier, Detail: detail})
+	}
+	return entries, nil, err
+}
+
+func auditEnvInjection(c mlogin.Client) ([]AuditEntry, []string, error) {
+	findings, err := auditEnvironment(c, "all")
+	var entries []AuditEntry
+	for _, f := range findings {
+		entries = append(entries, AuditEntry{Name: orDash(f.Label), Path: f.Source, Command: f.Detail, Detail: f.Kind})
+	}
+	return entries, nil, err
+}
+
+func printAuditReport(report []AuditSurface) {
+	for i, s := range report {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(headerStyle.Render(fmt.Sprintf("== %s (%d) ==", s.Surface, len(s.Entries))))
+		if s.Error != "" {
+			fmt.Printf("  %s %s\n", disabledStyle.Render("error:"), s.Error)
+		}
+		for _, w := range s.Warnings {
+			fmt.Printf("  %s %s\n", cautionStyle.Render("warning:"), w)
+		}
+		for _, e := range s.Entries {
+			line := e.Name
+			if e.Detail != "" {
+				line += "  (" + e.Detail + ")"
+			}
+			if e.Risk >= suspiciousRisk {
+				line = suspiciousStyle.Render(line)
+			}
+			fmt.Printf("  %s\n", line)
+			if e.Path != "" {
+				fmt.Printf("    path:    %s\n", e.Path)
+			}
+			if e.Command != "" && e.Command != e.Program {
+				fmt.Printf("    command: %s\n", e.Command)
+			} else if e.Program != "" && e.Program != e.Path {
+				fmt.Printf("    program: %s\n", e.Program)
+			}
+			if e.Verification != nil {
+				fmt.Printf("    %s\n", describeVerification(*e.Verification))
+			}
+			if e.SHA256 != "" {
+				fmt.Printf("    sha256:  %s\n", e.SHA256)
+			}
+			if e.Risk > 0 {
+				fmt.Printf("    %s\n", riskStyle(e.Risk).Render(fmt.Sprintf("risk:    %d (%s)", e.Risk, strings.Join(e.RiskReasons, ", "))))
+			}
+		}
+	}
+}
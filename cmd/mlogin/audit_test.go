@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func TestRunAuditProvidersKeepsGoingAfterError(t *testing.T) {
+	providers := []auditProvider{
+		{"failing", func(mlogin.Client) ([]AuditEntry, []string, error) { return nil, nil, errors.New("needs root") }},
+		{"working", func(mlogin.Client) ([]AuditEntry, []string, error) {
+			return []AuditEntry{{Name: "com.example.agent"}}, []string{"skipped one"}, nil
+		}},
+	}
+	report := runAuditProviders(mlogin.Client{}, providers)
+	if len(report) != 2 {
+		t.Fatalf("expected 2 surfaces, got %d", len(report))
+	}
+	if report[0].Error != "needs root" || report[0].Entries == nil {
+		t.Fatalf("unexpected failing surface: %+v", report[0])
+	}
+	if len(report[1].Entries) != 1 || len(report[1].Warnings) != 1 {
+		t.Fatalf("unexpected working surface: %+v", report[1])
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	authPluginDir      = "/Library/Security/SecurityAgentPlugins"
+	appleAuthPluginDir = "/System/Library/CoreServices/SecurityAgentPlugins"
+)
+
+// authRights are the authorization rights whose mechanisms run at the
+// login window, on unlock, and for admin authentication.
+var authRights = []string{"system.login.console", "system.login.screensaver", "authenticate"}
+
+type AuthPlugin struct {
+	Name       string   `json:"name"`
+	Path       string   `json:"path,omitempty"`
+	Apple      bool     `json:"apple"`
+	TeamID     string   `json:"team_id,omitempty"`
+	Mechanisms []string `json:"mechanisms,omitempty"`
+}
+
+func runAuthPlugins(args []string) error {
+	if len(args) == 0 {
+		return usagef("missing authplugins subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("authplugins list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		includeApple := fs.Bool("include-apple", false, "also list Apple plugins")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		plugins, warnings := listAuthPlugins(*includeApple)
+		for _, w := range warnings {
+			warn(w)
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(plugins)
+		}
+		printAuthPlugins(plugins)
+		return nil
+	default:
+		return usagef("unknown authplugins subcommand %q", args[0])
+	}
+}
+
+// parseMechanism splits an authorizationdb mechanism such as
+// "MyPlugin:invoke,privileged" into its plugin and mechanism names.
+func parseMechanism(s string) (plugin, mechanism string) {
+	plugin, mechanism, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", s
+	}
+	return plugin, mechanism
+}
+
+// isAppleAuthPlugin reports whether plugin is built into SecurityAgent or
+// ships in the read-only system plugin directory.
+func isAppleAuthPlugin(plugin string) bool {
+	switch plugin {
+	case "builtin", "loginwindow":
+		return true
+	}
+	_, err := os.Stat(filepath.Join(appleAuthPluginDir, plugin+".bundle"))
+	return err == nil
+}
+
+// listAuthPlugins returns the installed third-party plugin bundles plus every
+// plugin the login-related authorization rights reference, with the
+// mechanisms that invoke them.
+func listAuthPlugins(includeApple bool) ([]AuthPlugin, []string) {
+	var warnings []string
+	plugins := map[string]*AuthPlugin{}
+	bundles, _ := filepath.Glob(filepath.Join(authPluginDir, "*.bundle"))
+	for _, b := range bundles {
+		name := strings.TrimSuffix(filepath.Base(b), ".bundle")
+		plugins[name] = &AuthPlugin{Name: name, Path: b, TeamID: programTeamID(b)}
+	}
+	for _, right := range authRights {
+		mechanisms, err := readAuthRightMechanisms(right)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		for _, mech := range mechanisms {
+			name, _ := parseMechanism(mech)
+			if name == "" {
+				continue
+			}
+			p, ok := plugins[name]
+			if !ok {
+				p = &AuthPlugin{Name: name, Apple: isAppleAuthPlugin(name)}
+				plugins[name] = p
+			}
+			p.Mechanisms = append(p.Mechanisms, right+": "+mech)
+		}
+	}
+
+	out := make([]AuthPlugin, 0, len(plugins))
+	for _, p := range plugins {
+		if p.Apple && !includeApple {
+			continue
+		}
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, warnings
+}
+
+func readAuthRightMechanisms(right string) ([]string, error) {
+	cmd, done := toolCommand("security", "authorizationdb", "read", right)
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		return nil, fmt.Errorf("security authorizationdb read %s: %w", right, err)
+	}
+	m, err := decodePlistXML(out)
+	if err != nil {
+		return nil, fmt.Errorf("parse authorization right %s: %w", right, err)
+	}
+	return plistStrings(m, "mechanisms"), nil
+}
+
+func printAuthPlugins(plugins []AuthPlugin) {
+	if len(plugins) == 0 {
+		fmt.Println("No third-party authorization plugins found")
+		return
+	}
+	for _, p := range plugins {
+		origin := "third-party"
+		if p.Apple {
+			origin = "apple"
+		}
+		path := p.Path
+		if path == "" && !p.Apple {
+			path = "(bundle missing)"
+		}
+		fmt.Printf("%s [%s] team %s\n", p.Name, origin, orDash(p.TeamID))
+		if path != "" {
+			fmt.Printf("  %s\n", path)
+		}
+		for _, m := range p.Mechanisms {
+			fmt.Printf("  %s\n", m)
+		}
+		if !p.Apple && len(p.Mechanisms) == 0 {
+			fmt.Println("  (installed but not referenced by any login right)")
+		}
+	}
+}
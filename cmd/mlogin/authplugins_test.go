@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestParseMechanism(t *testing.T) {
+	tests := []struct {
+		in, plugin, mechanism string
+	}{
+		{"builtin:policy-banner", "builtin", "policy-banner"},
+		{"JamfConnectLogin:Invoke,privileged", "JamfConnectLogin", "Invoke,privileged"},
+		{"odd", "", "odd"},
+	}
+	for _, tt := range tests {
+		plugin, mechanism := parseMechanism(tt.in)
+		if plugin != tt.plugin || mechanism != tt.mechanism {
+			t.Fatalf("parseMechanism(%q) = %q, %q; want %q, %q", tt.in, plugin, mechanism, tt.plugin, tt.mechanism)
+		}
+	}
+	if !isAppleAuthPlugin("builtin") {
+		t.Fatalf("expected builtin to be an Apple plugin")
+	}
+}
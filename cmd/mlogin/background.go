@@ -0,0 +1,601 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func showBackgroundLogs(label, scope string, lines int, follow bool) error {
+	item, err := resolveBackgroundPlist(label, scope)
+	if err != nil {
+		return err
+	}
+	plist, err := readPlist(item.Path)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, key := range []string{"StandardOutPath", "StandardErrorPath"} {
+		p := plistString(plist, key)
+		if p == "" || slices.Contains(paths, p) {
+			continue
+		}
+		paths = append(paths, p)
+	}
+
+	var cmd *exec.Cmd
+	if len(paths) > 0 {
+		args := []string{"-n", strconv.Itoa(lines)}
+		if follow {
+			args = append(args, "-F")
+		}
+		cmd = exec.Command("tail", append(args, paths...)...)
+	} else {
+		predicate := logPredicate(label, plistProgram(plist))
+		fmt.Fprintf(os.Stderr, "no StandardOutPath/StandardErrorPath in %s; using unified log\n", item.Path)
+		if follow {
+			cmd = exec.Command("log", "stream", "--style", "compact", "--predicate", predicate)
+		} else {
+			// log show has no line limit of its own, so --lines is applied
+			// to its output.
+			cmd = exec.Command("log", "show", "--last", "1h", "--style", "compact", "--predicate", predicate)
+			cmd.Stderr = os.Stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(os.Stdout, lastLines(string(out), lines))
+			return err
+		}
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if follow && errors.As(err, &exitErr) && !exitErr.Exited() {
+			// Interrupted with ctrl+c while following.
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+	if n >= 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	out := strings.Join(lines, "")
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out
+}
+
+// logPredicate builds a unified log predicate matching the label's subsystem
+// and, when known, the process name of its program.
+func logPredicate(label, program string) string {
+	predicate := fmt.Sprintf("subsystem == %q", label)
+	if program != "" {
+		predicate += fmt.Sprintf(" OR process == %q", filepath.Base(program))
+	}
+	return predicate
+}
+
+type backgroundSpec struct {
+	label     string
+	program   string
+	args      []string
+	interval  int
+	runAtLoad bool
+	logPath   string
+}
+
+// launchdPlist builds the plist contents for a simple periodic or
+// run-at-load job.
+func (s backgroundSpec) launchdPlist() map[string]any {
+	m := map[string]any{
+		"Label":            s.label,
+		"ProgramArguments": append([]string{s.program}, s.args...),
+	}
+	if s.interval > 0 {
+		m["StartInterval"] = s.interval
+	}
+	if s.runAtLoad {
+		m["RunAtLoad"] = true
+	}
+	if s.logPath != "" {
+		m["StandardOutPath"] = s.logPath
+		m["StandardErrorPath"] = s.logPath
+	}
+	return m
+}
+
+// plistPathFor returns where a new plist for label belongs: user agents in
+// ~/Library/LaunchAgents, system jobs in /Library/LaunchDaemons.
+func plistPathFor(label, scope string) (string, error) {
+	// The label becomes the file name; it must not lead out of the
+	// launchd directory, which for system scope is written as root.
+	if label == "" || strings.Contains(label, "/") || strings.HasPrefix(label, ".") {
+		return "", fmt.Errorf("invalid label %q: must be non-empty, contain no /, and not start with .", label)
+	}
+	switch scope {
+	case "user", "user-domain":
+		home, err := launchUserHome()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library/LaunchAgents", label+".plist"), nil
+	case "loginwindow":
+		return filepath.Join("/Library/LaunchAgents", label+".plist"), nil
+	case "system":
+		return filepath.Join("/Library/LaunchDaemons", label+".plist"), nil
+	default:
+		return "", usagef("--scope must be user, user-domain, loginwindow, or system")
+	}
+}
+
+func createBackgroundItem(c mlogin.Client, spec backgroundSpec, scope string, load bool) error {
+	scope = strings.ToLower(scope)
+	program, err := filepath.Abs(spec.program)
+	if err != nil {
+		return err
+	}
+	spec.program = program
+	if spec.logPath != "" {
+		if spec.logPath, err = filepath.Abs(spec.logPath); err != nil {
+			return err
+		}
+	}
+
+	path, err := plistPathFor(spec.label, scope)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("plist %s already exists", path)
+	}
+	data, err := encodePlistXML(spec.launchdPlist())
+	if err != nil {
+		return err
+	}
+	if dryRunf("write %s", path) {
+		fmt.Fprintf(dryRunOutput, "%s", data)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("write plist %s: %w", path, err)
+		}
+		if scope == "system" || scope == "loginwindow" {
+			// launchd refuses system-wide jobs that are not owned by root:wheel.
+			if err := os.Chown(path, 0, 0); err != nil {
+				return fmt.Errorf("chown %s to root:wheel (try sudo): %w", path, err)
+			}
+		}
+	}
+	recordAction("background create", path, scope, "", nil)
+	successf("created %s", path)
+
+	if !load {
+		return nil
+	}
+	domain, err := launchDomain(c, scope)
+	if err != nil {
+		return err
+	}
+	err = c.LoadService(context.Background(), domain, path)
+	recordAction("background load", path, scope, "", err)
+	if err != nil {
+		return err
+	}
+	successf("loaded %s into %s", path, domain)
+	return nil
+}
+
+// reloadBackgroundPlist boots the service out of its domain (ignoring "not
+// loaded" errors) and bootstraps it again from its plist.
+func reloadBackgroundPlist(c mlogin.Client, item BackgroundItem) error {
+	if err := checkNotSIPProtected(item.Label, item.Path); err != nil {
+		return err
+	}
+	domain, err := launchDomain(c, item.Scope)
+	if err != nil {
+		return err
+	}
+	target := domain + "/" + item.Label
+	if err := c.UnloadService(context.Background(), target); err != nil {
+		if !isIgnorableBootoutError(err) {
+			err = fmt.Errorf("bootout failed for %s: %w", item.Label, err)
+			recordAction("background reload", target, item.Scope, "", err)
+			return err
+		}
+	}
+	if err := c.LoadService(context.Background(), domain, item.Path); err != nil {
+		err = fmt.Errorf("bootstrap failed for %s: %w", item.Path, err)
+		recordAction("background reload", target, item.Scope, "", err)
+		return err
+	}
+	recordAction("background reload", target, item.Scope, "", nil)
+	successf("reloaded %s in %s", item.Label, domain)
+	return nil
+}
+
+func editBackgroundItem(c mlogin.Client, label, scope string, reload bool) error {
+	item, err := resolveBackgroundPlist(label, scope)
+	if err != nil {
+		return err
+	}
+	before, err := os.ReadFile(item.Path)
+	if err != nil {
+		return err
+	}
+
+	// Under --dry-run the edits go to a scratch copy, so the real plist is
+	// never touched.
+	path := item.Path
+	if dryRun {
+		f, err := os.CreateTemp("", "mlogin-*.plist")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(before)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		path = f.Name()
+	}
+
+	for {
+		if err := openInEditor(path); err != nil {
+			return err
+		}
+		lintErr := lintPlist(path)
+		if lintErr == nil {
+			break
+		}
+		fmt.Fprintln(os.Stderr, "plist is invalid:", lintErr)
+		// confirm says yes by itself under --dry-run, which would reopen
+		// the editor forever.
+		if dryRun || !confirm("Re-open the editor?") {
+			return fmt.Errorf("%s left invalid: %w", item.Path, lintErr)
+		}
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		successf("no changes")
+		return nil
+	}
+	dryRunf("save the edited plist to %s", item.Path)
+	if !reload && !confirm(fmt.Sprintf("Reload %s so the change takes effect?", item.Label)) {
+		successf("saved %s (not reloaded)", item.Path)
+		return nil
+	}
+	return reloadBackgroundPlist(c, item)
+}
+
+// openInEditor runs $VISUAL or $EDITOR (falling back to vi) on path.
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", parts[0], err)
+	}
+	return nil
+}
+
+// isLabelPattern reports whether label contains glob metacharacters.
+func isLabelPattern(label string) bool {
+	return strings.ContainsAny(label, "*?[")
+}
+
+// matchBackgroundItems returns the on-disk background items whose label
+// matches the glob pattern.
+func matchBackgroundItems(c mlogin.Client, pattern, scope string) ([]BackgroundItem, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+	}
+	if scope == "" {
+		scope = "all"
+	}
+	items, _, err := listBackgroundItems(c, scope, false)
+	if err != nil {
+		return nil, err
+	}
+	var matched []BackgroundItem
+	for _, it := range items {
+		if ok, _ := path.Match(pattern, it.Label); ok {
+			matched = append(matched, it)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, notFoundf("no labels match %q", pattern)
+	}
+	return matched, nil
+}
+
+// runBackgroundVerb applies enable, disable, or unload to a single item.
+// With viaBrew, enable/disable of Homebrew-managed items is delegated to
+// `brew services`.
+func runBackgroundVerb(c mlogin.Client, verb string, item BackgroundItem, viaBrew bool) error {
+	if err := checkNotSIPProtected(item.Label, item.Path); err != nil {
+		return err
+	}
+	if item.Provenance == "legacy" {
+		return fmt.Errorf("%s is a legacy StartupItem, not a launchd job; remove %s to get rid of it", item.Label, item.Path)
+	}
+	if verb == "enable" || verb == "disable" {
+		if mlogin.IsHomebrewItem(item.Label, item.Path) {
+			formula := mlogin.HomebrewFormula(item.Label, item.Path)
+			switch {
+			case viaBrew && formula != "":
+				return runBrewServices(c, verb, formula)
+			case viaBrew:
+				warnf("cannot tell which formula installed %s; using launchctl", item.Label)
+			default:
+				warnf("%s is managed by brew services; use --brew to keep Homebrew in sync", item.Label)
+			}
+		}
+	}
+	if verb == "disable" {
+		if w := managedWarning(item); w != "" {
+			warn(w)
+		}
+	}
+	domain, err := launchDomain(c, item.Scope)
+	if err != nil {
+		return err
+	}
+	target := domain + "/" + item.Label
+	switch verb {
+	case "enable", "disable":
+		previous := launchdPreviousState(c, domain, item.Label)
+		err := toggleService(c, verb, target)
+		recordAction("background "+verb, target, item.Scope, previous, err)
+		if err != nil {
+			return err
+		}
+		successf("%sd %s in %s", verb, item.Label, domain)
+	case "unload":
+		err := c.UnloadService(context.Background(), target)
+		recordAction("background unload", target, item.Scope, "loaded", err)
+		if err != nil {
+			return err
+		}
+		successf("unloaded %s from %s", item.Label, domain)
+	default:
+		return fmt.Errorf("unsupported action %q", verb)
+	}
+	return nil
+}
+
+// runBackgroundVerbBulk lists the matched items, asks for confirmation unless
+// yes is set, and applies verb to each, continuing past individual failures.
+func runBackgroundVerbBulk(c mlogin.Client, verb string, items []BackgroundItem, yes, viaBrew bool) error {
+	fmt.Printf("%d matching label(s):\n", len(items))
+	for _, it := range items {
+		fmt.Printf("  %-8s %s\n", it.Scope, it.Label)
+	}
+	if err := confirmAction(fmt.Sprintf("%s all %d?", upperFirst(verb), len(items)), yes); err != nil {
+		return err
+	}
+	failed := 0
+	for _, it := range items {
+		if err := runBackgroundVerb(c, verb, it, viaBrew); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s %s: %v\n", verb, it.Label, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s failed for %d of %d label(s)", verb, failed, len(items))
+	}
+	return nil
+}
+
+type orphanedItem struct {
+	item    BackgroundItem
+	program string
+}
+
+// findOrphanedItems returns items whose Program/ProgramArguments binary no
+// longer exists, typically leftovers from uninstalled apps.
+func findOrphanedItems(items []BackgroundItem) []orphanedItem {
+	var orphans []orphanedItem
+	for _, it := range items {
+		plist, err := readPlist(it.Path)
+		if err != nil {
+			continue
+		}
+		program := plistProgram(plist)
+		if !filepath.IsAbs(program) {
+			continue
+		}
+		if _, err := os.Stat(program); os.IsNotExist(err) {
+			orphans = append(orphans, orphanedItem{item: it, program: program})
+		}
+	}
+	return orphans
+}
+
+func pruneBackgroundItems(c mlogin.Client, scope string, yes bool) error {
+	items, warnings, err := listBackgroundItems(c, scope, false)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		warn(w)
+	}
+	orphans := findOrphanedItems(items)
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned background items found")
+		return nil
+	}
+	fmt.Printf("%d orphaned background item(s):\n", len(orphans))
+	for _, o := range orphans {
+		fmt.Printf("  %-8s %s\n", o.item.Scope, o.item.Label)
+		fmt.Printf("    missing program: %s\n", o.program)
+		fmt.Printf("    plist: %s\n", o.item.Path)
+	}
+	if err := confirmAction(fmt.Sprintf("Unload and delete all %d?", len(orphans)), yes); err != nil {
+		return err
+	}
+	failed := 0
+	for _, o := range orphans {
+		if err := deleteBackgroundItem(c, o.item.Label, o.item.Path, o.item.Scope, false); err != nil {
+			fmt.Fprintf(os.Stderr, "error: delete %s: %v\n", o.item.Label, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("prune failed for %d of %d item(s)", failed, len(orphans))
+	}
+	return nil
+}
+
+func showBackgroundPlist(label, plistPath, scope, format string) error {
+	if plistPath == "" {
+		if scope == "" {
+			scope = "all"
+		}
+		item, err := resolveBackgroundPlist(label, scope)
+		if err != nil {
+			return err
+		}
+		plistPath = item.Path
+	}
+	plist, err := readPlist(plistPath)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plist)
+	case "yaml", "yml":
+		return writeYAML(os.Stdout, plist)
+	default:
+		return usagef("--format must be json or yaml")
+	}
+}
+
+// labelHasVendor reports whether one of the dot-separated label components
+// equals vendor, so "adobe" matches com.adobe.acc.installer.
+func labelHasVendor(label, vendor string) bool {
+	for _, part := range strings.Split(strings.ToLower(label), ".") {
+		if part == strings.ToLower(vendor) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchVendorItems returns on-disk items whose label names vendor or whose
+// program is signed with vendor as its Team ID.
+func matchVendorItems(c mlogin.Client, vendor, scope string) ([]BackgroundItem, error) {
+	if scope == "" {
+		scope = "all"
+	}
+	items, _, err := listBackgroundItems(c, scope, false)
+	if err != nil {
+		return nil, err
+	}
+	var matched []BackgroundItem
+	for _, it := range items {
+		if labelHasVendor(it.Label, vendor) {
+			matched = append(matched, it)
+			continue
+		}
+		if it.Path == "" {
+			continue
+		}
+		plist, err := readPlist(it.Path)
+		if err != nil {
+			continue
+		}
+		if program := plistProgram(plist); program != "" && strings.EqualFold(programTeamID(program), vendor) {
+			matched = append(matched, it)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, notFoundf("no background items match vendor %q", vendor)
+	}
+	return matched, nil
+}
+
+// readLabelsFrom reads newline-separated labels from a file, or from stdin
+// when src is "-".
+func readLabelsFrom(src string) ([]string, error) {
+	var data []byte
+	var err error
+	if src == "-" {
+		data, err = io.ReadAll(stdinReader)
+	} else {
+		data, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+	labels := parseLabelLines(string(data))
+	for i, label := range labels {
+		labels[i] = resolveAlias(label)
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("no labels read from %s", src)
+	}
+	return labels, nil
+}
+
+// itemsForLabels resolves each label's scope and plist the same way a
+// single --label invocation would.
+func itemsForLabels(labels []string, scope string) ([]BackgroundItem, error) {
+	items := make([]BackgroundItem, 0, len(labels))
+	for _, label := range labels {
+		resolved, err := inferScope(label, scope)
+		if err != nil {
+			return nil, err
+		}
+		item := BackgroundItem{Label: label, Scope: resolved}
+		if matches, err := findBackgroundPlists(label, resolved); err == nil && len(matches) > 0 {
+			item.Path = matches[0].Path
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
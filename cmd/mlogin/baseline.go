@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// The baseline is signed with an HMAC whose key lives in the login
+// keychain, so editing baseline.json to hide a new item is detected. The
+// key is created through /usr/bin/security, which the item then trusts:
+// any process running as the same user can read it back without a prompt
+// and re-sign a tampered baseline. The signature guards against other
+// users and offline edits, not against code already running as this user.
+const (
+	baselineKeychainService = "mlogin-baseline"
+	baselineKeychainAccount = "mlogin"
+)
+
+// baselinePath is the known-good snapshot; its signature sits next to it
+// with a .sig suffix.
+func baselinePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Application Support", "mlogin", "baseline.json"), nil
+}
+
+func runBaseline(args []string) error {
+	c := client()
+	if len(args) == 0 {
+		return usagef("missing baseline subcommand")
+	}
+	switch args[0] {
+	case "set":
+		fs := flag.NewFlagSet("baseline set", flag.ContinueOnError)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return setBaseline(c)
+	case "check":
+		fs := flag.NewFlagSet("baseline check", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return checkBaseline(c, *jsonOut)
+	default:
+		return usagef("unknown baseline subcommand %q", args[0])
+	}
+}
+
+// baselineKey returns the HMAC key from the keychain, creating it on first
+// use when create is set.
+func baselineKey(create bool) ([]byte, error) {
+	cmd, done := toolCommand("security", "find-generic-password", "-a", baselineKeychainAccount, "-s", baselineKeychainService, "-w")
+	out, err := cmd.Output()
+	err = done(err)
+	if err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(out)))
+	}
+	if !create {
+		return nil, errors.New("baseline key not found in the keychain; run mlogin baseline set")
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	// security -i reads the command from stdin, keeping the key out of
+	// argv where ps would show it. It exits 0 even when the command fails,
+	// so the key is read back to confirm it was stored.
+	cmd, done = toolCommand("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -a %s -s %s -w %s\n", baselineKeychainAccount, baselineKeychainService, hex.EncodeToString(key)))
+	out, err = cmd.CombinedOutput()
+	if err = done(err); err != nil {
+		return nil, fmt.Errorf("store baseline key: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if stored, err := baselineKey(false); err != nil || !hmac.Equal(stored, key) {
+		return nil, fmt.Errorf("store baseline key: security did not save it: %s", strings.TrimSpace(string(out)))
+	}
+	return key, nil
+}
+
+func signBaseline(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyBaseline(key, data []byte, sig string) bool {
+	want, err := hex.DecodeString(strings.TrimSpace(sig))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+func setBaseline(c mlogin.Client) error {
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	key, err := baselineKey(true)
+	if err != nil {
+		return err
+	}
+	snap := takeSnapshot(c)
+	for _, w := range snap.Warnings {
+		warn(w)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := writeSnapshot(path, snap); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".sig", []byte(signBaseline(key, data)+"\n"), 0o600); err != nil {
+		return err
+	}
+	successf("baseline set (%d login item(s), %d background item(s)) at %s", len(snap.LoginItems), len(snap.Background), path)
+	return nil
+}
+
+// checkBaseline verifies the baseline's signature, compares it with the
+// live state, and fails when anything deviates so schedulers can alert on
+// the exit status.
+func checkBaseline(c mlogin.Client, jsonOut bool) error {
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return errors.New("no baseline set; run mlogin baseline set")
+	} else if err != nil {
+		return err
+	}
+	sig, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return fmt.Errorf("baseline signature missing: %w", err)
+	}
+	key, err := baselineKey(false)
+	if err != nil {
+		return err
+	}
+	if !verifyBaseline(key, data, string(sig)) {
+		return fmt.Errorf("baseline %s was modified after it was set (signature mismatch)", path)
+	}
+	base, err := readSnapshot(path)
+	if err != nil {
+		return err
+	}
+	cur := takeSnapshot(c)
+	for _, w := range cur.Warnings {
+		warn(w)
+	}
+	changes := diffSnapshots(base, cur)
+	if jsonOut || outputFormat != "" {
+		if changes == nil {
+			changes = []SnapshotChange{}
+		}
+		if err := writeOutput(changes); err != nil {
+			return err
+		}
+	} else {
+		printSnapshotChanges(changes)
+	}
+	if len(changes) > 0 {
+		return fmt.Errorf("%d deviation(s) from the baseline set %s", len(changes), base.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestBaselineSignature(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	data := []byte(`{"schema_version": 1}`)
+	sig := signBaseline(key, data)
+	if !verifyBaseline(key, data, sig+"\n") {
+		t.Fatalf("expected signature to verify")
+	}
+	if verifyBaseline(key, []byte(`{"schema_version": 2}`), sig) {
+		t.Fatalf("expected modified data to fail verification")
+	}
+	if verifyBaseline([]byte("another key"), data, sig) {
+		t.Fatalf("expected a different key to fail verification")
+	}
+}
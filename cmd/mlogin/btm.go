@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BTMItem is one record of the Background Task Management database, the
+// store behind System Settings > General > Login Items.
+type BTMItem struct {
+	UID            int      `json:"uid"`
+	Name           string   `json:"name,omitempty"`
+	Type           string   `json:"type"`
+	Disposition    []string `json:"disposition,omitempty"`
+	Identifier     string   `json:"identifier"`
+	URL            string   `json:"url,omitempty"`
+	ExecutablePath string   `json:"executable_path,omitempty"`
+	BundleID       string   `json:"bundle_id,omitempty"`
+	TeamID         string   `json:"team_id,omitempty"`
+	Developer      string   `json:"developer,omitempty"`
+	Parent         string   `json:"parent,omitempty"`
+	ParentApp      string   `json:"parent_app,omitempty"`
+}
+
+func runBTM(args []string) error {
+	if len(args) == 0 {
+		return usagef("missing btm subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("btm list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		items, err := listBTMItems()
+		if err != nil {
+			return err
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(items)
+		}
+		printBTMItems(items)
+		return nil
+	case "reset":
+		fs := flag.NewFlagSet("btm reset", flag.ContinueOnError)
+		userOnly := fs.Bool("user", false, "reset only the current user's records (not supported by sfltool)")
+		all := fs.Bool("all", false, "reset the records of every user and the system")
+		yes := fs.Bool("yes", false, "reset without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *userOnly {
+			return errors.New("sfltool can only reset the whole database; pass --all")
+		}
+		if !*all {
+			return errors.New("btm reset clears every user's records; pass --all to confirm the scope")
+		}
+		return resetBTM(*yes)
+	default:
+		return usagef("unknown btm subcommand %q", args[0])
+	}
+}
+
+// listBTMItems dumps the database with sfltool.
+func listBTMItems() ([]BTMItem, error) {
+	out, err := runSfltool("dumpbtm")
+	if err != nil {
+		return nil, err
+	}
+	return parseDumpBTM(out), nil
+}
+
+// resetBTM wipes the database so macOS rebuilds it from the installed apps
+// and plists, which clears stale ("ghost") entries from the Login Items
+// pane. Users must re-approve items afterwards.
+func resetBTM(yes bool) error {
+	if err := confirmReset("Reset the Background Task Management database for all users?", yes); err != nil {
+		return err
+	}
+	if dryRunCommand("sfltool", "resetbtm") {
+		return nil
+	}
+	_, err := runSfltool("resetbtm")
+	recordAction("btm reset", "background task management database", "all", "", err)
+	if err != nil {
+		return err
+	}
+	successf("reset the Background Task Management database; restart to rebuild it")
+	return nil
+}
+
+// runSfltool runs sfltool, which needs administrator rights for the BTM
+// verbs on recent macOS versions.
+func runSfltool(args ...string) (string, error) {
+	cmd, done := toolCommand("sfltool", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if os.Geteuid() != 0 {
+			msg = strings.TrimSpace(msg + " (try again with sudo)")
+		}
+		return "", fmt.Errorf("sfltool %s: %w: %s", args[0], err, msg)
+	}
+	return string(out), nil
+}
+
+var (
+	btmRecordsRe = regexp.MustCompile(`^\s*Records for UID (-?\d+)`)
+	btmItemRe    = regexp.MustCompile(`^\s*#\d+:\s*$`)
+	// btmHexSuffixRe matches the raw value sfltool appends, as in
+	// "app (0x2)" or "[enabled, allowed] (0x3)".
+	btmHexSuffixRe = regexp.MustCompile(`\s*\(0x[0-9a-fA-F]+\)$`)
+)
+
+// parseDumpBTM parses `sfltool dumpbtm` output. Records are grouped under
+// "Records for UID <n>" headers (-2 holds system-wide items), each record
+// starts with a "#<n>:" line, and fields are "Key: value" lines. Nested
+// lists such as embedded item identifiers are skipped. Parent identifiers
+// are resolved to the parent record's name.
+func parseDumpBTM(out string) []BTMItem {
+	var items []BTMItem
+	uid := 0
+	var cur *BTMItem
+	flush := func() {
+		if cur != nil && cur.Identifier != "" {
+			items = append(items, *cur)
+		}
+		cur = nil
+	}
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := s.Text()
+		if m := btmRecordsRe.FindStringSubmatch(line); m != nil {
+			flush()
+			uid, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if btmItemRe.MatchString(line) {
+			flush()
+			cur = &BTMItem{UID: uid}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		// List headers have no value and their entries are "#<n>: value"
+		// lines; neither is a field of the record.
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		switch key {
+		case "Name":
+			cur.Name = value
+		case "Type":
+			cur.Type = btmHexSuffixRe.ReplaceAllString(value, "")
+		case "Disposition":
+			cur.Disposition = parseBTMFlags(value)
+		case "Identifier":
+			cur.Identifier = value
+		case "URL":
+			cur.URL = value
+		case "Executable Path":
+			cur.ExecutablePath = value
+		case "Bundle Identifier":
+			cur.BundleID = value
+		case "Team Identifier":
+			cur.TeamID = value
+		case "Developer Name":
+			cur.Developer = value
+		case "Parent Identifier":
+			cur.Parent = value
+		}
+	}
+	flush()
+
+	names := map[string]string{}
+	for _, it := range items {
+		names[it.Identifier] = it.Name
+	}
+	for i := range items {
+		if items[i].Parent != "" {
+			items[i].ParentApp = names[items[i].Parent]
+		}
+	}
+	return items
+}
+
+// parseBTMFlags splits a "[enabled, allowed, visible] (0x7)" field.
+func parseBTMFlags(value string) []string {
+	value = btmHexSuffixRe.ReplaceAllString(value, "")
+	value = strings.Trim(value, "[] ")
+	var flags []string
+	for _, f := range strings.Split(value, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+func printBTMItems(items []BTMItem) {
+	if len(items) == 0 {
+		fmt.Println("No background task records found")
+		return
+	}
+	fmt.Printf("%-5s %-16s %-32s %-24s %s\n", "UID", "TYPE", "NAME", "PARENT", "DISPOSITION")
+	for _, it := range items {
+		fmt.Printf("%-5d %-16s %-32s %-24s %s\n", it.UID, it.Type, orDash(it.Name), orDash(it.ParentApp), strings.Join(it.Disposition, ","))
+		target := it.URL
+		if it.ExecutablePath != "" {
+			target = it.ExecutablePath
+		}
+		if target != "" {
+			fmt.Printf("  %s\n", target)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestParseDumpBTM(t *testing.T) {
+	out := `========================
+ Records for UID 501 : 6F5A2C1E-0000-0000-0000-000000000000
+========================
+
+ ServiceManagement migrated: true
+ SharedFileList migrated: true
+
+ Items:
+
+ #1:
+                 UUID: 1D1B9F6A-0000-0000-0000-000000000000
+                 Name: Docker
+       Developer Name: Docker Inc
+      Team Identifier: 9BNSXJN65R
+                 Type: app (0x2)
+                Flags: [  ] (0)
+          Disposition: [enabled, allowed, visible, notified] (0xb)
+           Identifier: 2.com.docker.docker
+                  URL: file:///Applications/Docker.app/
+           Generation: 1
+    Bundle Identifier: com.docker.docker
+    Embedded Item Identifiers:
+      #1: 16.com.docker.vmnetd
+
+========================
+ Records for UID -2 : FFFFEEEE-DDDD-CCCC-BBBB-AAAAFFFF0000
+========================
+
+ Items:
+
+ #1:
+                 UUID: 2E2C0A7B-0000-0000-0000-000000000000
+                 Name: com.docker.vmnetd
+                 Type: legacy daemon (0x10010)
+          Disposition: [disabled, allowed, visible, notified] (0xa)
+           Identifier: 16.com.docker.vmnetd
+                  URL: file:///Library/LaunchDaemons/com.docker.vmnetd.plist
+      Executable Path: /Library/PrivilegedHelperTools/com.docker.vmnetd
+    Parent Identifier: 2.com.docker.docker
+`
+	items := parseDumpBTM(out)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d: %+v", len(items), items)
+	}
+	app := items[0]
+	if app.UID != 501 || app.Type != "app" || app.TeamID != "9BNSXJN65R" || app.BundleID != "com.docker.docker" {
+		t.Fatalf("unexpected app record: %+v", app)
+	}
+	if len(app.Disposition) != 4 || app.Disposition[0] != "enabled" {
+		t.Fatalf("unexpected disposition: %q", app.Disposition)
+	}
+	daemon := items[1]
+	if daemon.UID != -2 || daemon.Type != "legacy daemon" || daemon.ExecutablePath != "/Library/PrivilegedHelperTools/com.docker.vmnetd" {
+		t.Fatalf("unexpected daemon record: %+v", daemon)
+	}
+	if daemon.ParentApp != "Docker" {
+		t.Fatalf("expected parent app Docker, got %q", daemon.ParentApp)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// codesignInfo runs `codesign -dv` on path and returns its key=value
+// details (Identifier, TeamIdentifier, Authority, ...). codesign prints them
+// on stderr.
+func codesignInfo(path string) (map[string]string, error) {
+	cmd, done := toolCommand("codesign", "-dv", "--verbose=2", path)
+	out, err := cmd.CombinedOutput()
+	err = done(err)
+	if err != nil {
+		return nil, err
+	}
+	return parseCodesignInfo(string(out)), nil
+}
+
+// parseCodesignInfo parses codesign's "Key=Value" lines. Repeated keys such
+// as Authority keep their first value.
+func parseCodesignInfo(out string) map[string]string {
+	info := map[string]string{}
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		k, v, ok := strings.Cut(s.Text(), "=")
+		if !ok {
+			continue
+		}
+		k = strings.TrimSpace(k)
+		if _, exists := info[k]; !exists {
+			info[k] = strings.TrimSpace(v)
+		}
+	}
+	return info
+}
+
+// programTeamID returns the code-signing Team ID of a program, or "" when it
+// is unsigned, ad-hoc signed, or missing.
+func programTeamID(program string) string {
+	info, err := codesignInfo(program)
+	if err != nil {
+		return ""
+	}
+	team := info["TeamIdentifier"]
+	if team == "not set" {
+		return ""
+	}
+	return team
+}
+
+// CodeSignature is the result of verifying a program's signature.
+type CodeSignature struct {
+	Signer string `json:"signer,omitempty"`
+	TeamID string `json:"team_id,omitempty"`
+	Valid  bool   `json:"valid"`
+	// Error is codesign's reason when verification failed, e.g. "code object
+	// is not signed at all".
+	Error string `json:"error,omitempty"`
+}
+
+// verifyCodeSignature checks program's signature with `codesign --verify`
+// and reads who signed it.
+func verifyCodeSignature(program string) CodeSignature {
+	var sig CodeSignature
+	cmd, done := toolCommand("codesign", "--verify", "--strict", program)
+	out, err := cmd.CombinedOutput()
+	err = done(err)
+	if err != nil {
+		sig.Error = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), program+":"))
+		if sig.Error == "" {
+			sig.Error = err.Error()
+		}
+	} else {
+		sig.Valid = true
+	}
+	if info, err := codesignInfo(program); err == nil {
+		sig.Signer = info["Authority"]
+		if team := info["TeamIdentifier"]; team != "not set" {
+			sig.TeamID = team
+		}
+	}
+	return sig
+}
+
+// describeSignature renders sig as one line for listings.
+func describeSignature(sig CodeSignature) string {
+	s := fmt.Sprintf("signer: %s  team: %s  valid: %t", orDash(sig.Signer), orDash(sig.TeamID), sig.Valid)
+	if sig.Error != "" {
+		s += " (" + sig.Error + ")"
+	}
+	return s
+}
+
+// fillSignatures verifies the program of each item that has a plist.
+func fillSignatures(items []BackgroundItem) {
+	for i := range items {
+		if items[i].Path == "" || items[i].Kind == "startup" {
+			continue
+		}
+		plist, err := readPlist(items[i].Path)
+		if err != nil {
+			continue
+		}
+		if program := plistProgram(plist); program != "" {
+			sig := verifyCodeSignature(program)
+			items[i].Signature = &sig
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseCodesignInfo(t *testing.T) {
+	out := `Executable=/Applications/Foo.app/Contents/MacOS/Foo
+Identifier=com.foo.app
+Format=app bundle with Mach-O universal (x86_64 arm64)
+Authority=Developer ID Application: Foo Inc (ABCDE12345)
+Authority=Developer ID Certification Authority
+Authority=Apple Root CA
+TeamIdentifier=ABCDE12345
+`
+	info := parseCodesignInfo(out)
+	if info["TeamIdentifier"] != "ABCDE12345" {
+		t.Fatalf("unexpected team id: %q", info["TeamIdentifier"])
+	}
+	if info["Authority"] != "Developer ID Application: Foo Inc (ABCDE12345)" {
+		t.Fatalf("unexpected authority: %q", info["Authority"])
+	}
+}
+
+func TestLabelHasVendor(t *testing.T) {
+	if !labelHasVendor("com.adobe.acc.installer", "Adobe") {
+		t.Fatalf("expected adobe to match")
+	}
+	if labelHasVendor("com.adobelike.agent", "adobe") {
+		t.Fatalf("expected partial component not to match")
+	}
+}
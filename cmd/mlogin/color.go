@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// noColor is the global --no-color flag. Without it lipgloss already
+// drops colors when NO_COLOR is set or the output is not a terminal.
+var noColor bool
+
+var stderrRenderer = lipgloss.NewRenderer(os.Stderr)
+
+var (
+	headerStyle     = lipgloss.NewStyle().Bold(true)
+	disabledStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	cautionStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	suspiciousStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+	warningStyle    = stderrRenderer.NewStyle().Foreground(lipgloss.Color("3"))
+)
+
+func disableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	stderrRenderer.SetColorProfile(termenv.Ascii)
+}
+
+// warn prints a warning to stderr.
+func warn(a ...any) {
+	fmt.Fprintln(os.Stderr, append([]any{warningStyle.Render("warning:")}, a...)...)
+}
+
+func warnf(format string, a ...any) {
+	warn(fmt.Sprintf(format, a...))
+}
+
+// riskStyle colors a risk score: suspicious scores stand out, lower
+// non-zero scores are a caution.
+func riskStyle(risk int) lipgloss.Style {
+	switch {
+	case risk >= suspiciousRisk:
+		return suspiciousStyle
+	case risk > 0:
+		return cautionStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
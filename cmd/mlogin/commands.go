@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// command is one top-level mlogin command. run parses the command's own
+// flags and dispatches on its subcommands; the tree adds help, usage
+// lines, and suggestions for mistyped names on top.
+type command struct {
+	name    string
+	aliases []string
+	summary string
+	// subcommands are the subcommand names run dispatches on. A command
+	// with subcommands rejects any other non-flag first argument.
+	subcommands []string
+	run         func(args []string) error
+}
+
+var commands = []*command{
+	{name: "version", summary: "print the version", run: func([]string) error { printVersion(); return nil }},
+	{name: "tui", aliases: []string{"ui"}, summary: "browse and manage items in an interactive table", run: func([]string) error { return runTUI() }},
+	{name: "login", summary: "login items (System Events)", subcommands: []string{"list", "add", "remove", "ensure"}, run: runLogin},
+	{name: "background", aliases: []string{"bg"}, summary: "launchd agents and daemons", subcommands: []string{
+		"list", "enable", "disable", "ensure", "load", "validate", "unload", "reload", "kickstart", "logs", "create", "edit",
+		"delete", "remove", "restore", "health", "drift", "show", "env", "info", "why", "blame", "prune",
+	}, run: runBackground},
+	{name: "extensions", aliases: []string{"ext"}, summary: "system extensions", subcommands: []string{"list", "info", "approve", "uninstall", "developer-mode", "reset"}, run: runExtensions},
+	{name: "kexts", summary: "kernel extensions", subcommands: []string{"list"}, run: runKexts},
+	{name: "cron", summary: "user and system crontabs", subcommands: []string{"list", "remove"}, run: runCron},
+	{name: "at", summary: "at jobs", subcommands: []string{"list", "remove"}, run: runAt},
+	{name: "hooks", summary: "login and logout hooks", subcommands: []string{"list", "clear"}, run: runHooks},
+	{name: "emond", summary: "emond rules", subcommands: []string{"list"}, run: runEmond},
+	{name: "folderactions", summary: "Folder Actions scripts", subcommands: []string{"list", "remove"}, run: runFolderActions},
+	{name: "authplugins", summary: "authorization plugins", subcommands: []string{"list"}, run: runAuthPlugins},
+	{name: "helpers", summary: "privileged helper tools", subcommands: []string{"list", "remove"}, run: runHelpers},
+	{name: "profiles", summary: "configuration profiles", subcommands: []string{"list"}, run: runProfiles},
+	{name: "btm", summary: "the Background Task Management database", subcommands: []string{"list", "reset"}, run: runBTM},
+	{name: "env-audit", summary: "find environment injection in launchd jobs and shells", run: runEnvAudit},
+	{name: "audit", summary: "report every persistence surface", run: runAudit},
+	{name: "whois", summary: "explain what a label, path, or bundle ID belongs to", run: runWhois},
+	{name: "snapshot", summary: "save the current state", run: runSnapshot},
+	{name: "diff", summary: "compare two snapshots", run: runDiff},
+	{name: "apply", summary: "converge on a manifest", run: runApply},
+	{name: "profile", summary: "save and switch named manifests", subcommands: []string{"list", "save", "switch"}, run: runProfile},
+	{name: "log", summary: "list committed snapshots", run: runLog},
+	{name: "baseline", summary: "record and check a signed baseline", subcommands: []string{"set", "check"}, run: runBaseline},
+	{name: "watch", summary: "report changes as they happen", run: runWatch},
+	{name: "serve", summary: "serve the REST API", run: runServe},
+	{name: "mcp", summary: "serve the MCP server on stdio", run: runMCP},
+	{name: "exporter", summary: "serve Prometheus metrics", run: runExporter},
+	{name: "agent", summary: "install mlogin as a background agent", subcommands: []string{"install", "uninstall", "status"}, run: runAgent},
+	{name: "history", summary: "show the action log", run: runHistory},
+	{name: "fleet", summary: "run a command on many Macs over ssh", run: runFleet},
+	{name: "report", summary: "write an HTML or Markdown report", run: runReport},
+	{name: "timeline", summary: "query the change timeline", run: runTimeline},
+	{name: "schema", summary: "print the JSON schema of an output type", run: runSchema},
+	{name: "open", summary: "reveal an item in Finder or open System Settings", subcommands: []string{"settings"}, run: runOpen},
+	{name: "stats", summary: "count items by scope, kind, vendor, and Team ID", run: runStats},
+	{name: "doctor", summary: "check permissions and tools mlogin depends on", run: runDoctor},
+}
+
+// globalFlagsHelp describes the flags every command accepts, anywhere on
+// the command line.
+const globalFlagsHelp = `Global flags:
+  --no-sudo                 do not retry launchctl calls with sudo
+  --no-color                print without colors (also NO_COLOR)
+  --no-pager                do not page long listings
+  --dry-run                 print what would change instead of changing it
+  --quiet                   say nothing when a change succeeds
+  --verbose                 print each launchctl and osascript call
+  --timeout DURATION        give up on an external command after this long
+                            (default 1m, 0 waits forever)
+  --host user@mac           run on another Mac over ssh
+  --remote-mlogin PATH      mlogin on the remote Mac
+  -o, --output FORMAT       table, json, ndjson, yaml, csv, or tsv`
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name || slices.Contains(c.aliases, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func isHelpArg(a string) bool {
+	return a == "-h" || a == "--help" || a == "help"
+}
+
+// dispatch runs the command args name, or prints its help.
+func dispatch(args []string) error {
+	switch args[0] {
+	case "--version", "-v":
+		printVersion()
+		return nil
+	case "help", "-h", "--help":
+		if len(args) > 1 {
+			c := findCommand(args[1])
+			if c == nil {
+				return unknownCommandError(args[1])
+			}
+			printCommandHelp(os.Stdout, c)
+			return nil
+		}
+		printUsage()
+		return nil
+	}
+	c := findCommand(args[0])
+	if c == nil {
+		return unknownCommandError(args[0])
+	}
+	rest := args[1:]
+	if len(rest) > 0 && isHelpArg(rest[0]) {
+		printCommandHelp(os.Stdout, c)
+		return nil
+	}
+	if len(c.subcommands) > 0 && len(rest) > 0 && !strings.HasPrefix(rest[0], "-") && !slices.Contains(c.subcommands, rest[0]) {
+		msg := fmt.Sprintf("unknown %s subcommand %q", c.name, rest[0])
+		if s := suggest(rest[0], c.subcommands); s != "" {
+			msg += fmt.Sprintf("; did you mean %q?", s)
+		}
+		return usagef("%s (see mlogin %s --help)", msg, c.name)
+	}
+	return c.run(rest)
+}
+
+func unknownCommandError(name string) error {
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.name)
+		names = append(names, c.aliases...)
+	}
+	msg := fmt.Sprintf("unknown command %q", name)
+	if s := suggest(name, names); s != "" {
+		msg += fmt.Sprintf("; did you mean %q?", s)
+	}
+	return usagef("%s (see mlogin help)", msg)
+}
+
+// printCommandHelp prints a command's usage lines from the main usage
+// text, followed by the global flags.
+func printCommandHelp(w io.Writer, c *command) {
+	fmt.Fprintf(w, "mlogin %s - %s\n\nUsage:\n", c.name, c.summary)
+	for _, line := range commandUsage(c.name) {
+		fmt.Fprintln(w, line)
+	}
+	if len(c.aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(c.aliases, ", "))
+	}
+	if len(c.subcommands) > 0 {
+		fmt.Fprintf(w, "\nRun mlogin %s <subcommand> --help to describe its flags.\n", c.name)
+	}
+	fmt.Fprintf(w, "\n%s\n", globalFlagsHelp)
+}
+
+// commandUsage returns the lines of the usage text for the command name,
+// including their continuation lines.
+func commandUsage(name string) []string {
+	var lines []string
+	in := false
+	s := bufio.NewScanner(strings.NewReader(usageText))
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "  mlogin "+name+" ") || line == "  mlogin "+name:
+			in = true
+			lines = append(lines, line)
+		case in && strings.HasPrefix(line, "     "):
+			lines = append(lines, line)
+		default:
+			in = false
+		}
+	}
+	return lines
+}
+
+// suggest returns the candidate closest to name, if any is close enough to
+// be a typo of it.
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		d := editDistance(name, c)
+		if d < bestDist || (d == bestDist && best != "" && c < best) {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		for _, c := range candidates {
+			if len(name) >= 3 && strings.HasPrefix(c, name) {
+				return c
+			}
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
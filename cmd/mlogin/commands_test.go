@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"backgroud", "background"},
+		{"enabel", "enable"},
+		{"extension", "extensions"},
+		{"snap", "snapshot"},
+		{"zzzzzz", ""},
+	}
+	candidates := []string{"background", "enable", "extensions", "snapshot", "audit"}
+	for _, c := range cases {
+		if got := suggest(c.name, candidates); got != c.want {
+			t.Fatalf("suggest(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	lines := commandUsage("background")
+	var create bool
+	for i, l := range lines {
+		if strings.HasPrefix(l, "  mlogin background create ") {
+			create = i+1 < len(lines) && strings.Contains(lines[i+1], "[--log <path>]")
+		}
+	}
+	if !create {
+		t.Fatalf("background create usage lost its continuation line: %q", lines)
+	}
+}
+
+// Every command has usage lines, and the usage text names no command the
+// tree does not know.
+func TestCommandsMatchUsage(t *testing.T) {
+	for _, c := range commands {
+		if len(commandUsage(c.name)) == 0 {
+			t.Fatalf("no usage lines for %s", c.name)
+		}
+	}
+	for _, line := range strings.Split(usageText, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "mlogin" || strings.HasPrefix(fields[1], "[") || fields[1] == "-" {
+			continue
+		}
+		if findCommand(fields[1]) == nil {
+			t.Fatalf("usage line for unknown command: %q", line)
+		}
+	}
+}
+
+func TestDispatchUnknownSubcommand(t *testing.T) {
+	err := dispatch([]string{"background", "enabel", "--label", "x"})
+	if err == nil || !strings.Contains(err.Error(), `did you mean "enable"`) {
+		t.Fatalf("got %v", err)
+	}
+	if exitCode(err) != exitUsage {
+		t.Fatalf("exit code %d, want %d", exitCode(err), exitUsage)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Config holds defaults read from the config file at startup. Flags given
+// on the command line win over it.
+type Config struct {
+	// Output is the default --output format.
+	Output string `json:"output"`
+	// Scope is the default --scope of commands that list or check every
+	// scope (background list, prune, the audit helpers).
+	Scope string `json:"scope"`
+	// HideApple is the default of background list --hide-apple and of the
+	// TUI's Apple toggle.
+	HideApple *bool `json:"hide_apple"`
+	// Color false is the same as --no-color.
+	Color *bool `json:"color"`
+	// Theme recolors the named styles; values are ANSI numbers ("1") or
+	// hex colors ("#ff5f87").
+	Theme map[string]string `json:"theme"`
+	// Confirm false skips the confirmation prompts, like --yes, in the CLI
+	// and the TUI.
+	Confirm *bool `json:"confirm"`
+	// Aliases are short names for labels and bundle IDs, e.g.
+	// {"ts": "io.tailscale.ipn.macsys.network-extension"}, accepted by
+	// every --label and --bundle-id flag.
+	Aliases map[string]string `json:"aliases"`
+	TUI     struct {
+		// Keys binds TUI actions to other keys, e.g. {"delete": "D"}.
+		Keys map[string]string `json:"keys"`
+	} `json:"tui"`
+}
+
+// config is the loaded config file, or the zero Config when there is none.
+var config Config
+
+// themeStyles are the styles a config theme can recolor.
+var themeStyles = map[string]*lipgloss.Style{
+	"header":     &headerStyle,
+	"disabled":   &disabledStyle,
+	"caution":    &cautionStyle,
+	"suspicious": &suspiciousStyle,
+	"warning":    &warningStyle,
+}
+
+// configPath is $MLOGIN_CONFIG, or config.yaml under $XDG_CONFIG_HOME/mlogin
+// (~/.config/mlogin by default).
+func configPath() (string, error) {
+	if p := os.Getenv("MLOGIN_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "mlogin", "config.yaml"), nil
+}
+
+// loadConfig reads and applies the config file. A missing file is not an
+// error; an unreadable or invalid one leaves the defaults in place.
+func loadConfig() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c, err := parseConfig(data)
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	config = c
+	applyConfig(c)
+	return nil
+}
+
+// parseConfig decodes and checks a config file, rejecting unknown keys so
+// a typo does not silently do nothing.
+func parseConfig(data []byte) (Config, error) {
+	var generic any
+	if err := readYAML(data, &generic); err != nil {
+		return Config{}, err
+	}
+	raw, err := json.Marshal(generic)
+	if err != nil {
+		return Config{}, err
+	}
+	var c Config
+	if generic != nil {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := checkOutputFormat(c.Output); err != nil {
+		return Config{}, err
+	}
+	if c.Scope != "" && !slices.Contains([]string{"user", "system", "all"}, c.Scope) {
+		return Config{}, fmt.Errorf("scope must be user, system, or all, not %q", c.Scope)
+	}
+	for name := range c.Theme {
+		if _, ok := themeStyles[name]; !ok {
+			return Config{}, fmt.Errorf("unknown theme style %q (want %s)", name, strings.Join(sortedKeys(themeStyles), ", "))
+		}
+	}
+	for alias, target := range c.Aliases {
+		if strings.TrimSpace(target) == "" {
+			return Config{}, fmt.Errorf("alias %q has no label", alias)
+		}
+	}
+	for action := range c.TUI.Keys {
+		if _, ok := tuiDefaultKeys[action]; !ok {
+			return Config{}, fmt.Errorf("unknown TUI action %q (want %s)", action, strings.Join(sortedKeys(tuiDefaultKeys), ", "))
+		}
+	}
+	return c, nil
+}
+
+// applyConfig sets the globals the config provides defaults for, before
+// the global flags are parsed over them.
+func applyConfig(c Config) {
+	outputFormat = c.Output
+	if c.Color != nil && !*c.Color {
+		noColor = true
+	}
+	for name, color := range c.Theme {
+		s := themeStyles[name]
+		*s = s.Foreground(lipgloss.Color(color))
+	}
+}
+
+// defaultScope is the configured scope, or fallback when none is set.
+func defaultScope(fallback string) string {
+	if config.Scope != "" {
+		return config.Scope
+	}
+	return fallback
+}
+
+// defaultHideApple is the configured hide_apple, true when unset.
+func defaultHideApple() bool {
+	return config.HideApple == nil || *config.HideApple
+}
+
+// confirmationsOff reports whether the config turned confirmation prompts
+// off.
+func confirmationsOff() bool {
+	return config.Confirm != nil && !*config.Confirm
+}
+
+// resolveAlias returns the label or bundle ID the configured alias name
+// stands for, or name itself when it is not an alias.
+func resolveAlias(name string) string {
+	if target, ok := config.Aliases[name]; ok {
+		return target
+	}
+	return name
+}
+
+// labelFlag defines a string flag for a label or bundle ID that accepts
+// configured aliases in place of it.
+func labelFlag(fs *flag.FlagSet, name, usage string) *string {
+	p := new(string)
+	fs.Func(name, usage, func(s string) error {
+		*p = resolveAlias(s)
+		return nil
+	})
+	return p
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	c, err := parseConfig([]byte(`# mlogin defaults
+output: json
+scope: user
+hide_apple: false
+confirm: false
+theme:
+  header: "#ff5f87"
+tui:
+  keys:
+    delete: D
+`))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if c.Output != "json" || c.Scope != "user" || c.HideApple == nil || *c.HideApple || c.Confirm == nil || *c.Confirm {
+		t.Fatalf("got %+v", c)
+	}
+	if c.Theme["header"] != "#ff5f87" || c.TUI.Keys["delete"] != "D" {
+		t.Fatalf("got theme %v, keys %v", c.Theme, c.TUI.Keys)
+	}
+
+	for _, bad := range []string{"outptu: json\n", "output: xml\n", "scope: everything\n", "theme:\n  banner: \"1\"\n", "tui:\n  keys:\n    explode: z\n"} {
+		if _, err := parseConfig([]byte(bad)); err == nil {
+			t.Fatalf("parseConfig(%q) succeeded", bad)
+		}
+	}
+	if _, err := parseConfig(nil); err != nil {
+		t.Fatalf("empty config: %v", err)
+	}
+}
+
+func TestTUIKeyBindings(t *testing.T) {
+	defer func() { config = Config{} }()
+	config.TUI.Keys = map[string]string{"delete": "D", "quit": "ctrl+q"}
+	if got := tuiKey("D"); got != "x" {
+		t.Fatalf("tuiKey(D) = %q, want x", got)
+	}
+	if got := tuiKey("r"); got != "r" {
+		t.Fatalf("tuiKey(r) = %q, want r", got)
+	}
+	if got := tuiKeyName("quit"); got != "ctrl+q" {
+		t.Fatalf("tuiKeyName(quit) = %q", got)
+	}
+	if got := tuiKeyName("refresh"); got != "r" {
+		t.Fatalf("tuiKeyName(refresh) = %q", got)
+	}
+}
+
+func TestLabelAliases(t *testing.T) {
+	defer func() { config = Config{} }()
+	c, err := parseConfig([]byte("aliases:\n  ts: io.tailscale.ipn.macsys.network-extension\n  backup: com.me.backup\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	config = c
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	label := labelFlag(fs, "label", "launchd label")
+	if err := fs.Parse([]string{"--label", "backup"}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if *label != "com.me.backup" {
+		t.Fatalf("label = %q", *label)
+	}
+	if got := resolveAlias("com.example.agent"); got != "com.example.agent" {
+		t.Fatalf("resolveAlias left %q", got)
+	}
+	if _, err := parseConfig([]byte("aliases:\n  ts: \"\"\n")); err == nil {
+		t.Fatalf("expected an error for an empty alias")
+	}
+}
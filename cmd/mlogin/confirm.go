@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// errNotInteractive is returned when a destructive command would have to
+// ask but has no terminal to ask on.
+var errNotInteractive = errors.New("refusing to continue without confirmation: stdin is not a terminal (pass --yes)")
+
+// addYesFlags registers --yes and its alias --force on fs.
+func addYesFlags(fs *flag.FlagSet, usage string) *bool {
+	yes := fs.Bool("yes", false, usage)
+	fs.BoolVar(yes, "force", false, "same as --yes")
+	return yes
+}
+
+// confirmAction asks before a destructive change unless yes is set or the
+// config turned confirmations off. With no terminal on stdin it refuses
+// rather than going ahead, so a script has to opt in with --yes, the same
+// way the TUI asks before it deletes.
+func confirmAction(prompt string, yes bool) error {
+	return confirmReset(prompt, yes || confirmationsOff())
+}
+
+// confirmReset is confirmAction for resets that wipe state for the whole
+// machine. The config cannot turn it off; only --yes skips it.
+func confirmReset(prompt string, yes bool) error {
+	if yes {
+		return nil
+	}
+	if !dryRun && !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errNotInteractive
+	}
+	if !confirm(prompt) {
+		return errors.New("cancelled")
+	}
+	return nil
+}
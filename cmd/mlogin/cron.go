@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+type CronEntry struct {
+	Source   string `json:"source"`
+	User     string `json:"user"`
+	Line     int    `json:"line"`
+	Schedule string `json:"schedule"`
+	Command  string `json:"command"`
+}
+
+const (
+	systemCrontab = "/etc/crontab"
+	// userCrontabDir holds per-user crontabs on macOS; only root can read it.
+	userCrontabDir = "/usr/lib/cron/tabs"
+)
+
+func runCron(args []string) error {
+	if len(args) == 0 {
+		return usagef("missing cron subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("cron list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		entries, warnings, err := listCronEntries()
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			warn(w)
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(entries)
+		}
+		printCronEntries(entries)
+		return nil
+	case "remove":
+		fs := flag.NewFlagSet("cron remove", flag.ContinueOnError)
+		line := fs.Int("line", 0, "line number shown by cron list")
+		source := fs.String("source", "user", "user|system")
+		yes := fs.Bool("yes", false, "remove without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *line <= 0 {
+			return usagef("--line is required")
+		}
+		return removeCronLine(*source, *line, *yes)
+	default:
+		return usagef("unknown cron subcommand %q", args[0])
+	}
+}
+
+// listCronEntries returns the jobs in /etc/crontab and the users' crontabs.
+// As root every user's crontab is read from userCrontabDir; otherwise only
+// the current user's, via crontab -l.
+func listCronEntries() ([]CronEntry, []string, error) {
+	var entries []CronEntry
+	var warnings []string
+	if data, err := os.ReadFile(systemCrontab); err == nil {
+		entries = append(entries, parseCrontab(systemCrontab, "", string(data))...)
+	} else if !os.IsNotExist(err) {
+		warnings = append(warnings, err.Error())
+	}
+
+	if os.Geteuid() == 0 {
+		tabs, _ := filepath.Glob(filepath.Join(userCrontabDir, "*"))
+		for _, tab := range tabs {
+			data, err := os.ReadFile(tab)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+				continue
+			}
+			name := filepath.Base(tab)
+			entries = append(entries, parseCrontab("user:"+name, name, string(data))...)
+		}
+		return entries, warnings, nil
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return nil, nil, err
+	}
+	text, err := userCrontab()
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	entries = append(entries, parseCrontab("user:"+u.Username, u.Username, text)...)
+	return entries, warnings, nil
+}
+
+// userCrontab returns the current user's crontab, or "" when they have
+// none.
+func userCrontab() (string, error) {
+	cmd, done := toolCommand("crontab", "-l")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "no crontab for") {
+			return "", nil
+		}
+		if msg != "" {
+			return "", fmt.Errorf("crontab -l: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("crontab -l: %w", err)
+	}
+	return string(out), nil
+}
+
+// parseCrontab returns the job lines of a crontab. Comments, blank lines,
+// and environment assignments are skipped but still counted, so Line
+// matches the file. /etc/crontab is in system format, with the user as the
+// field after the schedule.
+func parseCrontab(source, owner, text string) []CronEntry {
+	var entries []CronEntry
+	s := bufio.NewScanner(strings.NewReader(text))
+	n := 0
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if strings.Contains(fields[0], "=") {
+			continue
+		}
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+		rest := fields[min(scheduleFields, len(fields)):]
+		e := CronEntry{Source: source, User: owner, Line: n, Schedule: strings.Join(fields[:min(scheduleFields, len(fields))], " ")}
+		if source == systemCrontab && len(rest) > 0 {
+			e.User = rest[0]
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			continue
+		}
+		e.Command = strings.Join(rest, " ")
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// removeCronLine deletes one job line from the current user's crontab or
+// from /etc/crontab. The line must be a job as listed by `cron list`.
+func removeCronLine(source string, line int, yes bool) error {
+	var text string
+	var err error
+	switch source {
+	case "user":
+		text, err = userCrontab()
+	case "system":
+		var data []byte
+		data, err = os.ReadFile(systemCrontab)
+		text = string(data)
+	default:
+		return usagef("unknown source %q (want user or system)", source)
+	}
+	if err != nil {
+		return err
+	}
+	parseAs := "user"
+	if source == "system" {
+		parseAs = systemCrontab
+	}
+	var target *CronEntry
+	for _, e := range parseCrontab(parseAs, "", text) {
+		if e.Line == line {
+			target = &e
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("line %d of the %s crontab is not a job", line, source)
+	}
+	if err := confirmAction(fmt.Sprintf("Remove %q?", target.Schedule+" "+target.Command), yes); err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	updated := strings.Join(append(lines[:line-1:line-1], lines[line:]...), "")
+	if source == "system" {
+		if dryRunf("write %s without line %d", systemCrontab, line) {
+			return nil
+		}
+		info, err := os.Stat(systemCrontab)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(systemCrontab, []byte(updated), info.Mode().Perm())
+		recordAction("cron remove", fmt.Sprintf("%s crontab line %d", source, line), source, target.Schedule+" "+target.Command, err)
+		if err != nil {
+			return err
+		}
+	} else {
+		if dryRunf("run: crontab - (the current crontab without line %d)", line) {
+			return nil
+		}
+		cmd, done := toolCommand("crontab", "-")
+		cmd.Stdin = strings.NewReader(updated)
+		out, err := cmd.CombinedOutput()
+		if err = done(err); err != nil {
+			err = fmt.Errorf("crontab: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		recordAction("cron remove", fmt.Sprintf("%s crontab line %d", source, line), source, target.Schedule+" "+target.Command, err)
+		if err != nil {
+			return err
+		}
+	}
+	successf("removed line %d from the %s crontab", line, source)
+	return nil
+}
+
+func printCronEntries(entries []CronEntry) {
+	if len(entries) == 0 {
+		fmt.Println("No cron jobs found")
+		return
+	}
+	fmt.Printf("%-16s %-10s %-5s %-20s %s\n", "SOURCE", "USER", "LINE", "SCHEDULE", "COMMAND")
+	for _, e := range entries {
+		fmt.Printf("%-16s %-10s %-5d %-20s %s\n", e.Source, e.User, e.Line, e.Schedule, e.Command)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestParseCrontab(t *testing.T) {
+	text := `# m h dom mon dow command
+MAILTO=""
+*/15 * * * * /usr/local/bin/sync --quiet
+
+@reboot /Users/me/bin/start.sh
+`
+	entries := parseCrontab("user:me", "me", text)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d (%+v)", len(entries), entries)
+	}
+	if entries[0].Line != 3 || entries[0].Schedule != "*/15 * * * *" || entries[0].Command != "/usr/local/bin/sync --quiet" || entries[0].User != "me" {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Line != 5 || entries[1].Schedule != "@reboot" || entries[1].Command != "/Users/me/bin/start.sh" {
+		t.Fatalf("unexpected second entry: %+v", entries[1])
+	}
+
+	system := parseCrontab(systemCrontab, "", "0 3 * * * root /usr/sbin/periodic daily\n")
+	if len(system) != 1 || system[0].User != "root" || system[0].Command != "/usr/sbin/periodic daily" {
+		t.Fatalf("unexpected system entry: %+v", system)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SnapshotChange is one difference between two snapshots.
+type SnapshotChange struct {
+	Surface string        `json:"surface"`
+	Name    string        `json:"name"`
+	Change  string        `json:"change"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// snapshotEntry is an item of any surface reduced to comparable fields.
+type snapshotEntry struct {
+	surface string
+	name    string
+	fields  map[string]string
+}
+
+func runDiff(args []string) error {
+	c := client()
+	var paths []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		paths, args = append(paths, args[0]), args[1:]
+	}
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	paths = append(paths, fs.Args()...)
+	if len(paths) == 0 || len(paths) > 2 {
+		return usagef("usage: mlogin diff old.json [new.json]")
+	}
+	old, err := readSnapshot(paths[0])
+	if err != nil {
+		return err
+	}
+	var cur Snapshot
+	if len(paths) == 2 {
+		if cur, err = readSnapshot(paths[1]); err != nil {
+			return err
+		}
+	} else {
+		cur = takeSnapshot(c)
+		for _, w := range cur.Warnings {
+			warn(w)
+		}
+	}
+	changes := diffSnapshots(old, cur)
+	if *jsonOut || outputFormat != "" {
+		if changes == nil {
+			changes = []SnapshotChange{}
+		}
+		return writeOutput(changes)
+	}
+	printSnapshotChanges(changes)
+	return nil
+}
+
+// snapshotEntries flattens a snapshot into entries keyed by surface and
+// name. Volatile state such as PIDs and health is left out so a diff shows
+// configuration changes, not process churn.
+func snapshotEntries(s Snapshot) map[string]snapshotEntry {
+	entries := map[string]snapshotEntry{}
+	add := func(surface, name string, fields map[string]string) {
+		entries[surface+"\x00"+name] = snapshotEntry{surface: surface, name: name, fields: fields}
+	}
+	for _, it := range s.LoginItems {
+		add("login items", it.Name, map[string]string{"path": it.Path, "hidden": strconv.FormatBool(it.Hidden)})
+	}
+	for _, it := range s.Background {
+		disabled := "unknown"
+		if it.Disabled != nil {
+			disabled = strconv.FormatBool(*it.Disabled)
+		}
+		fields := map[string]string{
+			"path":     it.Path,
+			"kind":     it.Kind,
+			"loaded":   strconv.FormatBool(it.Loaded),
+			"disabled": disabled,
+		}
+		// Hashes are only present when the caller computed them (the
+		// timeline does), so they are only compared then.
+		if it.SHA256 != "" {
+			fields["sha256"] = it.SHA256
+		}
+		add("launchd", it.Label+" ("+it.Scope+")", fields)
+	}
+	for _, it := range s.Extensions {
+		add("system extensions", it.BundleID, map[string]string{
+			"version": it.Version,
+			"state":   it.State,
+			"team_id": it.TeamID,
+		})
+	}
+	for _, surface := range s.Surfaces {
+		for _, e := range surface.Entries {
+			name := e.Name
+			if e.Path != "" {
+				name += " (" + e.Path + ")"
+			}
+			add(surface.Surface, name, map[string]string{
+				"program": e.Program,
+				"command": e.Command,
+				"detail":  e.Detail,
+			})
+		}
+	}
+	return entries
+}
+
+// diffSnapshots lists the entries added, removed, or changed from old to
+// cur, sorted by surface and name.
+func diffSnapshots(old, cur Snapshot) []SnapshotChange {
+	before, after := snapshotEntries(old), snapshotEntries(cur)
+	var changes []SnapshotChange
+	for key, b := range before {
+		a, ok := after[key]
+		if !ok {
+			changes = append(changes, SnapshotChange{Surface: b.surface, Name: b.name, Change: "removed"})
+			continue
+		}
+		var fields []FieldChange
+		for f, v := range b.fields {
+			if a.fields[f] != v {
+				fields = append(fields, FieldChange{Field: f, Old: v, New: a.fields[f]})
+			}
+		}
+		if len(fields) > 0 {
+			sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+			changes = append(changes, SnapshotChange{Surface: b.surface, Name: b.name, Change: "changed", Fields: fields})
+		}
+	}
+	for key, a := range after {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, SnapshotChange{Surface: a.surface, Name: a.name, Change: "added"})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Surface != changes[j].Surface {
+			return changes[i].Surface < changes[j].Surface
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func printSnapshotChanges(changes []SnapshotChange) {
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	styles := map[string]lipgloss.Style{
+		"added":   lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		"removed": lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+		"changed": lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+	}
+	marks := map[string]string{"added": "+", "removed": "-", "changed": "~"}
+	for _, c := range changes {
+		fmt.Println(styles[c.Change].Render(fmt.Sprintf("%s [%s] %s", marks[c.Change], c.Surface, c.Name)))
+		for _, f := range c.Fields {
+			fmt.Printf("    %s: %s -> %s\n", f.Field, orDash(f.Old), orDash(f.New))
+		}
+	}
+}
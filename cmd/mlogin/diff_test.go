@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestDiffSnapshots(t *testing.T) {
+	enabled, disabled := false, true
+	old := Snapshot{
+		LoginItems: []LoginItem{{Name: "Slack", Path: "/Applications/Slack.app"}},
+		Background: []BackgroundItem{{Label: "com.example.agent", Scope: "user", Kind: "agent", Disabled: &enabled}},
+	}
+	cur := Snapshot{
+		Background: []BackgroundItem{
+			{Label: "com.example.agent", Scope: "user", Kind: "agent", Disabled: &disabled},
+			{Label: "com.example.updater", Scope: "system", Kind: "daemon"},
+		},
+		Surfaces: []AuditSurface{{Surface: "cron", Entries: []AuditEntry{{Name: "user:alice line 3", Command: "/usr/local/bin/backup"}}}},
+	}
+	changes := diffSnapshots(old, cur)
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d: %+v", len(changes), changes)
+	}
+	want := []struct{ surface, change string }{
+		{"cron", "added"},
+		{"launchd", "changed"},
+		{"launchd", "added"},
+		{"login items", "removed"},
+	}
+	for i, w := range want {
+		if changes[i].Surface != w.surface || changes[i].Change != w.change {
+			t.Fatalf("change %d = %+v, want %s %s", i, changes[i], w.surface, w.change)
+		}
+	}
+	if f := changes[1].Fields; len(f) != 1 || f[0].Field != "disabled" || f[0].Old != "false" || f[0].New != "true" {
+		t.Fatalf("unexpected field changes: %+v", f)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// doctorCheck is the outcome of one `mlogin doctor` check.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // pass, warn, or fail
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	checks := []doctorCheck{
+		checkTool("osascript", "mlogin only runs on macOS, where osascript is /usr/bin/osascript"),
+		checkAutomation(client()),
+		checkTool("launchctl", "mlogin only runs on macOS, where launchctl is /bin/launchctl"),
+		checkTool("systemextensionsctl", "system extensions need macOS 10.15 or later"),
+		checkSIP(),
+		checkFullDiskAccess(),
+		checkSudo(),
+		checkTerminal(),
+	}
+	if *jsonOut || outputFormat != "" {
+		if err := writeOutput(checks); err != nil {
+			return err
+		}
+	} else {
+		printDoctorChecks(checks)
+	}
+	failed := 0
+	for _, c := range checks {
+		if c.Status == "fail" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d doctor check(s) failed", failed)
+	}
+	return nil
+}
+
+func printDoctorChecks(checks []doctorCheck) {
+	for _, c := range checks {
+		status := strings.ToUpper(c.Status)
+		switch c.Status {
+		case "warn":
+			status = cautionStyle.Render(status)
+		case "fail":
+			status = disabledStyle.Render(status)
+		}
+		fmt.Printf("%s  %-20s %s\n", status, c.Name, c.Detail)
+		if c.Fix != "" {
+			fmt.Printf("      %-20s fix: %s\n", "", c.Fix)
+		}
+	}
+}
+
+func checkTool(name, fix string) doctorCheck {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return doctorCheck{Name: name, Status: "fail", Detail: "not found in PATH", Fix: fix}
+	}
+	return doctorCheck{Name: name, Status: "pass", Detail: path}
+}
+
+// checkAutomation asks System Events for the login items the way the login
+// commands do, which fails until the terminal may control System Events.
+func checkAutomation(c mlogin.Client) doctorCheck {
+	check := doctorCheck{Name: "automation"}
+	if _, err := exec.LookPath("osascript"); err != nil {
+		check.Status, check.Detail = "fail", "osascript is missing"
+		return check
+	}
+	_, stderr, err := runOSA(c, "Application('System Events').loginItems.length;", nil)
+	switch {
+	case err == nil:
+		check.Status, check.Detail = "pass", "may control System Events"
+	case automationDenied(stderr):
+		check.Status, check.Detail = "fail", "not allowed to control System Events; login commands will fail"
+		check.Fix = "System Settings > Privacy & Security > Automation: allow your terminal to control System Events (or run `tccutil reset AppleEvents` and accept the prompt)"
+	default:
+		check.Status, check.Detail = "fail", "osascript failed: "+orDash(strings.TrimSpace(stderr))
+	}
+	return check
+}
+
+// automationDenied reports whether osascript failed because the Automation
+// (Apple Events) permission is missing: error -1743, or -1744 when the
+// user has not answered the prompt yet.
+func automationDenied(stderr string) bool {
+	return strings.Contains(stderr, "-1743") || strings.Contains(stderr, "-1744") ||
+		strings.Contains(stderr, "Not authorized to send Apple events")
+}
+
+func checkSIP() doctorCheck {
+	c := doctorCheck{Name: "sip"}
+	cmd, done := toolCommand("csrutil", "status")
+	out, err := cmd.CombinedOutput()
+	err = done(err)
+	if err != nil {
+		c.Status, c.Detail = "warn", "could not run csrutil status"
+		return c
+	}
+	enabled, ok := parseCSRUtilStatus(string(out))
+	switch {
+	case !ok:
+		c.Status, c.Detail = "warn", "unrecognized csrutil output: "+strings.TrimSpace(string(out))
+	case enabled:
+		c.Status, c.Detail = "pass", "enabled; items under /System are read-only"
+	default:
+		c.Status, c.Detail = "warn", "disabled; mlogin can change Apple's own services, which can leave macOS unbootable"
+		c.Fix = "boot into Recovery and run `csrutil enable`"
+	}
+	return c
+}
+
+// parseCSRUtilStatus reads `csrutil status` ("System Integrity Protection
+// status: enabled."). A custom configuration counts as enabled.
+func parseCSRUtilStatus(out string) (enabled, ok bool) {
+	_, status, found := strings.Cut(out, "status:")
+	if !found {
+		return false, false
+	}
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch {
+	case strings.HasPrefix(status, "enabled"):
+		return true, true
+	case strings.HasPrefix(status, "disabled"):
+		return false, true
+	case strings.HasPrefix(status, "unknown"):
+		return true, true
+	}
+	return false, false
+}
+
+// checkFullDiskAccess opens the user's TCC database, which only processes
+// with Full Disk Access may read.
+func checkFullDiskAccess() doctorCheck {
+	c := doctorCheck{Name: "full disk access"}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		c.Status, c.Detail = "warn", err.Error()
+		return c
+	}
+	f, err := os.Open(filepath.Join(home, "Library/Application Support/com.apple.TCC/TCC.db"))
+	switch {
+	case err == nil:
+		f.Close()
+		c.Status, c.Detail = "pass", "granted"
+	case errors.Is(err, fs.ErrPermission):
+		c.Status, c.Detail = "warn", "not granted; some plists and the BTM database cannot be read, so listings may be incomplete"
+		c.Fix = "System Settings > Privacy & Security > Full Disk Access: add your terminal"
+	default:
+		c.Status, c.Detail = "warn", "could not tell: "+err.Error()
+	}
+	return c
+}
+
+func checkSudo() doctorCheck {
+	c := doctorCheck{Name: "sudo"}
+	switch {
+	case os.Geteuid() == 0:
+		c.Status, c.Detail = "pass", "running as root"
+	case escalation == escalateNone:
+		c.Status, c.Detail = "warn", "--no-sudo given; system scope changes will fail"
+	default:
+		if _, err := exec.LookPath("sudo"); err != nil {
+			c.Status, c.Detail = "fail", "sudo not found; system scope changes will fail"
+			c.Fix = "run mlogin as root for --scope system"
+			return c
+		}
+		if exec.Command("sudo", "-n", "true").Run() == nil {
+			c.Status, c.Detail = "pass", "available without a password prompt"
+		} else {
+			c.Status, c.Detail = "pass", "available; system scope changes will ask for your password"
+		}
+	}
+	return c
+}
+
+func checkTerminal() doctorCheck {
+	c := doctorCheck{Name: "terminal"}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		c.Status, c.Detail = "warn", "not an interactive terminal; mlogin tui will not start"
+		c.Fix = "run mlogin tui from a terminal window (with ssh -t over ssh), not through a pipe"
+		return c
+	}
+	if t := os.Getenv("TERM"); t == "" || t == "dumb" {
+		c.Status, c.Detail = "warn", fmt.Sprintf("TERM=%q; the TUI may not draw correctly", t)
+		c.Fix = "export TERM=xterm-256color"
+		return c
+	}
+	w, h, _ := term.GetSize(int(os.Stdout.Fd()))
+	c.Status = "pass"
+	c.Detail = fmt.Sprintf("%dx%d, %s", w, h, colorProfileName())
+	if w < 80 || h < 20 {
+		c.Status = "warn"
+		c.Detail += "; the TUI needs at least 80x20"
+		c.Fix = "enlarge the window"
+	}
+	return c
+}
+
+func colorProfileName() string {
+	if noColor {
+		return "no color"
+	}
+	switch lipgloss.ColorProfile() {
+	case termenv.TrueColor:
+		return "true color"
+	case termenv.ANSI256:
+		return "256 colors"
+	case termenv.ANSI:
+		return "16 colors"
+	}
+	return "no color"
+}
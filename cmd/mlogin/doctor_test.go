@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseCSRUtilStatus(t *testing.T) {
+	cases := []struct {
+		out         string
+		enabled, ok bool
+	}{
+		{"System Integrity Protection status: enabled.\n", true, true},
+		{"System Integrity Protection status: disabled.\n", false, true},
+		{"System Integrity Protection status: unknown (Custom Configuration).\n", true, true},
+		{"csrutil: command not supported\n", false, false},
+	}
+	for _, c := range cases {
+		enabled, ok := parseCSRUtilStatus(c.out)
+		if enabled != c.enabled || ok != c.ok {
+			t.Fatalf("parseCSRUtilStatus(%q) = %t, %t; want %t, %t", c.out, enabled, ok, c.enabled, c.ok)
+		}
+	}
+}
+
+func TestAutomationDenied(t *testing.T) {
+	if !automationDenied("execution error: Not authorized to send Apple events to System Events. (-1743)") {
+		t.Fatal("-1743 not recognized")
+	}
+	if automationDenied("execution error: Error: ReferenceError: Can't find variable: foo (-2700)") {
+		t.Fatal("script error taken for a permission problem")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// compareLoadedConfig reports keys whose on-disk plist value differs from the
+// configuration launchd has loaded, as shown by `launchctl print`.
+func compareLoadedConfig(plist map[string]any, loaded *launchctlBlock) []string {
+	var diffs []string
+	add := func(key, disk, live string) {
+		if disk != live {
+			diffs = append(diffs, fmt.Sprintf("%s: disk %q, loaded %q", key, disk, live))
+		}
+	}
+
+	diskArgs := plistStrings(plist, "ProgramArguments")
+	if len(diskArgs) == 0 && plistString(plist, "Program") != "" {
+		diskArgs = []string{plistString(plist, "Program")}
+	}
+	liveArgs := loaded.block("arguments").items
+	if len(liveArgs) == 0 && loaded.values["program"] != "" {
+		liveArgs = []string{loaded.values["program"]}
+	}
+	add("ProgramArguments", strings.Join(diskArgs, " "), strings.Join(liveArgs, " "))
+	if p := plistString(plist, "Program"); p != "" {
+		add("Program", p, loaded.values["program"])
+	}
+
+	add("StandardOutPath", plistString(plist, "StandardOutPath"), loaded.values["stdout path"])
+	add("StandardErrorPath", plistString(plist, "StandardErrorPath"), loaded.values["stderr path"])
+	add("WorkingDirectory", plistString(plist, "WorkingDirectory"), loaded.values["working directory"])
+
+	diskInterval := ""
+	if v, ok := plist["StartInterval"].(int64); ok {
+		diskInterval = strconv.FormatInt(v, 10)
+	}
+	liveInterval := strings.TrimSuffix(loaded.values["run interval"], " seconds")
+	add("StartInterval", diskInterval, liveInterval)
+
+	diskEnv, _ := plist["EnvironmentVariables"].(map[string]any)
+	liveEnv := loaded.block("environment").values
+	keys := map[string]bool{}
+	for k := range diskEnv {
+		keys[k] = true
+	}
+	for k := range liveEnv {
+		// launchd injects its own XPC_* bookkeeping variables.
+		if !strings.HasPrefix(k, "XPC_") {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		disk, _ := diskEnv[k].(string)
+		add("EnvironmentVariables."+k, disk, liveEnv[k])
+	}
+	return diffs
+}
+
+func runBackgroundDrift(c mlogin.Client, label, scope string) error {
+	if scope == "" {
+		scope = "all"
+	}
+	var items []BackgroundItem
+	if label != "" {
+		item, err := resolveBackgroundPlist(label, scope)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	} else {
+		listed, warnings, err := listBackgroundItems(c, scope, false)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			warn(w)
+		}
+		for _, it := range withoutAppleItems(listed) {
+			if it.Loaded && it.Path != "" {
+				items = append(items, it)
+			}
+		}
+	}
+
+	drifted := 0
+	services := launchServicesFor(c, items)
+	for _, it := range items {
+		loaded, err := services.print(c, it)
+		if err != nil {
+			if label != "" {
+				return fmt.Errorf("%s is not loaded: %w", it.Label, err)
+			}
+			continue
+		}
+		plist, err := readPlist(it.Path)
+		if err != nil {
+			warn(err)
+			continue
+		}
+		diffs := compareLoadedConfig(plist, loaded)
+		if len(diffs) == 0 {
+			continue
+		}
+		drifted++
+		fmt.Printf("%s (%s)\n", it.Label, it.Path)
+		for _, d := range diffs {
+			fmt.Printf("  %s\n", d)
+		}
+	}
+	if drifted == 0 {
+		fmt.Println("No drift: loaded configuration matches the plists on disk")
+		return nil
+	}
+	fmt.Printf("\n%d item(s) differ from disk; run `mlogin background reload --label <label>` to apply.\n", drifted)
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// dryRun is the global --dry-run flag: commands that would change launchd,
+// login items, or files print what they would do instead of doing it.
+// Reading state (listing, launchctl print) still happens, so the preview
+// reflects the machine as it is.
+var dryRun bool
+
+// dryRunOutput receives the dry-run lines. The TUI swaps in a buffer so
+// they end up in its status line instead of over the screen.
+var dryRunOutput io.Writer = os.Stdout
+
+// dryRunf prints "would <what>" and reports true under --dry-run, so a
+// call site can skip the change:
+//
+//	if dryRunf("remove %s", path) {
+//		return nil
+//	}
+func dryRunf(format string, a ...any) bool {
+	if !dryRun {
+		return false
+	}
+	fmt.Fprintf(dryRunOutput, "would "+format+"\n", a...)
+	return true
+}
+
+// dryRunCommand is dryRunf for an external command.
+func dryRunCommand(args ...string) bool {
+	return dryRunf("run: %s", displayCommand(args))
+}
+
+// runOSAChange runs a JXA script that changes something, such as adding a
+// login item. Under --dry-run the script and its environment are printed
+// instead.
+func runOSAChange(c mlogin.Client, script string, env map[string]string) (string, string, error) {
+	return runTool(c, mlogin.Command{Name: "osascript", Args: []string{"-l", "JavaScript", "-e", script}, Env: env, Changes: true})
+}
+
+// dryRunner prints the commands that would change something under
+// --dry-run and passes the rest on, so listings still read the machine as
+// it is.
+type dryRunner struct {
+	next mlogin.Runner
+}
+
+func (r dryRunner) Run(ctx context.Context, c mlogin.Command) ([]byte, error) {
+	if !dryRun || !c.Changes {
+		return r.next.Run(ctx, c)
+	}
+	if c.Name == "osascript" && len(c.Args) == 4 && c.Args[2] == "-e" {
+		vars := make([]string, 0, len(c.Env))
+		for k, v := range c.Env {
+			vars = append(vars, k+"="+shellJoin([]string{v}))
+		}
+		sort.Strings(vars)
+		cmd := strings.Join(append(vars, "osascript "+strings.Join(c.Args[:2], " ")+" -"), " ")
+		dryRunf("run: %s <<'JS'\n%s\nJS", cmd, strings.Trim(c.Args[3], "\n"))
+		return nil, nil
+	}
+	dryRunCommand(append([]string{c.Name}, c.Args...)...)
+	return nil, nil
+}
+
+// dryRunBuffer collects dry-run entries from TUI actions, which run on
+// their own goroutines. Each dryRunf call is one Write.
+type dryRunBuffer struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (b *dryRunBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// take returns the first line of each collected entry and empties the
+// buffer.
+func (b *dryRunBuffer) take() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]string, 0, len(b.entries))
+	for _, e := range b.entries {
+		first, _, _ := strings.Cut(e, "\n")
+		out = append(out, first)
+	}
+	b.entries = nil
+	return out
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestRunOSAChangeDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	dryRun, dryRunOutput = true, &buf
+	defer func() { dryRun, dryRunOutput = false, os.Stdout }()
+
+	if _, _, err := runOSAChange(client(), "\nse.loginItems()\n", map[string]string{"REMOVE_PATH": "/Applications/My App.app"}); err != nil {
+		t.Fatalf("runOSAChange: %v", err)
+	}
+	want := "would run: REMOVE_PATH='/Applications/My App.app' osascript -l JavaScript - <<'JS'\nse.loginItems()\nJS\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDryRunBufferTake(t *testing.T) {
+	b := &dryRunBuffer{}
+	dryRun, dryRunOutput = true, b
+	defer func() { dryRun, dryRunOutput = false, os.Stdout }()
+
+	dryRunCommand("launchctl", "disable", "gui/501/com.example.agent")
+	dryRunf("write %s\n<plist/>", "/tmp/x.plist")
+	got := b.take()
+	if len(got) != 2 || got[0] != "would run: launchctl disable gui/501/com.example.agent" || got[1] != "would write /tmp/x.plist" {
+		t.Fatalf("got %q", got)
+	}
+	if len(b.take()) != 0 {
+		t.Fatal("take did not empty the buffer")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	emondRulesDir   = "/etc/emond.d/rules"
+	emondClientsDir = "/private/var/db/emondClients"
+	// emondSampleRules ships with macOS and contains a single disabled rule.
+	emondSampleRules = "SampleRules.plist"
+)
+
+type EmondRule struct {
+	File       string   `json:"file"`
+	Name       string   `json:"name"`
+	Enabled    bool     `json:"enabled"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Actions    []string `json:"actions,omitempty"`
+}
+
+type EmondReport struct {
+	// Clients are files in emondClientsDir; emond only runs while one exists.
+	Clients []string    `json:"clients"`
+	Rules   []EmondRule `json:"rules"`
+}
+
+func runEmond(args []string) error {
+	if len(args) == 0 {
+		return usagef("missing emond subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("emond list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		all := fs.Bool("all", false, "include the stock SampleRules.plist")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		report, warnings := scanEmond(*all)
+		for _, w := range warnings {
+			warn(w)
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(report)
+		}
+		printEmondReport(report)
+		return nil
+	default:
+		return usagef("unknown emond subcommand %q", args[0])
+	}
+}
+
+// scanEmond collects emond clients and rules. The stock sample rules are
+// left out unless all is set.
+func scanEmond(all bool) (EmondReport, []string) {
+	report := EmondReport{Clients: []string{}, Rules: []EmondRule{}}
+	var warnings []string
+	if entries, err := os.ReadDir(emondClientsDir); err == nil {
+		for _, e := range entries {
+			report.Clients = append(report.Clients, filepath.Join(emondClientsDir, e.Name()))
+		}
+	} else if !os.IsNotExist(err) {
+		warnings = append(warnings, err.Error())
+	}
+	files, _ := filepath.Glob(filepath.Join(emondRulesDir, "*"))
+	for _, f := range files {
+		if !all && filepath.Base(f) == emondSampleRules {
+			continue
+		}
+		v, err := readPlistValue(f)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		report.Rules = append(report.Rules, parseEmondRules(f, v)...)
+	}
+	return report, warnings
+}
+
+// parseEmondRules reads a rules file: an array of rule dicts, each with a
+// name, enabled flag, eventTypes, and actions such as RunCommand.
+func parseEmondRules(file string, v any) []EmondRule {
+	raw, _ := v.([]any)
+	var rules []EmondRule
+	for _, r := range raw {
+		d, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		rule := EmondRule{
+			File:       file,
+			Name:       plistString(d, "name"),
+			EventTypes: plistStrings(d, "eventTypes"),
+		}
+		rule.Enabled, _ = d["enabled"].(bool)
+		actions, _ := d["actions"].([]any)
+		for _, a := range actions {
+			ad, ok := a.(map[string]any)
+			if !ok {
+				continue
+			}
+			rule.Actions = append(rule.Actions, describeEmondAction(ad))
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func describeEmondAction(a map[string]any) string {
+	kind := plistString(a, "type")
+	switch kind {
+	case "RunCommand":
+		cmd := append([]string{plistString(a, "command")}, plistStrings(a, "arguments")...)
+		return kind + ": " + strings.Join(cmd, " ")
+	case "SendEmail", "SendSMS":
+		return kind + ": " + strings.Join(plistStrings(a, "to"), ", ")
+	default:
+		return kind
+	}
+}
+
+func printEmondReport(r EmondReport) {
+	if len(r.Clients) == 0 {
+		fmt.Println("emond clients: none (emond is not started)")
+	} else {
+		fmt.Println("emond clients:")
+		for _, c := range r.Clients {
+			fmt.Printf("  %s\n", c)
+		}
+	}
+	if len(r.Rules) == 0 {
+		fmt.Println("No non-default emond rules found")
+		return
+	}
+	for _, rule := range r.Rules {
+		state := "disabled"
+		if rule.Enabled {
+			state = "enabled"
+		}
+		fmt.Printf("%s (%s) in %s\n", orDash(rule.Name), state, rule.File)
+		if len(rule.EventTypes) > 0 {
+			fmt.Printf("  events: %s\n", strings.Join(rule.EventTypes, ", "))
+		}
+		for _, a := range rule.Actions {
+			fmt.Printf("  action: %s\n", a)
+		}
+	}
+}
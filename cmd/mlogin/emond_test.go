@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestParseEmondRules(t *testing.T) {
+	v, err := decodePlistXMLValue([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<array>
+	<dict>
+		<key>name</key>
+		<string>persist</string>
+		<key>enabled</key>
+		<true/>
+		<key>eventTypes</key>
+		<array>
+			<string>startup</string>
+		</array>
+		<key>actions</key>
+		<array>
+			<dict>
+				<key>type</key>
+				<string>RunCommand</string>
+				<key>command</key>
+				<string>/bin/sh</string>
+				<key>arguments</key>
+				<array>
+					<string>-c</string>
+					<string>/tmp/payload</string>
+				</array>
+			</dict>
+		</array>
+	</dict>
+</array>
+</plist>`))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	rules := parseEmondRules("/etc/emond.d/rules/persist.plist", v)
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+	r := rules[0]
+	if r.Name != "persist" || !r.Enabled || len(r.EventTypes) != 1 || r.EventTypes[0] != "startup" {
+		t.Fatalf("unexpected rule: %+v", r)
+	}
+	if len(r.Actions) != 1 || r.Actions[0] != "RunCommand: /bin/sh -c /tmp/payload" {
+		t.Fatalf("unexpected actions: %v", r.Actions)
+	}
+}
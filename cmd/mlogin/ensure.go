@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+)
+
+// ensureResult reports an idempotent ensure command for configuration
+// management tools: Changed is false when the state already matched.
+type ensureResult struct {
+	Target  string `json:"target"`
+	State   string `json:"state"`
+	Changed bool   `json:"changed"`
+}
+
+func printEnsureResult(r ensureResult, jsonOut bool) error {
+	if jsonOut || outputFormat != "" {
+		return writeOutput(r)
+	}
+	fmt.Printf("%s: %s\nchanged: %t\n", r.Target, r.State, r.Changed)
+	return nil
+}
+
+func runLoginEnsure(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("login ensure", flag.ContinueOnError)
+	path := fs.String("path", "", "app path")
+	state := fs.String("state", "present", "present|absent")
+	hidden := fs.Bool("hidden", false, "start hidden (when adding)")
+	jsonOut := fs.Bool("json", false, "output JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return usagef("--path is required")
+	}
+	if *state != "present" && *state != "absent" {
+		return usagef("--state must be present or absent")
+	}
+	abspath, err := filepath.Abs(*path)
+	if err != nil {
+		return err
+	}
+	items, err := listLoginItems(c)
+	if err != nil {
+		return err
+	}
+	present := false
+	for _, it := range items {
+		if filepath.Clean(it.Path) == abspath {
+			present = true
+			break
+		}
+	}
+	r := ensureResult{Target: abspath, State: *state}
+	switch {
+	case *state == "present" && !present:
+		if err := addLoginItem(c, abspath, *hidden); err != nil {
+			return err
+		}
+		r.Changed = true
+	case *state == "absent" && present:
+		if err := removeLoginItem(c, "", abspath); err != nil {
+			return err
+		}
+		r.Changed = true
+	}
+	return printEnsureResult(r, *jsonOut)
+}
+
+func runBackgroundEnsure(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("background ensure", flag.ContinueOnError)
+	label := labelFlag(fs, "label", "launchd label")
+	scope := fs.String("scope", "", "user|system (default: inferred from plist location)")
+	state := fs.String("state", "", "enabled|disabled")
+	jsonOut := fs.Bool("json", false, "output JSON")
+	applyUser := addTargetUserFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := applyUser(); err != nil {
+		return err
+	}
+	if *label == "" {
+		return usagef("--label is required")
+	}
+	if *state != "enabled" && *state != "disabled" {
+		return usagef("--state must be enabled or disabled")
+	}
+	resolved, err := inferScope(*label, *scope)
+	if err != nil {
+		return err
+	}
+	item := BackgroundItem{Label: *label, Scope: resolved}
+	if matches, err := findBackgroundPlists(*label, resolved); err == nil && len(matches) > 0 {
+		item.Path = matches[0].Path
+	}
+	if err := checkNotSIPProtected(item.Label, item.Path); err != nil {
+		return err
+	}
+	domain, err := launchDomain(c, item.Scope)
+	if err != nil {
+		return err
+	}
+	disabled, err := getDisabledLabels(c, domain)
+	if err != nil {
+		return err
+	}
+	want := *state == "disabled"
+	r := ensureResult{Target: domain + "/" + item.Label, State: *state}
+	if disabled[item.Label] != want {
+		verb := "enable"
+		if want {
+			verb = "disable"
+			if w := managedWarning(item); w != "" {
+				warn(w)
+			}
+		}
+		previous := "enabled"
+		if disabled[item.Label] {
+			previous = "disabled"
+		}
+		err := toggleService(c, verb, r.Target)
+		recordAction("background "+verb, r.Target, item.Scope, previous, err)
+		if err != nil {
+			return err
+		}
+		r.Changed = true
+	}
+	return printEnsureResult(r, *jsonOut)
+}
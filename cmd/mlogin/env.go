@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+type EnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// PlistValue is set when the loaded value differs from the plist.
+	PlistValue string `json:"plist_value,omitempty"`
+}
+
+// mergeServiceEnv combines the plist's EnvironmentVariables with the
+// environment launchd reports for the loaded service. loaded may be nil when
+// the service is not loaded.
+func mergeServiceEnv(plist map[string]any, loaded *launchctlBlock) []EnvVar {
+	vars := map[string]EnvVar{}
+	if loaded != nil {
+		for k, v := range loaded.block("default environment").values {
+			vars[k] = EnvVar{Name: k, Value: v, Source: "default"}
+		}
+		for k, v := range loaded.block("environment").values {
+			vars[k] = EnvVar{Name: k, Value: v, Source: "launchd"}
+		}
+	}
+	diskEnv, _ := plist["EnvironmentVariables"].(map[string]any)
+	for k, raw := range diskEnv {
+		v, _ := raw.(string)
+		ev, ok := vars[k]
+		if !ok || ev.Source == "default" {
+			vars[k] = EnvVar{Name: k, Value: v, Source: "plist"}
+			continue
+		}
+		ev.Source = "plist"
+		if ev.Value != v {
+			ev.PlistValue = v
+		}
+		vars[k] = ev
+	}
+
+	out := make([]EnvVar, 0, len(vars))
+	for _, ev := range vars {
+		out = append(out, ev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func runBackgroundEnv(c mlogin.Client, label, scope string, jsonOut bool) error {
+	if scope == "" {
+		scope = "all"
+	}
+	item, err := resolveBackgroundPlist(label, scope)
+	if err != nil {
+		return err
+	}
+	plist, err := readPlist(item.Path)
+	if err != nil {
+		return err
+	}
+	loaded, err := printLaunchService(c, item)
+	if err != nil {
+		warnf("%s is not loaded; showing plist environment only", label)
+		loaded = nil
+	}
+	vars := mergeServiceEnv(plist, loaded)
+
+	if jsonOut || outputFormat != "" {
+		return writeOutput(vars)
+	}
+	if len(vars) == 0 {
+		fmt.Println("No environment variables found")
+		return nil
+	}
+	fmt.Printf("%-8s %-24s %s\n", "SOURCE", "NAME", "VALUE")
+	for _, ev := range vars {
+		fmt.Printf("%-8s %-24s %s\n", ev.Source, ev.Name, ev.Value)
+		if ev.PlistValue != "" {
+			fmt.Printf("%-8s %-24s (plist has %q; reload to apply)\n", "", "", ev.PlistValue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// EnvFinding is one environment-injection indicator found by env-audit.
+type EnvFinding struct {
+	Kind    string `json:"kind"`
+	Source  string `json:"source"`
+	Label   string `json:"label,omitempty"`
+	Detail  string `json:"detail"`
+	Explain string `json:"explain"`
+}
+
+// injectionEnvVars make the dynamic linker load extra code into every
+// process that inherits them.
+var injectionEnvVars = []string{
+	"DYLD_INSERT_LIBRARIES",
+	"DYLD_LIBRARY_PATH",
+	"DYLD_FRAMEWORK_PATH",
+	"DYLD_FALLBACK_LIBRARY_PATH",
+	"DYLD_FALLBACK_FRAMEWORK_PATH",
+}
+
+// launchdConfFiles were read by launchd at boot or login before OS X 10.10
+// to run launchctl subcommands such as setenv. macOS ignores them now, but a
+// leftover file usually means something once tried to persist through it.
+var launchdConfFiles = []string{
+	"/etc/launchd.conf",
+	"/etc/launchd-user.conf",
+}
+
+func runEnvAudit(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("env-audit", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	scope := fs.String("scope", defaultScope("all"), "user|system|all")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	findings, err := auditEnvironment(c, *scope)
+	if err != nil {
+		return err
+	}
+	if *jsonOut || outputFormat != "" {
+		return writeOutput(findings)
+	}
+	printEnvFindings(findings)
+	return nil
+}
+
+// auditEnvironment looks for launchd.conf remnants, injection variables set
+// in the launchd session with `launchctl setenv`, and launchd plists that set
+// them or run `launchctl setenv` at load.
+func auditEnvironment(c mlogin.Client, scope string) ([]EnvFinding, error) {
+	if scope != "user" && scope != "system" && scope != "all" {
+		return nil, usagef("--scope must be user, system, or all")
+	}
+	var findings []EnvFinding
+	confFiles := launchdConfFiles
+	if scope == "user" {
+		confFiles = nil
+	}
+	if scope != "system" {
+		if home, err := launchUserHome(); err == nil {
+			confFiles = append(confFiles, filepath.Join(home, ".launchd.conf"))
+		}
+	}
+	for _, path := range confFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				findings = append(findings, EnvFinding{Kind: "launchd.conf", Source: path, Detail: err.Error(), Explain: "legacy launchd.conf exists but could not be read"})
+			}
+			continue
+		}
+		detail := strings.Join(strings.Fields(string(data)), " ")
+		findings = append(findings, EnvFinding{
+			Kind:    "launchd.conf",
+			Source:  path,
+			Detail:  orDash(detail),
+			Explain: "ignored since OS X 10.10; a leftover file is a remnant of (attempted) environment persistence and can be removed",
+		})
+	}
+
+	if scope != "system" {
+		for _, name := range injectionEnvVars {
+			out, err := runLaunchctlOutput(c, "getenv", name)
+			if err != nil {
+				continue
+			}
+			if value := strings.TrimSpace(out); value != "" {
+				findings = append(findings, EnvFinding{
+					Kind:    "launchctl-setenv",
+					Source:  "launchd session",
+					Detail:  name + "=" + value,
+					Explain: "set with `launchctl setenv`; every app launched in this session loads it (clear with `launchctl unsetenv " + name + "`)",
+				})
+			}
+		}
+	}
+
+	dirs, err := launchDirs(scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(d.Dir, "*.plist"))
+		for _, path := range matches {
+			plist, err := readPlist(path)
+			if err != nil {
+				continue
+			}
+			findings = append(findings, auditPlistEnv(path, plist)...)
+		}
+	}
+	return findings, nil
+}
+
+// auditPlistEnv reports a launchd plist that injects libraries through its
+// EnvironmentVariables or runs `launchctl setenv` to taint the session.
+func auditPlistEnv(path string, plist map[string]any) []EnvFinding {
+	label := plistString(plist, "Label")
+	var findings []EnvFinding
+	env, _ := plist["EnvironmentVariables"].(map[string]any)
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(injectionEnvVars, name) {
+			continue
+		}
+		value, _ := env[name].(string)
+		findings = append(findings, EnvFinding{
+			Kind:    "plist-env",
+			Source:  path,
+			Label:   label,
+			Detail:  name + "=" + value,
+			Explain: "the job's program is started with " + name + ", so the listed code is loaded into it",
+		})
+	}
+	args := plistStrings(plist, "ProgramArguments")
+	if program := plistString(plist, "Program"); program != "" {
+		args = append([]string{program}, args...)
+	}
+	cmdline := strings.Join(args, " ")
+	if strings.Contains(cmdline, "launchctl") && strings.Contains(cmdline, "setenv") {
+		findings = append(findings, EnvFinding{
+			Kind:    "plist-setenv",
+			Source:  path,
+			Label:   label,
+			Detail:  cmdline,
+			Explain: "runs `launchctl setenv` when loaded, so the variable is set for every app launched afterwards",
+		})
+	}
+	return findings
+}
+
+func printEnvFindings(findings []EnvFinding) {
+	if len(findings) == 0 {
+		fmt.Println("No environment injection indicators found")
+		return
+	}
+	for _, f := range findings {
+		source := f.Source
+		if f.Label != "" {
+			source = f.Label + " (" + f.Source + ")"
+		}
+		fmt.Printf("[%s] %s\n", f.Kind, source)
+		fmt.Printf("  %s\n", f.Detail)
+		fmt.Printf("  %s\n", f.Explain)
+	}
+}
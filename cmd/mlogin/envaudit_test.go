@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestAuditPlistEnv(t *testing.T) {
+	plist := map[string]any{
+		"Label": "com.example.inject",
+		"EnvironmentVariables": map[string]any{
+			"DYLD_INSERT_LIBRARIES": "/tmp/libhook.dylib",
+			"PATH":                  "/usr/bin:/bin",
+		},
+		"ProgramArguments": []any{"/bin/launchctl", "setenv", "DYLD_INSERT_LIBRARIES", "/tmp/libhook.dylib"},
+	}
+	findings := auditPlistEnv("/Library/LaunchAgents/com.example.inject.plist", plist)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d: %+v", len(findings), findings)
+	}
+	if findings[0].Kind != "plist-env" || findings[0].Detail != "DYLD_INSERT_LIBRARIES=/tmp/libhook.dylib" {
+		t.Fatalf("unexpected env finding: %+v", findings[0])
+	}
+	if findings[1].Kind != "plist-setenv" || findings[1].Label != "com.example.inject" {
+		t.Fatalf("unexpected setenv finding: %+v", findings[1])
+	}
+
+	clean := map[string]any{
+		"Label":                "com.example.clean",
+		"EnvironmentVariables": map[string]any{"PATH": "/usr/bin"},
+		"ProgramArguments":     []any{"/usr/local/bin/clean"},
+	}
+	if got := auditPlistEnv("/tmp/clean.plist", clean); len(got) != 0 {
+		t.Fatalf("expected no findings, got %+v", got)
+	}
+}
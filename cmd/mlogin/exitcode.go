@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// Exit codes scripts can branch on.
+const (
+	exitOK         = 0
+	exitFailure    = 1
+	exitUsage      = 2
+	exitNotFound   = 3
+	exitPermission = 4
+	exitExternal   = 5
+)
+
+// errNotFound matches errors for an item that does not exist.
+var errNotFound = errors.New("not found")
+
+type notFoundError struct {
+	msg string
+	err error
+}
+
+func (e notFoundError) Error() string        { return e.msg }
+func (e notFoundError) Is(target error) bool { return target == errNotFound }
+func (e notFoundError) Unwrap() error        { return e.err }
+
+func notFoundf(format string, a ...any) error {
+	return notFoundError{msg: fmt.Sprintf(format, a...)}
+}
+
+// notFound marks err, such as a tool's failure, as being about a missing
+// item while keeping its message and chain.
+func notFound(err error) error {
+	return notFoundError{msg: err.Error(), err: err}
+}
+
+// errUsage matches errors about how mlogin was invoked.
+var errUsage = errors.New("usage")
+
+type usageError struct{ err error }
+
+func (e usageError) Error() string        { return e.err.Error() }
+func (e usageError) Is(target error) bool { return target == errUsage }
+func (e usageError) Unwrap() error        { return e.err }
+
+func usagef(format string, a ...any) error {
+	return usageError{err: fmt.Errorf(format, a...)}
+}
+
+// parseFlags parses args into fs; a bad or unknown flag is a usage error.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usageError{err: err}
+	}
+	return nil
+}
+
+// exitCode maps err to the exit status of the process.
+func exitCode(err error) int {
+	var status exitStatusError
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &status):
+		return status.code
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, errNotFound), errors.Is(err, mlogin.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, fs.ErrPermission), isPermissionError(err):
+		return exitPermission
+	case errors.As(err, &exitErr), errors.Is(err, exec.ErrNotFound), errors.Is(err, context.DeadlineExceeded):
+		return exitExternal
+	}
+	return exitFailure
+}
+
+// ErrorOutput is what a failed command prints to stderr under --output
+// json, ndjson, or yaml instead of the "error: ..." line.
+type ErrorOutput struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	// Code names the exit status: usage, not_found, permission_denied,
+	// timeout, external, or failure.
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Hint       string `json:"hint,omitempty"`
+	ExitStatus int    `json:"exit_status"`
+}
+
+// errorDetail describes err for scripts, so they can branch on its code
+// rather than on the wording of launchctl's messages.
+func errorDetail(err error) ErrorDetail {
+	d := ErrorDetail{Message: err.Error(), ExitStatus: exitCode(err)}
+	switch d.ExitStatus {
+	case exitUsage:
+		d.Code, d.Hint = "usage", "run mlogin help, or add --help after the command"
+	case exitNotFound:
+		d.Code, d.Hint = "not_found", "mlogin background list and mlogin audit show what is installed"
+	case exitPermission:
+		d.Code, d.Hint = "permission_denied", "run with sudo; mlogin doctor checks Full Disk Access and Automation"
+		if escalation == escalateNone {
+			d.Hint = "drop --no-sudo, or run with sudo"
+		}
+	case exitExternal:
+		d.Code, d.Hint = "external", "run with --verbose to see the failing command"
+		if errors.Is(err, context.DeadlineExceeded) {
+			d.Code, d.Hint = "timeout", "raise --timeout; mlogin doctor tells whether osascript waits for an Automation prompt"
+		}
+	default:
+		d.Code = "failure"
+	}
+	return d
+}
+
+// reportError prints err to w, as an ErrorOutput when a structured
+// --output format was asked for.
+func reportError(w io.Writer, err error) {
+	switch outputFormat {
+	case "json", "ndjson", "yaml":
+		if encodeOutput(w, outputFormat, ErrorOutput{Error: errorDetail(err)}) == nil {
+			return
+		}
+	}
+	fmt.Fprintln(w, "error:", err)
+}
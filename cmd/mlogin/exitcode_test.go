@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{err: nil, code: exitOK},
+		{err: flag.ErrHelp, code: exitOK},
+		{err: usagef("missing background subcommand"), code: exitUsage},
+		{err: fmt.Errorf("background: %w", usagef("--label is required")), code: exitUsage},
+		{err: parseFlags(flag.NewFlagSet("t", flag.ContinueOnError), []string{"-x"}), code: exitUsage},
+		{err: usageError{err: flag.ErrHelp}, code: exitOK},
+		{err: errors.New("missing service in plist"), code: exitFailure},
+		{err: notFoundf("no labels match %q", "com.x.*"), code: exitNotFound},
+		{err: fmt.Errorf("read plist: %w", fs.ErrNotExist), code: exitNotFound},
+		{err: errors.New("launchctl bootout: exit status 1: Operation not permitted"), code: exitPermission},
+		{err: fmt.Errorf("launchctl: %w: boom", &exec.ExitError{}), code: exitExternal},
+		{err: exitStatusError{code: 3}, code: 3},
+		{err: errors.New("something else"), code: exitFailure},
+	}
+	for _, tc := range cases {
+		if got := exitCode(tc.err); got != tc.code {
+			t.Fatalf("exitCode(%v) = %d, want %d", tc.err, got, tc.code)
+		}
+	}
+}
+
+func TestConflictingFlagsAreUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"disable", "--label", "com.example.agent", "--vendor", "example"},
+		{"disable", "--user", "nobody", "--uid", "0", "--label", "com.example.agent"},
+	} {
+		if got := exitCode(runBackground(args)); got != exitUsage {
+			t.Fatalf("background %v: exit code %d, want %d", args, got, exitUsage)
+		}
+	}
+}
+
+func TestReportError(t *testing.T) {
+	defer func() { outputFormat = "" }()
+	var buf bytes.Buffer
+	reportError(&buf, notFoundf("no labels match %q", "com.x.*"))
+	if buf.String() != "error: no labels match \"com.x.*\"\n" {
+		t.Fatalf("unexpected text error %q", buf.String())
+	}
+	outputFormat = "json"
+	buf.Reset()
+	reportError(&buf, errors.New("launchctl bootout: exit status 1: Operation not permitted"))
+	var out ErrorOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf.String(), err)
+	}
+	if out.Error.Code != "permission_denied" || out.Error.ExitStatus != exitPermission || out.Error.Hint == "" {
+		t.Fatalf("unexpected error output %+v", out.Error)
+	}
+}
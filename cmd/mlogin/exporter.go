@@ -0,0 +1,216 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// metricSample is one Prometheus sample. Samples sharing a name must share
+// help text and are written as one family.
+type metricSample struct {
+	name   string
+	help   string
+	labels map[string]string
+	value  float64
+}
+
+func runExporter(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:9377", "address to serve /metrics on (use :9377 to let a remote Prometheus scrape it)")
+	refresh := fs.Duration("refresh", time.Minute, "reuse collected metrics for this long; signature checks are slow")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var (
+		mu        sync.Mutex
+		cached    string
+		collected time.Time
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if cached == "" || time.Since(collected) >= *refresh {
+			start := time.Now()
+			samples := collectMetrics(c)
+			samples = append(samples, metricSample{name: "mlogin_collect_duration_seconds", help: "Time spent collecting autostart state.", value: time.Since(start).Seconds()})
+			cached, collected = formatMetrics(samples), time.Now()
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, cached)
+	})
+	fmt.Fprintf(os.Stderr, "serving metrics on http://%s/metrics\n", *listen)
+	srv := &http.Server{
+		Addr:    *listen,
+		Handler: mux,
+		// As in serve: slow or idle scrapers must not tie up the server.
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	return srv.ListenAndServe()
+}
+
+// collectMetrics gathers the current state. A surface that cannot be read
+// is counted in mlogin_collect_errors instead of failing the scrape.
+func collectMetrics(c mlogin.Client) []metricSample {
+	var samples []metricSample
+	collectErrors := map[string]int{}
+
+	login, err := listLoginItems(c)
+	if err != nil {
+		collectErrors["login items"]++
+	}
+	samples = append(samples, metricSample{name: "mlogin_login_items", help: "Apps that open at login.", value: float64(len(login))})
+	unsignedLogin := 0
+	for _, it := range login {
+		if !verifyCodeSignature(it.Path).Valid {
+			unsignedLogin++
+		}
+	}
+
+	items, _, err := listBackgroundItems(c, "all", false)
+	if err != nil {
+		collectErrors["launchd"]++
+	}
+	items = withoutAppleItems(items)
+	fillSignatures(items)
+	fillRisk(items)
+	var failing []BackgroundItem
+	for _, it := range items {
+		if it.Health == "failing" {
+			failing = append(failing, it)
+		}
+	}
+	services := launchServicesFor(c, failing)
+	for i, it := range items {
+		// The list only tells failing from ok; launchctl print has the run
+		// count needed to spot a crash loop.
+		if it.Health != "failing" {
+			continue
+		}
+		if b, err := services.print(c, it); err == nil {
+			items[i].Health = assessServiceHealth(it, b).Health
+		}
+	}
+	samples = append(samples, backgroundMetrics(items)...)
+	samples = append(samples, metricSample{name: "mlogin_unsigned_programs", help: "Persistence programs whose code signature is missing or invalid.", labels: map[string]string{"surface": "login items"}, value: float64(unsignedLogin)})
+
+	extensions, err := listSystemExtensions(c)
+	if err != nil {
+		collectErrors["system extensions"]++
+	}
+	states := map[string]int{}
+	for _, e := range extensions {
+		states[e.State]++
+	}
+	for state, n := range states {
+		samples = append(samples, metricSample{name: "mlogin_system_extensions", help: "Installed system extensions by state.", labels: map[string]string{"state": state}, value: float64(n)})
+	}
+
+	for surface, n := range collectErrors {
+		samples = append(samples, metricSample{name: "mlogin_collect_errors", help: "Surfaces that could not be read during the last collection.", labels: map[string]string{"surface": surface}, value: float64(n)})
+	}
+	return samples
+}
+
+// backgroundMetrics counts launchd items by scope and kind: loaded,
+// disabled, unhealthy by health, unsigned, and suspicious.
+func backgroundMetrics(items []BackgroundItem) []metricSample {
+	type key struct{ scope, kind string }
+	type counts struct{ total, loaded, disabled, unsigned, suspicious int }
+	byKey := map[key]*counts{}
+	unhealthy := map[string]int{"failing": 0, "crash-loop": 0, "flapping": 0}
+	for _, it := range items {
+		k := key{it.Scope, it.Kind}
+		c, ok := byKey[k]
+		if !ok {
+			c = &counts{}
+			byKey[k] = c
+		}
+		c.total++
+		if it.Loaded {
+			c.loaded++
+		}
+		if it.Disabled != nil && *it.Disabled {
+			c.disabled++
+		}
+		if it.Signature != nil && !it.Signature.Valid {
+			c.unsigned++
+		}
+		if it.Risk >= suspiciousRisk {
+			c.suspicious++
+		}
+		if _, ok := unhealthy[it.Health]; ok {
+			unhealthy[it.Health]++
+		}
+	}
+
+	var samples []metricSample
+	for k, c := range byKey {
+		labels := map[string]string{"scope": k.scope, "kind": k.kind}
+		samples = append(samples,
+			metricSample{name: "mlogin_background_items", help: "launchd agents and daemons, excluding Apple's.", labels: labels, value: float64(c.total)},
+			metricSample{name: "mlogin_background_loaded", help: "launchd items currently loaded.", labels: labels, value: float64(c.loaded)},
+			metricSample{name: "mlogin_background_disabled", help: "launchd items disabled in their domain.", labels: labels, value: float64(c.disabled)},
+			metricSample{name: "mlogin_unsigned_programs", help: "Persistence programs whose code signature is missing or invalid.", labels: map[string]string{"surface": "launchd", "scope": k.scope, "kind": k.kind}, value: float64(c.unsigned)},
+			metricSample{name: "mlogin_suspicious_items", help: "launchd items whose risk score is suspicious.", labels: labels, value: float64(c.suspicious)},
+		)
+	}
+	for health, n := range unhealthy {
+		samples = append(samples, metricSample{name: "mlogin_background_unhealthy", help: "Loaded launchd items that are failing, crash-looping, or flapping.", labels: map[string]string{"health": health}, value: float64(n)})
+	}
+	return samples
+}
+
+// formatMetrics renders samples in the Prometheus text exposition format,
+// sorted so scrapes are stable.
+func formatMetrics(samples []metricSample) string {
+	type line struct{ name, text string }
+	var lines []line
+	help := map[string]string{}
+	for _, s := range samples {
+		help[s.name] = s.help
+		lines = append(lines, line{s.name, s.name + formatMetricLabels(s.labels) + " " + strconv.FormatFloat(s.value, 'g', -1, 64)})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].name != lines[j].name {
+			return lines[i].name < lines[j].name
+		}
+		return lines[i].text < lines[j].text
+	})
+	var b strings.Builder
+	for i, l := range lines {
+		if i == 0 || lines[i-1].name != l.name {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", l.name, help[l.name], l.name)
+		}
+		b.WriteString(l.text + "\n")
+	}
+	return b.String()
+}
+
+func formatMetricLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + strconv.Quote(labels[k])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBackgroundMetrics(t *testing.T) {
+	disabled := true
+	items := []BackgroundItem{
+		{Label: "com.a", Scope: "user", Kind: "agent", Loaded: true, Health: "crash-loop", Signature: &CodeSignature{Valid: false}},
+		{Label: "com.b", Scope: "user", Kind: "agent", Disabled: &disabled, Risk: suspiciousRisk},
+		{Label: "com.c", Scope: "system", Kind: "daemon", Loaded: true, Health: "running", Signature: &CodeSignature{Valid: true}},
+	}
+	out := formatMetrics(backgroundMetrics(items))
+	for _, want := range []string{
+		"# TYPE mlogin_background_items gauge\n",
+		`mlogin_background_items{kind="agent",scope="user"} 2`,
+		`mlogin_background_loaded{kind="agent",scope="user"} 1`,
+		`mlogin_background_disabled{kind="agent",scope="user"} 1`,
+		`mlogin_background_unhealthy{health="crash-loop"} 1`,
+		`mlogin_background_unhealthy{health="flapping"} 0`,
+		`mlogin_unsigned_programs{kind="agent",scope="user",surface="launchd"} 1`,
+		`mlogin_unsigned_programs{kind="daemon",scope="system",surface="launchd"} 0`,
+		`mlogin_suspicious_items{kind="agent",scope="user"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "# HELP mlogin_background_items ") != 1 {
+		t.Fatalf("expected one HELP line per metric family:\n%s", out)
+	}
+}
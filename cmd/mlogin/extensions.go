@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// extensionCategories maps the friendly --category names to
+// systemextensionsctl's category identifiers.
+var extensionCategories = map[string]string{
+	"network":           "com.apple.system_extension.network_extension",
+	"endpoint-security": "com.apple.system_extension.endpoint_security",
+	"driver":            "com.apple.system_extension.driver_extension",
+}
+
+// extensionCategory resolves a friendly or full category name.
+func extensionCategory(name string) (string, error) {
+	if full, ok := extensionCategories[strings.ToLower(name)]; ok {
+		return full, nil
+	}
+	for _, full := range extensionCategories {
+		if name == full {
+			return full, nil
+		}
+	}
+	return "", usagef("unknown category %q (want network, endpoint-security, or driver)", name)
+}
+
+func extensionsInCategory(items []SystemExtensionItem, category string) []SystemExtensionItem {
+	out := items[:0]
+	for _, it := range items {
+		if it.Category == category {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// systemExtensionsOutput returns the raw `systemextensionsctl list` output.
+func systemExtensionsOutput(c mlogin.Client) (string, error) {
+	out, err := c.Run(context.Background(), mlogin.Command{Name: "systemextensionsctl", Args: []string{"list"}})
+	return string(out), err
+}
+
+// systemExtensionsDB is where sysextd records every extension it knows
+// about, including the app that installed it.
+const systemExtensionsDB = "/Library/SystemExtensions/db.plist"
+
+// sysextRecord is the part of a db.plist extension entry mlogin uses.
+type sysextRecord struct {
+	Identifier    string
+	TeamID        string
+	State         string
+	Version       string
+	ContainerPath string
+	StagedPath    string
+}
+
+// parseSystemExtensionsDB extracts the extension entries from a decoded
+// db.plist.
+func parseSystemExtensionsDB(m map[string]any) []sysextRecord {
+	raw, _ := m["extensions"].([]any)
+	records := make([]sysextRecord, 0, len(raw))
+	for _, e := range raw {
+		d, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		r := sysextRecord{
+			Identifier: plistString(d, "identifier"),
+			TeamID:     plistString(d, "teamID"),
+			State:      plistString(d, "state"),
+		}
+		if v, ok := d["bundleVersion"].(map[string]any); ok {
+			r.Version = plistString(v, "CFBundleShortVersionString")
+		}
+		if c, ok := d["container"].(map[string]any); ok {
+			r.ContainerPath = plistString(c, "bundlePath")
+		}
+		if r.ContainerPath == "" {
+			r.ContainerPath = plistString(d, "originPath")
+		}
+		if staged, ok := d["stagedBundleURL"].(map[string]any); ok {
+			r.StagedPath = fileURLPath(plistString(staged, "relative"))
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// fileURLPath converts a file:// URL to a path, or returns "" for anything
+// else.
+func fileURLPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+func readSystemExtensionsDB() ([]sysextRecord, error) {
+	m, err := readPlist(systemExtensionsDB)
+	if err != nil {
+		return nil, err
+	}
+	return parseSystemExtensionsDB(m), nil
+}
+
+// matchSysextRecord returns the db.plist entry for ext, preferring one with
+// the same version.
+func matchSysextRecord(records []sysextRecord, ext SystemExtensionItem) (sysextRecord, bool) {
+	var found sysextRecord
+	ok := false
+	for _, r := range records {
+		if r.Identifier != ext.BundleID || (ext.TeamID != "" && r.TeamID != ext.TeamID) {
+			continue
+		}
+		if !ok || (r.Version == ext.Version && found.Version != ext.Version) {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// findSystemExtension returns the listed extension with bundleID, preferring
+// the enabled entry when older versions are still listed.
+func findSystemExtension(c mlogin.Client, bundleID string) (SystemExtensionItem, error) {
+	items, err := listSystemExtensions(c)
+	if err != nil {
+		return SystemExtensionItem{}, err
+	}
+	var found *SystemExtensionItem
+	for i := range items {
+		if items[i].BundleID != bundleID {
+			continue
+		}
+		if found == nil || (items[i].Enabled && !found.Enabled) {
+			found = &items[i]
+		}
+	}
+	if found == nil {
+		return SystemExtensionItem{}, fmt.Errorf("no system extension with bundle id %q", bundleID)
+	}
+	return *found, nil
+}
+
+// runSystemExtensionsCtl runs systemextensionsctl and folds its output into
+// the error on failure.
+func runSystemExtensionsCtl(c mlogin.Client, args ...string) (string, error) {
+	out, stderr, err := runTool(c, mlogin.Command{Name: "systemextensionsctl", Args: args})
+	if err != nil {
+		msg := strings.TrimSpace(out + "\n" + stderr)
+		if msg != "" {
+			return "", fmt.Errorf("systemextensionsctl %s: %w: %s", strings.Join(args, " "), err, msg)
+		}
+		return "", fmt.Errorf("systemextensionsctl %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+func uninstallSystemExtension(c mlogin.Client, bundleID, teamID string, yes bool) error {
+	if teamID == "" {
+		ext, err := findSystemExtension(c, bundleID)
+		if err != nil {
+			return fmt.Errorf("%w (pass --team-id to uninstall anyway)", err)
+		}
+		teamID = ext.TeamID
+	}
+	if err := confirmAction(fmt.Sprintf("Uninstall system extension %s (team %s)?", bundleID, teamID), yes); err != nil {
+		return err
+	}
+	if !dryRun {
+		fmt.Fprintln(os.Stderr, "note: macOS may only remove the extension while its hosting app is running; if it stays in \"terminated waiting to uninstall\", launch the app or reboot")
+	}
+	msg, err := c.UninstallSystemExtension(context.Background(), teamID, bundleID)
+	recordAction("extensions uninstall", bundleID, "system", "team "+teamID, err)
+	if err != nil {
+		return err
+	}
+	if msg != "" {
+		fmt.Println(msg)
+	}
+	successf("uninstall requested for %s", bundleID)
+	return nil
+}
+
+// fillHostApps sets each extension's host app from the sysextd database,
+// with the app's version and whether it is still installed. Extensions
+// whose app was deleted are the usual "terminated waiting to uninstall"
+// leftovers.
+func fillHostApps(items []SystemExtensionItem) {
+	records, err := readSystemExtensionsDB()
+	if err != nil {
+		return
+	}
+	for i := range items {
+		r, ok := matchSysextRecord(records, items[i])
+		if !ok || r.ContainerPath == "" {
+			continue
+		}
+		items[i].HostApp = r.ContainerPath
+		_, err := os.Stat(r.ContainerPath)
+		exists := err == nil
+		items[i].HostAppExists = &exists
+		if !exists {
+			continue
+		}
+		if plist, err := readPlist(filepath.Join(r.ContainerPath, "Contents", "Info.plist")); err == nil {
+			items[i].HostAppVersion = plistString(plist, "CFBundleShortVersionString")
+		}
+	}
+}
+
+// ExtensionInfo is what `extensions info` reports for one extension.
+type ExtensionInfo struct {
+	SystemExtensionItem
+	StagedPath        string `json:"staged_path,omitempty"`
+	DBState           string `json:"db_state,omitempty"`
+	SigningIdentifier string `json:"signing_identifier,omitempty"`
+	SigningAuthority  string `json:"signing_authority,omitempty"`
+}
+
+func runExtensionsInfo(c mlogin.Client, bundleID string, jsonOut bool) error {
+	ext, err := findSystemExtension(c, bundleID)
+	if err != nil {
+		return err
+	}
+	info := ExtensionInfo{SystemExtensionItem: ext}
+	records, err := readSystemExtensionsDB()
+	if err != nil {
+		warn(err)
+	}
+	if r, ok := matchSysextRecord(records, ext); ok {
+		info.StagedPath = r.StagedPath
+		info.DBState = r.State
+	}
+	signed := info.StagedPath
+	if signed == "" {
+		signed = info.HostApp
+	}
+	if signed != "" {
+		if sig, err := codesignInfo(signed); err == nil {
+			info.SigningIdentifier = sig["Identifier"]
+			info.SigningAuthority = sig["Authority"]
+		}
+	}
+
+	if jsonOut || outputFormat != "" {
+		return writeOutput(info)
+	}
+	fmt.Printf("Bundle ID:  %s\n", info.BundleID)
+	fmt.Printf("Name:       %s\n", info.Name)
+	fmt.Printf("Version:    %s\n", orDash(info.Version))
+	fmt.Printf("Team ID:    %s\n", orDash(info.TeamID))
+	fmt.Printf("Category:   %s\n", info.Category)
+	fmt.Printf("State:      %s (enabled %t, active %t)\n", info.State, info.Enabled, info.Active)
+	app := orDash(info.HostApp)
+	if info.HostAppExists != nil && !*info.HostAppExists {
+		app += " (missing)"
+	} else if info.HostAppVersion != "" {
+		app += " (" + info.HostAppVersion + ")"
+	}
+	fmt.Printf("App:        %s\n", app)
+	fmt.Printf("Staged at:  %s\n", orDash(info.StagedPath))
+	fmt.Printf("Signed by:  %s\n", orDash(info.SigningAuthority))
+	return nil
+}
+
+// setExtensionsDeveloperMode wraps `systemextensionsctl developer`. Without
+// on/off it prints the current setting. Turning it on requires SIP to be
+// disabled.
+func setExtensionsDeveloperMode(c mlogin.Client, mode string) error {
+	args := []string{"developer"}
+	switch mode {
+	case "status":
+	case "on", "off":
+		args = append(args, mode)
+	default:
+		return fmt.Errorf("developer-mode takes on, off, or status, not %q", mode)
+	}
+	if mode != "status" && dryRunCommand(append([]string{"systemextensionsctl"}, args...)...) {
+		return nil
+	}
+	out, err := runSystemExtensionsCtl(c, args...)
+	if err != nil {
+		return err
+	}
+	fmt.Println(strings.TrimSpace(out))
+	return nil
+}
+
+// resetSystemExtensions wraps `systemextensionsctl reset`, which uninstalls
+// every third-party system extension on the machine. Unless force is set the
+// user has to confirm.
+func resetSystemExtensions(c mlogin.Client, force bool) error {
+	warn("this uninstalls ALL third-party system extensions (network filters, VPNs, endpoint security agents, drivers) on this Mac")
+	if err := confirmReset("Reset all system extensions?", force); err != nil {
+		return err
+	}
+	if dryRunCommand("systemextensionsctl", "reset") {
+		return nil
+	}
+	out, err := runSystemExtensionsCtl(c, "reset")
+	recordAction("extensions reset", "all third-party system extensions", "system", "", err)
+	if err != nil {
+		return err
+	}
+	if msg := strings.TrimSpace(out); msg != "" {
+		fmt.Println(msg)
+	}
+	return nil
+}
+
+// securitySettingsURL opens System Settings at Privacy & Security, where
+// macOS asks the user to allow newly activated system extensions.
+const securitySettingsURL = "x-apple.systempreferences:com.apple.preference.security?Security"
+
+func isWaitingForUser(state string) bool {
+	return strings.Contains(strings.ReplaceAll(state, "_", " "), "waiting for user")
+}
+
+// approveSystemExtension explains how to approve an extension waiting for
+// the user and opens the Privacy & Security pane. Approval itself can only
+// be given by the user (or an MDM profile).
+func approveSystemExtension(c mlogin.Client, bundleID string) error {
+	ext, err := findSystemExtension(c, bundleID)
+	if err != nil {
+		return err
+	}
+	if !isWaitingForUser(ext.State) {
+		fmt.Printf("%s is not waiting for approval (state: %s)\n", ext.BundleID, orDash(ext.State))
+		return nil
+	}
+	name := ext.Name
+	if name == "" {
+		name = ext.BundleID
+	}
+	fmt.Printf("%s (%s) is waiting for you to allow it:\n", name, ext.BundleID)
+	fmt.Println("  1. In System Settings > Privacy & Security, scroll to Security.")
+	fmt.Printf("  2. Click Allow (or Details...) next to the message about system software from %s.\n", orDash(ext.TeamID))
+	fmt.Println("  3. Authenticate with an administrator password.")
+	if ext.Category == extensionCategories["endpoint-security"] {
+		fmt.Println("  Endpoint security extensions also need Full Disk Access in Privacy & Security > Full Disk Access.")
+	}
+	if ext.Category == extensionCategories["network"] {
+		fmt.Println("  Network extensions may show a second prompt to allow the VPN or content filter configuration.")
+	}
+	return openWith(securitySettingsURL)
+}
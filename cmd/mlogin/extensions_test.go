@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestParseSystemExtensionsDB(t *testing.T) {
+	db := map[string]any{
+		"extensions": []any{
+			map[string]any{
+				"identifier":      "com.example.app.net",
+				"teamID":          "ABCDE12345",
+				"state":           "activated_enabled",
+				"bundleVersion":   map[string]any{"CFBundleShortVersionString": "2.0"},
+				"container":       map[string]any{"bundlePath": "/Applications/Example.app"},
+				"stagedBundleURL": map[string]any{"relative": "file:///Library/SystemExtensions/1234/com.example.app.net.systemextension/"},
+			},
+			map[string]any{
+				"identifier":    "com.example.app.net",
+				"teamID":        "ABCDE12345",
+				"state":         "terminated_waiting_to_uninstall_on_reboot",
+				"bundleVersion": map[string]any{"CFBundleShortVersionString": "1.0"},
+				"originPath":    "/Applications/Example.app",
+			},
+		},
+	}
+	records := parseSystemExtensionsDB(db)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].StagedPath != "/Library/SystemExtensions/1234/com.example.app.net.systemextension" {
+		t.Fatalf("unexpected staged path: %q", records[0].StagedPath)
+	}
+	if records[1].ContainerPath != "/Applications/Example.app" {
+		t.Fatalf("expected originPath fallback, got %q", records[1].ContainerPath)
+	}
+
+	r, ok := matchSysextRecord(records, SystemExtensionItem{BundleID: "com.example.app.net", TeamID: "ABCDE12345", Version: "1.0"})
+	if !ok || r.State != "terminated_waiting_to_uninstall_on_reboot" {
+		t.Fatalf("expected version 1.0 record, got %+v", r)
+	}
+}
+
+func TestExtensionCategory(t *testing.T) {
+	if got, err := extensionCategory("Network"); err != nil || got != "com.apple.system_extension.network_extension" {
+		t.Fatalf("extensionCategory(Network) = %q, %v", got, err)
+	}
+	if got, err := extensionCategory("com.apple.system_extension.driver_extension"); err != nil || got != "com.apple.system_extension.driver_extension" {
+		t.Fatalf("full identifier not accepted: %q, %v", got, err)
+	}
+	if _, err := extensionCategory("kext"); err == nil {
+		t.Fatalf("expected unknown category error")
+	}
+}
+
+func TestIsWaitingForUser(t *testing.T) {
+	for _, state := range []string{"activated waiting for user", "activated_waiting_for_user"} {
+		if !isWaitingForUser(state) {
+			t.Fatalf("expected %q to be waiting for user", state)
+		}
+	}
+	if isWaitingForUser("activated enabled") {
+		t.Fatalf("expected enabled extension not to be waiting")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FleetResult is one host's output. Result is the remote command's JSON
+// output, kept as is.
+type FleetResult struct {
+	Host   string          `json:"host"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func runFleet(args []string) error {
+	fs := flag.NewFlagSet("fleet", flag.ContinueOnError)
+	hostsFile := fs.String("hosts", "", "file with one SSH destination per line (# comments allowed)")
+	concurrency := fs.Int("concurrency", 8, "hosts to query at once")
+	format := fs.String("format", "json", "json|csv")
+	timeout := fs.Duration("timeout", 2*time.Minute, "give up on a host after this long")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *hostsFile == "" {
+		return usagef("--hosts is required")
+	}
+	if fs.NArg() == 0 {
+		return usagef("usage: mlogin fleet --hosts hosts.txt <command> [args...]")
+	}
+	if *format != "json" && *format != "csv" {
+		return usagef("--format must be json or csv")
+	}
+	if *concurrency < 1 {
+		return usagef("--concurrency must be at least 1")
+	}
+	hosts, err := readFleetHosts(*hostsFile)
+	if err != nil {
+		return err
+	}
+	command := fs.Args()
+	if !slices.Contains(command, "--json") {
+		command = append(command, "--json")
+	}
+
+	results := queryFleet(hosts, command, *concurrency, *timeout)
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			warnf("%s: %s", r.Host, r.Error)
+		}
+	}
+	if *format == "csv" {
+		if err := writeFleetCSV(os.Stdout, results); err != nil {
+			return err
+		}
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	}
+	if failed == len(results) {
+		return fmt.Errorf("all %d host(s) failed", failed)
+	}
+	return nil
+}
+
+func readFleetHosts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var hosts []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			if err := checkSSHHost(line); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			hosts = append(hosts, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("%s lists no hosts", path)
+	}
+	return hosts, nil
+}
+
+// queryFleet runs command on every host, at most concurrency at a time,
+// and returns the results in host order.
+func queryFleet(hosts, command []string, concurrency int, timeout time.Duration) []FleetResult {
+	results := make([]FleetResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = queryFleetHost(host, command, timeout)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func queryFleetHost(host string, command []string, timeout time.Duration) FleetResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// BatchMode fails instead of prompting for a password: there is no
+	// terminal to answer for dozens of hosts at once.
+	sshArgs := append([]string{"-o", "BatchMode=yes"}, remoteCommand(host, remoteMlogin, command, false)...)
+	cmd := exec.CommandContext(ctx, "ssh", sshArgs...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	r := FleetResult{Host: host}
+	out := bytes.TrimSpace(stdout.Bytes())
+	if json.Valid(out) && len(out) > 0 {
+		r.Result = out
+	}
+	switch {
+	case ctx.Err() != nil:
+		r.Error = "timed out after " + timeout.String()
+	case err != nil:
+		r.Error = strings.TrimSpace(err.Error() + ": " + lastLine(stderr.String()))
+	case r.Result == nil:
+		r.Error = "remote mlogin printed no JSON"
+	}
+	return r
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// fleetRows flattens each host's result into CSV rows, with the host in
+// the first column; see tableRecords.
+func fleetRows(results []FleetResult) ([]string, [][]string) {
+	var records []jsonObject
+	for _, r := range results {
+		if r.Error != "" && r.Result == nil {
+			var rec jsonObject
+			rec.set("host", r.Host)
+			rec.set("error", r.Error)
+			records = append(records, rec)
+			continue
+		}
+		flat, _ := tableRecords(r.Result)
+		for _, f := range flat {
+			var rec jsonObject
+			rec.set("host", r.Host)
+			for _, k := range f.keys {
+				rec.set(k, f.values[k])
+			}
+			records = append(records, rec)
+		}
+	}
+	return tabulate(records)
+}
+
+func writeFleetCSV(w io.Writer, results []FleetResult) error {
+	columns, rows := fleetRows(results)
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
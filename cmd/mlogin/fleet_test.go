@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFleetHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	if err := os.WriteFile(path, []byte("# lab\nadmin@mac1\n\n  mac2.local  # spare\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := readFleetHosts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(hosts, ",") != "admin@mac1,mac2.local" {
+		t.Fatalf("unexpected hosts: %v", hosts)
+	}
+
+	if err := os.WriteFile(path, []byte("admin@mac1\n-oProxyCommand=touch /tmp/pwned\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFleetHosts(path); err == nil || !strings.Contains(err.Error(), "hosts.txt:2") {
+		t.Fatalf("expected an option-like host to be rejected, got %v", err)
+	}
+	if err := runRemote("-oProxyCommand=touch /tmp/pwned", []string{"background", "list"}); err == nil {
+		t.Fatalf("expected --host to reject an option-like host")
+	}
+}
+
+func TestWriteFleetCSV(t *testing.T) {
+	audit := []AuditSurface{{Surface: "cron", Entries: []AuditEntry{
+		{Name: "job1", Risk: 1},
+		{Name: "job2", RiskReasons: []string{"runs from /tmp"}},
+	}}}
+	data, err := json.Marshal(audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []FleetResult{
+		{Host: "mac1", Result: data},
+		{Host: "mac2", Error: "ssh: connect to host mac2 port 22: Connection refused"},
+	}
+	var b strings.Builder
+	if err := writeFleetCSV(&b, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 rows, got:\n%s", b.String())
+	}
+	if lines[0] != "host,surface,entries.name,entries.risk,entries.risk_reasons,error" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "mac1,cron,job1,1,") {
+		t.Fatalf("unexpected first row %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `"[""runs from /tmp""]"`) {
+		t.Fatalf("expected the reasons list as JSON, got %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "mac2,") || !strings.Contains(lines[3], "Connection refused") {
+		t.Fatalf("unexpected error row %q", lines[3])
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+type FolderAction struct {
+	Folder  string               `json:"folder"`
+	Enabled bool                 `json:"enabled"`
+	Scripts []FolderActionScript `json:"scripts"`
+}
+
+type FolderActionScript struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Enabled bool   `json:"enabled"`
+}
+
+// FolderActionsReport carries the global Folder Actions switch: attached
+// scripts only run while it is on.
+type FolderActionsReport struct {
+	Enabled bool           `json:"enabled"`
+	Actions []FolderAction `json:"actions"`
+}
+
+func runFolderActions(args []string) error {
+	c := client()
+	if len(args) == 0 {
+		return usagef("missing folderactions subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("folderactions list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		report, err := listFolderActions(c)
+		if err != nil {
+			return err
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(report)
+		}
+		printFolderActions(report)
+		return nil
+	case "remove":
+		fs := flag.NewFlagSet("folderactions remove", flag.ContinueOnError)
+		folder := fs.String("folder", "", "folder the action is attached to")
+		script := fs.String("script", "", "only detach this script (default: the whole folder action)")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *folder == "" {
+			return usagef("--folder is required")
+		}
+		return removeFolderAction(c, *folder, *script)
+	default:
+		return usagef("unknown folderactions subcommand %q", args[0])
+	}
+}
+
+func listFolderActions(c mlogin.Client) (FolderActionsReport, error) {
+	script := `
+ObjC.import('Cocoa');
+const se = Application('System Events');
+const out = {
+  enabled: se.folderActionsEnabled(),
+  actions: se.folderActions().map((fa) => ({
+    folder: fa.path(),
+    enabled: fa.enabled(),
+    scripts: fa.scripts().map((s) => ({ name: s.name(), path: s.path(), enabled: s.enabled() }))
+  }))
+};
+$.NSFileHandle.fileHandleWithStandardOutput.writeData($(JSON.stringify(out) + "\n").dataUsingEncoding($.NSUTF8StringEncoding));
+`
+	stdout, stderr, err := runOSA(c, script, nil)
+	if err != nil {
+		return FolderActionsReport{}, fmt.Errorf("osascript folder actions list failed: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	var report FolderActionsReport
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout)), &report); err != nil {
+		return FolderActionsReport{}, fmt.Errorf("parse folder actions: %w", err)
+	}
+	return report, nil
+}
+
+func removeFolderAction(c mlogin.Client, folder, scriptName string) error {
+	script := `
+const se = Application('System Events');
+const folder = $.getenv('FA_FOLDER');
+const scriptName = $.getenv('FA_SCRIPT');
+let removed = 0;
+for (const fa of se.folderActions()) {
+  if (fa.path() !== folder) {
+    continue;
+  }
+  if (scriptName) {
+    for (const s of fa.scripts()) {
+      if (s.name() === scriptName) {
+        s.delete();
+        removed += 1;
+      }
+    }
+  } else {
+    fa.delete();
+    removed += 1;
+  }
+}
+if (removed === 0) {
+  throw new Error('no matching folder action found');
+}
+`
+	abspath, err := filepath.Abs(folder)
+	if err != nil {
+		return err
+	}
+	// runOSA appends to the inherited environment, so FA_SCRIPT must always
+	// be set to avoid picking up a stray value.
+	env := map[string]string{"FA_FOLDER": abspath, "FA_SCRIPT": scriptName}
+	_, stderr, err := runOSAChange(c, script, env)
+	if err != nil {
+		err = fmt.Errorf("remove folder action failed: %w: %s", err, strings.TrimSpace(stderr))
+		if strings.Contains(stderr, "no matching folder action") {
+			err = notFound(err)
+		}
+	}
+	target := abspath
+	if scriptName != "" {
+		target += " (" + scriptName + ")"
+	}
+	recordAction("folderactions remove", target, "", "", err)
+	if err != nil {
+		return err
+	}
+	successf("removed matching folder actions")
+	return nil
+}
+
+func printFolderActions(r FolderActionsReport) {
+	state := "off"
+	if r.Enabled {
+		state = "on"
+	}
+	fmt.Printf("Folder Actions: %s\n", state)
+	if len(r.Actions) == 0 {
+		fmt.Println("No folder actions found")
+		return
+	}
+	for _, fa := range r.Actions {
+		fmt.Printf("%s (enabled %t)\n", fa.Folder, fa.Enabled)
+		for _, s := range fa.Scripts {
+			fmt.Printf("  %s (enabled %t)\n", s.Path, s.Enabled)
+		}
+	}
+}
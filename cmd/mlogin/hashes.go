@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// programSHA256 returns the hex SHA-256 of program. For a bundle (.app,
+// .kext, ...) the main executable named by its Info.plist is hashed.
+func programSHA256(program string) (string, error) {
+	path, err := bundleExecutable(program)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// bundleExecutable resolves a bundle directory to its main executable and
+// returns any other path unchanged.
+func bundleExecutable(path string) (string, error) {
+	st, err := os.Stat(path)
+	if err != nil || !st.IsDir() {
+		return path, err
+	}
+	plist, err := readPlist(filepath.Join(path, "Contents", "Info.plist"))
+	if err != nil {
+		return "", err
+	}
+	name := plistString(plist, "CFBundleExecutable")
+	if name == "" {
+		name = filepath.Base(path[:len(path)-len(filepath.Ext(path))])
+	}
+	return filepath.Join(path, "Contents", "MacOS", name), nil
+}
+
+// fillHashes hashes the program of each item that has a plist.
+func fillHashes(items []BackgroundItem) {
+	for i := range items {
+		if items[i].Path == "" || items[i].Kind == "startup" {
+			continue
+		}
+		plist, err := readPlist(items[i].Path)
+		if err != nil {
+			continue
+		}
+		if program := plistProgram(plist); program != "" {
+			items[i].SHA256, _ = programSHA256(program)
+		}
+	}
+}
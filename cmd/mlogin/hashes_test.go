@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgramSHA256(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "agent")
+	if err := os.WriteFile(bin, []byte("hello\n"), 0o755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	const want = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	got, err := programSHA256(bin)
+	if err != nil || got != want {
+		t.Fatalf("programSHA256 = %q, %v; want %q", got, err, want)
+	}
+
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+type ServiceHealth struct {
+	Label        string `json:"label"`
+	Scope        string `json:"scope"`
+	State        string `json:"state"`
+	Runs         int    `json:"runs"`
+	LastExitCode string `json:"last_exit_code,omitempty"`
+	LastSignal   string `json:"last_signal,omitempty"`
+	Health       string `json:"health"`
+}
+
+// crashLoopRuns is the number of launches after which a service that keeps
+// exiting abnormally is considered crash-looping rather than merely failed.
+const crashLoopRuns = 3
+
+// assessServiceHealth classifies a service from its `launchctl print` block.
+func assessServiceHealth(item BackgroundItem, b *launchctlBlock) ServiceHealth {
+	h := ServiceHealth{
+		Label:        item.Label,
+		Scope:        item.Scope,
+		State:        b.values["state"],
+		LastExitCode: b.values["last exit code"],
+		LastSignal:   b.values["last terminating signal"],
+	}
+	h.Runs, _ = strconv.Atoi(b.values["runs"])
+
+	abnormal := h.LastSignal != "" || (h.LastExitCode != "" && h.LastExitCode != "0" && h.LastExitCode != "(never exited)")
+	switch {
+	case h.State == "running":
+		h.Health = "running"
+		if abnormal && h.Runs >= crashLoopRuns {
+			// Currently up, but has been respawned repeatedly after failures.
+			h.Health = "flapping"
+		}
+	case abnormal && (h.Runs >= crashLoopRuns || h.State == "spawn scheduled"):
+		h.Health = "crash-loop"
+	case abnormal:
+		h.Health = "failing"
+	default:
+		h.Health = "ok"
+	}
+	return h
+}
+
+func runBackgroundHealth(c mlogin.Client, label, scope string, all, jsonOut bool) error {
+	var items []BackgroundItem
+	if label != "" {
+		resolved, err := inferScope(label, scope)
+		if err != nil {
+			return err
+		}
+		item := BackgroundItem{Label: label, Scope: resolved, Kind: "registered"}
+		if matches, err := findBackgroundPlists(label, resolved); err == nil && len(matches) > 0 {
+			item = matches[0]
+		}
+		items = append(items, item)
+	} else {
+		if scope == "" {
+			scope = "all"
+		}
+		listed, warnings, err := listBackgroundItems(c, scope, false)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			warn(w)
+		}
+		for _, it := range withoutAppleItems(listed) {
+			if it.Loaded {
+				items = append(items, it)
+			}
+		}
+	}
+
+	var report []ServiceHealth
+	services := launchServicesFor(c, items)
+	for _, it := range items {
+		b, err := services.print(c, it)
+		if err != nil {
+			if label != "" {
+				return fmt.Errorf("%s is not loaded: %w", label, err)
+			}
+			continue
+		}
+		h := assessServiceHealth(it, b)
+		if all || label != "" || (h.Health != "ok" && h.Health != "running") {
+			report = append(report, h)
+		}
+	}
+
+	if jsonOut || outputFormat != "" {
+		return writeOutput(report)
+	}
+	if len(report) == 0 {
+		fmt.Println("No unhealthy background items found")
+		return nil
+	}
+	fmt.Printf("%-10s %-8s %-16s %-5s %-10s %s\n", "HEALTH", "SCOPE", "STATE", "RUNS", "LAST EXIT", "LABEL")
+	for _, h := range report {
+		exit := h.LastExitCode
+		if h.LastSignal != "" {
+			exit = h.LastSignal
+		}
+		if exit == "" {
+			exit = "-"
+		}
+		fmt.Printf("%-10s %-8s %-16s %-5d %-10s %s\n", h.Health, h.Scope, h.State, h.Runs, exit, h.Label)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// privilegedHelperDir is where SMJobBless installs privileged helpers; each
+// is started by a LaunchDaemon whose Program points at the helper.
+const privilegedHelperDir = "/Library/PrivilegedHelperTools"
+
+type PrivilegedHelper struct {
+	Path      string `json:"path"`
+	Label     string `json:"label,omitempty"`
+	Plist     string `json:"plist,omitempty"`
+	Loaded    bool   `json:"loaded"`
+	TeamID    string `json:"team_id,omitempty"`
+	Authority string `json:"authority,omitempty"`
+	// Orphaned is set when no LaunchDaemon references the helper anymore.
+	Orphaned bool `json:"orphaned,omitempty"`
+}
+
+func runHelpers(args []string) error {
+	c := client()
+	if len(args) == 0 {
+		return usagef("missing helpers subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("helpers list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		helpers, err := listPrivilegedHelpers(c)
+		if err != nil {
+			return err
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(helpers)
+		}
+		printPrivilegedHelpers(helpers)
+		return nil
+	case "remove":
+		fs := flag.NewFlagSet("helpers remove", flag.ContinueOnError)
+		path := fs.String("path", "", "helper binary path")
+		label := labelFlag(fs, "label", "label of the helper's LaunchDaemon")
+		yes := fs.Bool("yes", false, "remove without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *path == "" && *label == "" {
+			return usagef("provide --path or --label")
+		}
+		return removePrivilegedHelper(c, *path, *label, *yes)
+	default:
+		return usagef("unknown helpers subcommand %q", args[0])
+	}
+}
+
+// listPrivilegedHelpers returns each helper binary together with the
+// LaunchDaemon that runs it, matched by the daemon's Program.
+func listPrivilegedHelpers(c mlogin.Client) ([]PrivilegedHelper, error) {
+	entries, err := os.ReadDir(privilegedHelperDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	daemons := helperDaemons()
+	loaded, _ := getLoadedDomainLabels(c, "system")
+
+	var helpers []PrivilegedHelper
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		h := PrivilegedHelper{Path: filepath.Join(privilegedHelperDir, e.Name())}
+		if d, ok := daemons[h.Path]; ok {
+			h.Label, h.Plist = d.Label, d.Path
+			_, h.Loaded = loaded[d.Label]
+		} else {
+			h.Orphaned = true
+		}
+		if info, err := codesignInfo(h.Path); err == nil {
+			h.TeamID = info["TeamIdentifier"]
+			if h.TeamID == "not set" {
+				h.TeamID = ""
+			}
+			h.Authority = info["Authority"]
+		}
+		helpers = append(helpers, h)
+	}
+	return helpers, nil
+}
+
+// helperDaemons maps the programs of system LaunchDaemons to their items.
+func helperDaemons() map[string]BackgroundItem {
+	daemons := map[string]BackgroundItem{}
+	matches, _ := filepath.Glob("/Library/LaunchDaemons/*.plist")
+	for _, p := range matches {
+		plist, err := readPlist(p)
+		if err != nil {
+			continue
+		}
+		program := plistProgram(plist)
+		if filepath.Dir(program) != privilegedHelperDir {
+			continue
+		}
+		daemons[program] = BackgroundItem{Label: plistString(plist, "Label"), Path: p, Scope: "system", Kind: "daemon"}
+	}
+	return daemons
+}
+
+// removePrivilegedHelper boots out the helper's daemon, moves its plist to
+// the mlogin trash, and deletes the helper binary.
+func removePrivilegedHelper(c mlogin.Client, path, label string, yes bool) error {
+	helpers, err := listPrivilegedHelpers(c)
+	if err != nil {
+		return err
+	}
+	var target *PrivilegedHelper
+	for i := range helpers {
+		if (path != "" && helpers[i].Path == path) || (label != "" && helpers[i].Label == label) {
+			target = &helpers[i]
+			break
+		}
+	}
+	if target == nil {
+		return notFoundf("no matching privileged helper found")
+	}
+	fmt.Printf("helper: %s\n", target.Path)
+	if target.Plist != "" {
+		fmt.Printf("daemon: %s (%s)\n", target.Label, target.Plist)
+	}
+	if err := confirmAction("Remove the helper and its daemon?", yes); err != nil {
+		return err
+	}
+	if target.Plist != "" {
+		if err := deleteBackgroundItem(c, target.Label, target.Plist, "system", false); err != nil {
+			return err
+		}
+	}
+	if dryRunf("remove %s", target.Path) {
+		return nil
+	}
+	if err := os.Remove(target.Path); err != nil && !os.IsNotExist(err) {
+		err = fmt.Errorf("remove helper %s: %w", target.Path, err)
+		recordAction("helpers remove", target.Path, "system", "", err)
+		return err
+	}
+	recordAction("helpers remove", target.Path, "system", "", nil)
+	successf("removed helper %s", target.Path)
+	return nil
+}
+
+func printPrivilegedHelpers(helpers []PrivilegedHelper) {
+	if len(helpers) == 0 {
+		fmt.Println("No privileged helpers found")
+		return
+	}
+	fmt.Printf("%-7s %-10s %s\n", "LOADED", "TEAMID", "HELPER")
+	for _, h := range helpers {
+		fmt.Printf("%-7t %-10s %s\n", h.Loaded, orDash(h.TeamID), h.Path)
+		if h.Orphaned {
+			fmt.Println("  (orphaned: no LaunchDaemon runs this helper)")
+			continue
+		}
+		fmt.Printf("  %s -> %s\n", h.Label, h.Plist)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// historyFile is the snapshot inside the history repository; every
+// `snapshot --commit` overwrites it so git records each change.
+const historyFile = "state.json"
+
+// historyDir is the git repository holding committed snapshots.
+func historyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "mlogin", "history"), nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// commitSnapshot writes snap into the history repository and commits it with
+// the changes since the previous snapshot in the message. Nothing is
+// committed when nothing changed.
+func commitSnapshot(snap Snapshot) error {
+	dir, err := historyDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+		if _, err := runGit(dir, "init", "--quiet"); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(dir, historyFile)
+	var changes []SnapshotChange
+	first := true
+	if prev, err := readSnapshot(path); err == nil {
+		first = false
+		changes = diffSnapshots(prev, snap)
+		if len(changes) == 0 {
+			fmt.Println("No changes since the last snapshot")
+			return nil
+		}
+	}
+	if err := writeSnapshot(path, snap); err != nil {
+		return err
+	}
+	msg := historyCommitMessage(snap, changes, first)
+	if _, err := runGit(dir, "add", historyFile); err != nil {
+		return err
+	}
+	host := orDash(snap.Hostname)
+	if _, err := runGit(dir, "-c", "user.name=mlogin", "-c", "user.email=mlogin@"+host, "commit", "--quiet", "-m", msg); err != nil {
+		return err
+	}
+	subject, _, _ := strings.Cut(msg, "\n")
+	successf("committed %s to %s", subject, dir)
+	return nil
+}
+
+// historyCommitMessage summarizes a snapshot commit: a subject with the
+// change count and one body line per change.
+func historyCommitMessage(snap Snapshot, changes []SnapshotChange, first bool) string {
+	stamp := snap.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
+	if first {
+		return "snapshot " + stamp + ": initial state"
+	}
+	lines := []string{fmt.Sprintf("snapshot %s: %d change(s)", stamp, len(changes)), ""}
+	marks := map[string]string{"added": "+", "removed": "-", "changed": "~"}
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("%s [%s] %s", marks[c.Change], c.Surface, c.Name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runLog(args []string) error {
+	fs := flag.NewFlagSet("log", flag.ContinueOnError)
+	n := fs.Int("n", 20, "number of snapshots to show (0 for all)")
+	short := fs.Bool("short", false, "only show one line per snapshot")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	dir, err := historyDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		return errors.New("no snapshot history yet; run mlogin snapshot --commit")
+	}
+	format := "--format=%h %s%n%b"
+	if *short {
+		format = "--format=%h %s"
+	}
+	gitArgs := []string{"log", format}
+	if *n > 0 {
+		gitArgs = append(gitArgs, "-n", strconv.Itoa(*n))
+	}
+	out, err := runGit(dir, gitArgs...)
+	if err != nil {
+		return err
+	}
+	fmt.Print(strings.TrimRight(out, "\n") + "\n")
+	return nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryCommitMessage(t *testing.T) {
+	snap := Snapshot{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	if got := historyCommitMessage(snap, nil, true); got != "snapshot 2024-05-01T12:00:00Z: initial state" {
+		t.Fatalf("unexpected initial message: %q", got)
+	}
+	changes := []SnapshotChange{
+		{Surface: "launchd", Name: "com.example.agent (user)", Change: "added"},
+		{Surface: "login items", Name: "Slack", Change: "removed"},
+	}
+	want := "snapshot 2024-05-01T12:00:00Z: 2 change(s)\n\n+ [launchd] com.example.agent (user)\n- [login items] Slack"
+	if got := historyCommitMessage(snap, changes, false); got != want {
+		t.Fatalf("message = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// runBrewServices delegates enable/disable of formula to `brew services
+// start/stop` so Homebrew's own bookkeeping stays in sync with launchd.
+func runBrewServices(c mlogin.Client, verb, formula string) error {
+	action := "start"
+	if verb == "disable" {
+		action = "stop"
+	}
+	out, err := c.Run(context.Background(), mlogin.Command{Name: "brew", Args: []string{"services", action, formula}, Changes: true})
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		fmt.Println(msg)
+	}
+	recordAction("brew services "+action, formula, "", "", err)
+	return err
+}
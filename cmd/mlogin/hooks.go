@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type LoginHook struct {
+	Kind   string `json:"kind"`
+	Domain string `json:"domain"`
+	Script string `json:"script"`
+	// Missing is set when the hook points at a script that no longer exists.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// hookDomains are the com.apple.loginwindow preference domains loginwindow
+// reads hooks from. `sudo defaults write com.apple.loginwindow LoginHook`
+// lands in root's domain, which is the classic way hooks get installed.
+var hookDomains = []struct {
+	name   string
+	domain string
+}{
+	{"user", "com.apple.loginwindow"},
+	{"system", "/Library/Preferences/com.apple.loginwindow"},
+	{"root", "/var/root/Library/Preferences/com.apple.loginwindow"},
+}
+
+var hookKeys = map[string]string{
+	"login":  "LoginHook",
+	"logout": "LogoutHook",
+}
+
+func runHooks(args []string) error {
+	sub := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		sub, args = args[0], args[1:]
+	}
+	switch sub {
+	case "list":
+		fs := flag.NewFlagSet("hooks list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		hooks := listLoginHooks()
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(hooks)
+		}
+		printLoginHooks(hooks)
+		return nil
+	case "clear":
+		fs := flag.NewFlagSet("hooks clear", flag.ContinueOnError)
+		kind := fs.String("kind", "", "login|logout")
+		domain := fs.String("domain", "", "user|system|root (default: every domain that has the hook)")
+		yes := fs.Bool("yes", false, "clear without asking")
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		return clearLoginHooks(*kind, *domain, *yes)
+	default:
+		return usagef("unknown hooks subcommand %q", sub)
+	}
+}
+
+// listLoginHooks returns the LoginHook/LogoutHook values set in any hook
+// domain. Domains that cannot be read (root's, without sudo) are skipped.
+func listLoginHooks() []LoginHook {
+	var hooks []LoginHook
+	for _, d := range hookDomains {
+		for _, kind := range []string{"login", "logout"} {
+			script, ok := readDefault(d.domain, hookKeys[kind])
+			if !ok || script == "" {
+				continue
+			}
+			h := LoginHook{Kind: kind, Domain: d.name, Script: script}
+			if _, err := os.Stat(script); os.IsNotExist(err) {
+				h.Missing = true
+			}
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// readDefault returns `defaults read domain key`, or ok=false when the key
+// is unset or the domain unreadable.
+func readDefault(domain, key string) (string, bool) {
+	cmd, done := toolCommand("defaults", "read", domain, key)
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(out)), true
+}
+
+func clearLoginHooks(kind, domain string, yes bool) error {
+	key, ok := hookKeys[kind]
+	if !ok {
+		return usagef("--kind must be login or logout")
+	}
+	var targets []LoginHook
+	for _, h := range listLoginHooks() {
+		if h.Kind == kind && (domain == "" || h.Domain == domain) {
+			targets = append(targets, h)
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no %s found", key)
+	}
+	for _, h := range targets {
+		if !yes && !confirm(fmt.Sprintf("Clear %s %s (%s)?", h.Domain, key, h.Script)) {
+			continue
+		}
+		for _, d := range hookDomains {
+			if d.name != h.Domain {
+				continue
+			}
+			if dryRunCommand("defaults", "delete", d.domain, key) {
+				continue
+			}
+			cmd, done := toolCommand("defaults", "delete", d.domain, key)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			if err := done(cmd.Run()); err != nil {
+				err = fmt.Errorf("defaults delete %s %s: %w: %s", d.domain, key, err, strings.TrimSpace(stderr.String()))
+				recordAction("hooks remove", d.domain+" "+key, "", h.Script, err)
+				return err
+			}
+			recordAction("hooks remove", d.domain+" "+key, "", h.Script, nil)
+			successf("cleared %s %s", h.Domain, key)
+		}
+	}
+	return nil
+}
+
+func printLoginHooks(hooks []LoginHook) {
+	if len(hooks) == 0 {
+		fmt.Println("No login/logout hooks set")
+		return
+	}
+	fmt.Printf("%-7s %-7s %s\n", "KIND", "DOMAIN", "SCRIPT")
+	for _, h := range hooks {
+		script := h.Script
+		if h.Missing {
+			script += " (missing)"
+		}
+		fmt.Printf("%-7s %-7s %s\n", h.Kind, h.Domain, script)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// ServiceInfo is what `background info` reports: the plist's program and
+// trigger plus the launchd resources (sockets, Mach services, watched paths)
+// that can cause launchd to start the job on demand.
+type ServiceInfo struct {
+	Label            string         `json:"label"`
+	Scope            string         `json:"scope"`
+	Kind             string         `json:"kind"`
+	Path             string         `json:"path"`
+	Program          string         `json:"program,omitempty"`
+	Arguments        []string       `json:"arguments,omitempty"`
+	Trigger          string         `json:"trigger,omitempty"`
+	State            string         `json:"state,omitempty"`
+	PID              string         `json:"pid,omitempty"`
+	Sockets          []string       `json:"sockets,omitempty"`
+	MachServices     []string       `json:"mach_services,omitempty"`
+	WatchPaths       []string       `json:"watch_paths,omitempty"`
+	QueueDirectories []string       `json:"queue_directories,omitempty"`
+	Signature        *CodeSignature `json:"signature,omitempty"`
+	SHA256           string         `json:"sha256,omitempty"`
+}
+
+// buildServiceInfo collects the plist-declared details of item. loaded may
+// be nil when the service is not loaded.
+func buildServiceInfo(item BackgroundItem, plist map[string]any, loaded *launchctlBlock) ServiceInfo {
+	info := ServiceInfo{
+		Label:            item.Label,
+		Scope:            item.Scope,
+		Kind:             item.Kind,
+		Path:             item.Path,
+		Program:          plistProgram(plist),
+		Arguments:        plistStrings(plist, "ProgramArguments"),
+		Trigger:          describeTrigger(plist),
+		Sockets:          describeSockets(plist),
+		MachServices:     describeMachServices(plist),
+		WatchPaths:       plistStrings(plist, "WatchPaths"),
+		QueueDirectories: plistStrings(plist, "QueueDirectories"),
+	}
+	if len(info.Arguments) > 0 && info.Arguments[0] == info.Program {
+		info.Arguments = info.Arguments[1:]
+	}
+	if loaded != nil {
+		info.State = loaded.values["state"]
+		info.PID = loaded.values["pid"]
+	}
+	return info
+}
+
+// describeSockets renders each entry of the plist's Sockets dictionary as
+// "name: proto address", e.g. "Listeners: tcp localhost:8080" or
+// "Listener: unix /var/run/foo.sock". A name may map to one socket or an
+// array of them.
+func describeSockets(plist map[string]any) []string {
+	sockets, _ := plist["Sockets"].(map[string]any)
+	names := make([]string, 0, len(sockets))
+	for name := range sockets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []string
+	for _, name := range names {
+		var specs []map[string]any
+		switch v := sockets[name].(type) {
+		case map[string]any:
+			specs = append(specs, v)
+		case []any:
+			for _, e := range v {
+				if d, ok := e.(map[string]any); ok {
+					specs = append(specs, d)
+				}
+			}
+		}
+		for _, spec := range specs {
+			out = append(out, name+": "+describeSocket(spec))
+		}
+	}
+	return out
+}
+
+func describeSocket(spec map[string]any) string {
+	if path := plistString(spec, "SockPathName"); path != "" {
+		return "unix " + path
+	}
+	proto := "tcp"
+	if plistString(spec, "SockType") == "dgram" {
+		proto = "udp"
+	}
+	host := plistString(spec, "SockNodeName")
+	if host == "" {
+		host = "*"
+	}
+	port := plistString(spec, "SockServiceName")
+	if port == "" {
+		if n, ok := plistInt(spec, "SockServiceName"); ok {
+			port = fmt.Sprint(n)
+		}
+	}
+	return proto + " " + host + ":" + port
+}
+
+// describeMachServices lists the plist's MachServices names, noting the ones
+// that only reset or hide the service rather than plainly advertising it.
+func describeMachServices(plist map[string]any) []string {
+	services, _ := plist["MachServices"].(map[string]any)
+	out := make([]string, 0, len(services))
+	for name, v := range services {
+		if opts, ok := v.(map[string]any); ok {
+			var flags []string
+			for _, k := range []string{"ResetAtClose", "HideUntilCheckIn"} {
+				if b, _ := opts[k].(bool); b {
+					flags = append(flags, k)
+				}
+			}
+			if len(flags) > 0 {
+				name += " (" + strings.Join(flags, ", ") + ")"
+			}
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// serviceInfoLines formats info for the CLI and the TUI detail pane.
+func serviceInfoLines(info ServiceInfo) []string {
+	state := "not loaded"
+	if info.State != "" {
+		state = info.State
+		if info.PID != "" {
+			state += " (pid " + info.PID + ")"
+		}
+	}
+	program := info.Program
+	if len(info.Arguments) > 0 {
+		program += " " + strings.Join(info.Arguments, " ")
+	}
+	trigger := info.Trigger
+	if trigger == "" {
+		trigger = "-"
+	}
+	lines := []string{
+		"Label:   " + info.Label,
+		"Scope:   " + info.Scope + " (" + info.Kind + ")",
+		"Path:    " + info.Path,
+		"Program: " + program,
+		"Trigger: " + trigger,
+		"State:   " + state,
+	}
+	section := func(title string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		lines = append(lines, title+":")
+		for _, v := range values {
+			lines = append(lines, "  "+v)
+		}
+	}
+	if info.Signature != nil {
+		valid := "valid"
+		if !info.Signature.Valid {
+			valid = "invalid: " + orDash(info.Signature.Error)
+		}
+		lines = append(lines,
+			"Signer:  "+orDash(info.Signature.Signer),
+			"TeamID:  "+orDash(info.Signature.TeamID)+" ("+valid+")",
+		)
+	}
+	if info.SHA256 != "" {
+		lines = append(lines, "SHA256:  "+info.SHA256)
+	}
+	section("Sockets", info.Sockets)
+	section("MachServices", info.MachServices)
+	section("WatchPaths", info.WatchPaths)
+	section("QueueDirectories", info.QueueDirectories)
+	return lines
+}
+
+// loadServiceInfo reads item's plist and, when loaded, its launchd state,
+// and verifies the program's signature.
+func loadServiceInfo(c mlogin.Client, item BackgroundItem) (ServiceInfo, error) {
+	if item.Path == "" {
+		return ServiceInfo{}, fmt.Errorf("%s has no plist on disk", item.Label)
+	}
+	plist, err := readPlist(item.Path)
+	if err != nil {
+		return ServiceInfo{}, err
+	}
+	loaded, err := printLaunchService(c, item)
+	if err != nil {
+		loaded = nil
+	}
+	info := buildServiceInfo(item, plist, loaded)
+	if info.Program != "" {
+		sig := verifyCodeSignature(info.Program)
+		info.Signature = &sig
+	}
+	return info, nil
+}
+
+func runBackgroundInfo(c mlogin.Client, label, scope string, jsonOut, hashes bool) error {
+	if scope == "" {
+		scope = "all"
+	}
+	item, err := resolveBackgroundPlist(label, scope)
+	if err != nil {
+		return err
+	}
+	info, err := loadServiceInfo(c, item)
+	if err != nil {
+		return err
+	}
+	if hashes && info.Program != "" {
+		if info.SHA256, err = programSHA256(info.Program); err != nil {
+			warn(err)
+		}
+	}
+	if jsonOut || outputFormat != "" {
+		return writeOutput(info)
+	}
+	for _, line := range serviceInfoLines(info) {
+		fmt.Println(line)
+	}
+	return nil
+}
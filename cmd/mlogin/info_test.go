@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuildServiceInfo(t *testing.T) {
+	plist := map[string]any{
+		"Label":            "com.example.agent",
+		"ProgramArguments": []any{"/usr/local/bin/agent", "--serve"},
+		"Sockets": map[string]any{
+			"Listeners": map[string]any{
+				"SockNodeName":    "localhost",
+				"SockServiceName": "8080",
+			},
+			"Control": []any{
+				map[string]any{"SockPathName": "/var/run/agent.sock"},
+			},
+		},
+		"MachServices": map[string]any{
+			"com.example.agent.xpc":    true,
+			"com.example.agent.helper": map[string]any{"ResetAtClose": true},
+		},
+		"WatchPaths": []any{"/etc/agent.conf"},
+	}
+	item := BackgroundItem{Label: "com.example.agent", Scope: "user", Kind: "agent", Path: "/tmp/com.example.agent.plist"}
+	loaded := parseLaunchctlPrint("gui/501/com.example.agent = {\n\tstate = running\n\tpid = 42\n}\n")
+	info := buildServiceInfo(item, plist, loaded)
+
+	if info.Program != "/usr/local/bin/agent" || !slices.Equal(info.Arguments, []string{"--serve"}) {
+		t.Fatalf("unexpected program: %q %v", info.Program, info.Arguments)
+	}
+	wantSockets := []string{"Control: unix /var/run/agent.sock", "Listeners: tcp localhost:8080"}
+	if !slices.Equal(info.Sockets, wantSockets) {
+		t.Fatalf("sockets = %v, want %v", info.Sockets, wantSockets)
+	}
+	wantMach := []string{"com.example.agent.helper (ResetAtClose)", "com.example.agent.xpc"}
+	if !slices.Equal(info.MachServices, wantMach) {
+		t.Fatalf("mach services = %v, want %v", info.MachServices, wantMach)
+	}
+	if !slices.Equal(info.WatchPaths, []string{"/etc/agent.conf"}) {
+		t.Fatalf("unexpected watch paths: %v", info.WatchPaths)
+	}
+	if info.State != "running" || info.PID != "42" {
+		t.Fatalf("unexpected state: %q pid %q", info.State, info.PID)
+	}
+}
+
+func TestServiceInfoLinesSignature(t *testing.T) {
+	info := ServiceInfo{
+		Label:     "com.example.agent",
+		Signature: &CodeSignature{Signer: "Developer ID Application: Example (ABCDE12345)", TeamID: "ABCDE12345", Error: "a sealed resource is missing or invalid"},
+	}
+	lines := serviceInfoLines(info)
+	if !slices.Contains(lines, "Signer:  Developer ID Application: Example (ABCDE12345)") {
+		t.Fatalf("missing signer line: %q", lines)
+	}
+	if !slices.Contains(lines, "TeamID:  ABCDE12345 (invalid: a sealed resource is missing or invalid)") {
+		t.Fatalf("missing team line: %q", lines)
+	}
+}
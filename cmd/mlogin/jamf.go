@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// printJamfEA prints values one per line as a single extension attribute
+// result. No values yields an empty result, which Jamf stores as blank.
+func printJamfEA(values []string) {
+	fmt.Printf("<result>%s</result>\n", strings.Join(values, "\n"))
+}
+
+func jamfLoginItems(items []LoginItem) []string {
+	values := make([]string, 0, len(items))
+	for _, it := range items {
+		values = append(values, it.Name)
+	}
+	return values
+}
+
+func jamfBackgroundItems(items []BackgroundItem) []string {
+	values := make([]string, 0, len(items))
+	for _, it := range items {
+		state := "enabled"
+		if it.Disabled != nil && *it.Disabled {
+			state = "disabled"
+		}
+		values = append(values, it.Label+" ("+state+")")
+	}
+	return values
+}
+
+func jamfSystemExtensions(items []SystemExtensionItem) []string {
+	values := make([]string, 0, len(items))
+	for _, it := range items {
+		values = append(values, it.BundleID+" ("+it.State+")")
+	}
+	return values
+}
+
+func jamfAuditReport(report []AuditSurface) []string {
+	var values []string
+	for _, s := range report {
+		for _, e := range s.Entries {
+			values = append(values, s.Surface+": "+e.Name)
+		}
+	}
+	return values
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestJamfValues(t *testing.T) {
+	yes := true
+	items := []BackgroundItem{{Label: "com.example.agent"}, {Label: "com.example.off", Disabled: &yes}}
+	want := []string{"com.example.agent (enabled)", "com.example.off (disabled)"}
+	if got := jamfBackgroundItems(items); !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	report := []AuditSurface{{Surface: "cron", Entries: []AuditEntry{{Name: "user:alice line 3"}}}, {Surface: "emond"}}
+	if got := jamfAuditReport(report); !slices.Equal(got, []string{"cron: user:alice line 3"}) {
+		t.Fatalf("unexpected audit values: %q", got)
+	}
+	if err := checkOutputFormat("xml"); err == nil {
+		t.Fatalf("expected unknown output format to be rejected")
+	}
+}
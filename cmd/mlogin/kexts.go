@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type KextItem struct {
+	Index    int    `json:"index"`
+	Refs     int    `json:"refs"`
+	BundleID string `json:"bundle_id"`
+	Version  string `json:"version,omitempty"`
+	UUID     string `json:"uuid,omitempty"`
+	Path     string `json:"path,omitempty"`
+	TeamID   string `json:"team_id,omitempty"`
+}
+
+// kextDirs are searched for the bundle of a loaded kext. Third-party kexts
+// are installed to /Library/Extensions and staged by macOS under
+// /Library/StagedExtensions before they load.
+var kextDirs = []string{
+	"/Library/Extensions",
+	"/Library/StagedExtensions/Library/Extensions",
+	"/System/Library/Extensions",
+}
+
+func runKexts(args []string) error {
+	if len(args) == 0 {
+		return usagef("missing kexts subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("kexts list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		includeApple := fs.Bool("include-apple", false, "also list Apple kexts")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		items, err := listKexts(*includeApple)
+		if err != nil {
+			return err
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(items)
+		}
+		printKexts(items)
+		return nil
+	default:
+		return usagef("unknown kexts subcommand %q", args[0])
+	}
+}
+
+// listKexts returns loaded kernel extensions with their bundle path and
+// signing Team ID. Apple's own kexts are skipped unless includeApple is set.
+func listKexts(includeApple bool) ([]KextItem, error) {
+	cmd, done := toolCommand("kmutil", "showloaded", "--list-only")
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		return nil, fmt.Errorf("kmutil showloaded: %w", err)
+	}
+	all := parseKmutilShowloaded(string(out))
+	paths := kextBundlePaths()
+	items := all[:0]
+	for _, k := range all {
+		if !includeApple && strings.HasPrefix(k.BundleID, "com.apple.") {
+			continue
+		}
+		k.Path = paths[k.BundleID]
+		if k.Path != "" {
+			k.TeamID = programTeamID(k.Path)
+		}
+		items = append(items, k)
+	}
+	return items, nil
+}
+
+// parseKmutilShowloaded parses `kmutil showloaded --list-only` rows:
+//
+//	Index Refs Address Size Wired Name (Version) UUID <Linked Against>
+//
+// Header and informational lines ("No variant specified, ...") are skipped.
+func parseKmutilShowloaded(out string) []KextItem {
+	var items []KextItem
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		refs, _ := strconv.Atoi(fields[1])
+		k := KextItem{Index: index, Refs: refs, BundleID: fields[5]}
+		rest := fields[6:]
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "(") {
+			k.Version = strings.Trim(rest[0], "()")
+			rest = rest[1:]
+		}
+		if len(rest) > 0 && !strings.HasPrefix(rest[0], "<") {
+			k.UUID = rest[0]
+		}
+		items = append(items, k)
+	}
+	return items
+}
+
+// kextBundlePaths maps bundle identifiers to .kext bundles in kextDirs. The
+// first directory listing a bundle wins.
+func kextBundlePaths() map[string]string {
+	paths := map[string]string{}
+	for _, dir := range kextDirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.kext"))
+		for _, m := range matches {
+			plist, err := readPlist(filepath.Join(m, "Contents", "Info.plist"))
+			if err != nil {
+				continue
+			}
+			id := plistString(plist, "CFBundleIdentifier")
+			if _, seen := paths[id]; id != "" && !seen {
+				paths[id] = m
+			}
+		}
+	}
+	return paths
+}
+
+func printKexts(items []KextItem) {
+	if len(items) == 0 {
+		fmt.Println("No kernel extensions found")
+		return
+	}
+	fmt.Printf("%-5s %-4s %-10s %-44s %s\n", "INDEX", "REFS", "TEAMID", "BUNDLEID", "VERSION")
+	for _, k := range items {
+		fmt.Printf("%-5d %-4d %-10s %-44s %s\n", k.Index, k.Refs, orDash(k.TeamID), k.BundleID, k.Version)
+		if k.Path != "" {
+			fmt.Printf("  %s\n", k.Path)
+		}
+	}
+}
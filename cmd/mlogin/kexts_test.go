@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestParseKmutilShowloaded(t *testing.T) {
+	out := `No variant specified, falling back to release
+Index Refs Address            Size       Wired      Name (Version) UUID <Linked Against>
+    1  168 0                  0          0          com.apple.kpi.bsd (23.1.0) 5C2D0D7C-8A65-3B5A-9F3E-1E3B5A9F3E1E <>
+  212    0 0xffffff7f8a3b4000 0x5000     0x5000     com.example.driver (1.2.3) 0F1E2D3C-4B5A-6978-8796-A5B4C3D2E1F0 <6 5 4 3 1>
+`
+	items := parseKmutilShowloaded(out)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 kexts, got %d (%+v)", len(items), items)
+	}
+	k := items[1]
+	if k.Index != 212 || k.Refs != 0 || k.BundleID != "com.example.driver" || k.Version != "1.2.3" || k.UUID != "0F1E2D3C-4B5A-6978-8796-A5B4C3D2E1F0" {
+		t.Fatalf("unexpected kext: %+v", k)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// launchctlBlock is one "name = { ... }" section of `launchctl print` output.
+// Plain "key = value" and "key => value" lines land in values, nested
+// sections in blocks, and bare lines (such as program arguments) in items.
+type launchctlBlock struct {
+	values map[string]string
+	blocks map[string]*launchctlBlock
+	items  []string
+}
+
+func newLaunchctlBlock() *launchctlBlock {
+	return &launchctlBlock{values: map[string]string{}, blocks: map[string]*launchctlBlock{}}
+}
+
+// parseLaunchctlPrint parses the output of `launchctl print <target>` into
+// its outermost block.
+func parseLaunchctlPrint(out string) *launchctlBlock {
+	root := newLaunchctlBlock()
+	var stack []*launchctlBlock
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasSuffix(line, "= {") {
+			name := strings.TrimSpace(strings.TrimSuffix(line, "= {"))
+			if len(stack) == 0 {
+				stack = append(stack, root)
+				continue
+			}
+			child := newLaunchctlBlock()
+			stack[len(stack)-1].blocks[name] = child
+			stack = append(stack, child)
+			continue
+		}
+		if len(stack) == 0 {
+			continue
+		}
+		cur := stack[len(stack)-1]
+		if line == "}" {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if k, v, ok := strings.Cut(line, " => "); ok {
+			cur.values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			continue
+		}
+		if k, v, ok := strings.Cut(line, " = "); ok {
+			cur.values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			continue
+		}
+		cur.items = append(cur.items, line)
+	}
+	return root
+}
+
+// block returns the named child block, or an empty block when absent.
+func (b *launchctlBlock) block(name string) *launchctlBlock {
+	if c, ok := b.blocks[name]; ok {
+		return c
+	}
+	return newLaunchctlBlock()
+}
+
+// serviceDomain returns the launchctl domain a background item runs in.
+// Agents run in the user's GUI session even when installed system-wide.
+func serviceDomain(c mlogin.Client, item BackgroundItem) (string, error) {
+	switch item.Kind {
+	case "agent":
+		return launchDomain(c, "user")
+	case "daemon":
+		return "system", nil
+	default:
+		return launchDomain(c, item.Scope)
+	}
+}
+
+// printLaunchService runs `launchctl print` for the item's service target.
+func printLaunchService(c mlogin.Client, item BackgroundItem) (*launchctlBlock, error) {
+	domain, err := serviceDomain(c, item)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runLaunchctlOutput(c, "print", domain+"/"+item.Label)
+	if err != nil {
+		return nil, err
+	}
+	return parseLaunchctlPrint(out), nil
+}
+
+// launchServices indexes the service blocks of `launchctl dumpstate` by
+// target ("system/com.example.daemon", "gui/501/com.example.agent"), so a
+// pass over many items runs launchctl once rather than once per item.
+type launchServices map[string]*launchctlBlock
+
+// launchServicesFor dumps launchd state when items has more than one entry;
+// a single item is cheaper to print directly. When dumpstate fails (it
+// needs root on recent macOS) the index is empty and every lookup falls
+// back to printLaunchService.
+func launchServicesFor(c mlogin.Client, items []BackgroundItem) launchServices {
+	if len(items) < 2 {
+		return launchServices{}
+	}
+	out, err := runLaunchctlOutput(c, "dumpstate")
+	if err != nil {
+		return launchServices{}
+	}
+	return parseLaunchctlDump(out)
+}
+
+// parseLaunchctlDump splits dumpstate output into its top-level blocks and
+// indexes every block, at any depth, whose name is a service target.
+func parseLaunchctlDump(out string) launchServices {
+	ix := launchServices{}
+	var index func(name string, b *launchctlBlock)
+	index = func(name string, b *launchctlBlock) {
+		if strings.Contains(name, "/") {
+			ix[name] = b
+		}
+		for child, c := range b.blocks {
+			index(child, c)
+		}
+	}
+	var name string
+	var chunk []string
+	depth := 0
+	s := bufio.NewScanner(strings.NewReader(out))
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		opens := strings.HasSuffix(line, "= {")
+		if depth == 0 && !opens {
+			continue
+		}
+		if opens {
+			if depth == 0 {
+				name, chunk = strings.TrimSpace(strings.TrimSuffix(line, "= {")), nil
+			}
+			depth++
+		} else if line == "}" {
+			depth--
+		}
+		chunk = append(chunk, line)
+		if depth == 0 {
+			index(name, parseLaunchctlPrint(strings.Join(chunk, "\n")))
+		}
+	}
+	return ix
+}
+
+// print returns the item's block from the dump, running launchctl print for
+// items the dump does not cover.
+func (ix launchServices) print(c mlogin.Client, item BackgroundItem) (*launchctlBlock, error) {
+	domain, err := serviceDomain(c, item)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := ix[domain+"/"+item.Label]; ok {
+		return b, nil
+	}
+	return printLaunchService(c, item)
+}
+
+// blameLaunchService returns launchctl's reason for the service's most
+// recent launch, such as "ipc (mach message)" or "speculative".
+func blameLaunchService(c mlogin.Client, item BackgroundItem) (string, error) {
+	domain, err := serviceDomain(c, item)
+	if err != nil {
+		return "", err
+	}
+	out, err := runLaunchctlOutput(c, "blame", domain+"/"+item.Label)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+const samplePrint = `gui/501/com.example.agent = {
+	active count = 0
+	path = /Users/test/Library/LaunchAgents/com.example.agent.plist
+	type = LaunchAgent
+	state = spawn scheduled
+
+	program = /usr/local/bin/agent
+	arguments = {
+		/usr/local/bin/agent
+		--serve
+	}
+
+	environment = {
+		PATH => /usr/bin:/bin
+		FOO => bar
+	}
+
+	runs = 12
+	last exit code = 78: EX_CONFIG
+}
+`
+
+func TestParseLaunchctlPrint(t *testing.T) {
+	b := parseLaunchctlPrint(samplePrint)
+	if b.values["program"] != "/usr/local/bin/agent" {
+		t.Fatalf("unexpected program: %q", b.values["program"])
+	}
+	args := b.block("arguments").items
+	if len(args) != 2 || args[1] != "--serve" {
+		t.Fatalf("unexpected arguments: %v", args)
+	}
+	if b.block("environment").values["FOO"] != "bar" {
+		t.Fatalf("unexpected environment: %v", b.block("environment").values)
+	}
+	if b.values["runs"] != "12" {
+		t.Fatalf("unexpected runs: %q", b.values["runs"])
+	}
+}
+
+func TestAssessServiceHealth(t *testing.T) {
+	item := BackgroundItem{Label: "com.example.agent", Scope: "user"}
+	h := assessServiceHealth(item, parseLaunchctlPrint(samplePrint))
+	if h.Health != "crash-loop" || h.Runs != 12 {
+		t.Fatalf("unexpected health: %+v", h)
+	}
+
+	ok := parseLaunchctlPrint("gui/501/com.example.ok = {\n\tstate = not running\n\truns = 4\n\tlast exit code = 0\n}\n")
+	if got := assessServiceHealth(item, ok).Health; got != "ok" {
+		t.Fatalf("expected ok, got %q", got)
+	}
+}
+
+func TestCompareLoadedConfig(t *testing.T) {
+	plist := map[string]any{
+		"Label":                "com.example.agent",
+		"ProgramArguments":     []any{"/usr/local/bin/agent", "--serve", "--verbose"},
+		"EnvironmentVariables": map[string]any{"PATH": "/usr/bin:/bin", "FOO": "bar"},
+	}
+	diffs := compareLoadedConfig(plist, parseLaunchctlPrint(samplePrint))
+	if len(diffs) != 1 || !strings.HasPrefix(diffs[0], "ProgramArguments:") {
+		t.Fatalf("unexpected diffs: %v", diffs)
+	}
+
+	plist["ProgramArguments"] = []any{"/usr/local/bin/agent", "--serve"}
+	if diffs := compareLoadedConfig(plist, parseLaunchctlPrint(samplePrint)); len(diffs) != 0 {
+		t.Fatalf("expected no drift, got %v", diffs)
+	}
+}
+
+func TestMergeServiceEnv(t *testing.T) {
+	loaded := parseLaunchctlPrint(`gui/501/com.example.agent = {
+	environment = {
+		FOO => old
+	}
+	default environment = {
+		PATH => /usr/bin:/bin:/usr/sbin:/sbin
+	}
+}`)
+	plist := map[string]any{
+		"EnvironmentVariables": map[string]any{"FOO": "new", "BAR": "1"},
+	}
+	vars := mergeServiceEnv(plist, loaded)
+	if len(vars) != 3 {
+		t.Fatalf("unexpected vars: %+v", vars)
+	}
+	byName := map[string]EnvVar{}
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+	if v := byName["FOO"]; v.Value != "old" || v.PlistValue != "new" || v.Source != "plist" {
+		t.Fatalf("unexpected FOO: %+v", v)
+	}
+	if v := byName["PATH"]; v.Source != "default" {
+		t.Fatalf("unexpected PATH: %+v", v)
+	}
+	if v := byName["BAR"]; v.Value != "1" || v.Source != "plist" {
+		t.Fatalf("unexpected BAR: %+v", v)
+	}
+}
+
+func TestLaunchServicesFromDump(t *testing.T) {
+	dump := `com.apple.xpc.launchd.domain.system = {
+	type = system
+	services = {
+		0	0	com.example.daemon
+	}
+}
+
+system/com.example.daemon = {
+	state = running
+	runs = 3
+	last exit code = (never exited)
+}
+
+` + samplePrint
+	ix := parseLaunchctlDump(dump)
+	if len(ix) != 2 || ix["system/com.example.daemon"].values["runs"] != "3" || ix["gui/501/com.example.agent"].values["program"] != "/usr/local/bin/agent" {
+		t.Fatalf("unexpected index %v", ix)
+	}
+
+	canned := &cannedRunner{out: map[string]string{
+		"launchctl dumpstate":                      dump,
+		"launchctl print system/com.example.other": "system/com.example.other = {\n\tstate = waiting\n}\n",
+	}}
+	c := mlogin.Client{Runner: canned}
+	items := []BackgroundItem{
+		{Label: "com.example.daemon", Scope: "system", Kind: "daemon"},
+		{Label: "com.example.other", Scope: "system", Kind: "daemon"},
+	}
+	services := launchServicesFor(c, items)
+	for _, it := range items {
+		if _, err := services.print(c, it); err != nil {
+			t.Fatalf("print %s: %v", it.Label, err)
+		}
+	}
+	if strings.Join(canned.ran, ";") != "launchctl dumpstate;launchctl print system/com.example.other" {
+		t.Fatalf("ran %q", canned.ran)
+	}
+}
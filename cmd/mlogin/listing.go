@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sortKeys maps each --sort key of a list command to the value it orders
+// items by.
+type sortKeys[T any] map[string]func(T) string
+
+var loginSortKeys = sortKeys[LoginItem]{
+	"name":   func(it LoginItem) string { return strings.ToLower(it.Name) },
+	"path":   func(it LoginItem) string { return it.Path },
+	"hidden": func(it LoginItem) string { return strconv.FormatBool(it.Hidden) },
+}
+
+var backgroundSortKeys = sortKeys[BackgroundItem]{
+	"name":   func(it BackgroundItem) string { return strings.ToLower(it.Label) },
+	"path":   func(it BackgroundItem) string { return it.Path },
+	"scope":  func(it BackgroundItem) string { return it.Scope },
+	"kind":   func(it BackgroundItem) string { return it.Kind },
+	"loaded": func(it BackgroundItem) string { return strconv.FormatBool(it.Loaded) },
+	"health": func(it BackgroundItem) string { return it.Health },
+}
+
+var extensionSortKeys = sortKeys[SystemExtensionItem]{
+	"name":     func(it SystemExtensionItem) string { return strings.ToLower(it.Name) },
+	"path":     func(it SystemExtensionItem) string { return it.HostApp },
+	"category": func(it SystemExtensionItem) string { return it.Category },
+	"team":     func(it SystemExtensionItem) string { return it.TeamID },
+	"state":    func(it SystemExtensionItem) string { return it.State },
+	"loaded":   func(it SystemExtensionItem) string { return strconv.FormatBool(it.Active) },
+}
+
+// parse splits a --sort value such as "loaded:desc" and checks the key.
+func (keys sortKeys[T]) parse(spec string) (key string, desc bool, err error) {
+	key, order, _ := strings.Cut(spec, ":")
+	switch order {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return "", false, usagef("unknown sort order %q (want asc or desc)", order)
+	}
+	if _, ok := keys[key]; !ok {
+		names := make([]string, 0, len(keys))
+		for k := range keys {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return "", false, usagef("unknown sort key %q (want %s)", key, strings.Join(names, ", "))
+	}
+	return key, desc, nil
+}
+
+// sort orders items by spec, which has been checked with parse; items
+// that tie keep the command's default order. An empty spec leaves items
+// alone.
+func (keys sortKeys[T]) sort(items []T, spec string) {
+	key, desc, err := keys.parse(spec)
+	if spec == "" || err != nil {
+		return
+	}
+	value := keys[key]
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return value(items[i]) > value(items[j])
+		}
+		return value(items[i]) < value(items[j])
+	})
+}
+
+// textFilter is a --filter or TUI filter query: a case-insensitive
+// substring, or a regular expression between slashes such as
+// /^com\.(adobe|google)\./.
+type textFilter struct {
+	sub string
+	re  *regexp.Regexp
+}
+
+func parseTextFilter(q string) (textFilter, error) {
+	q = strings.TrimSpace(q)
+	if len(q) >= 2 && strings.HasPrefix(q, "/") && strings.HasSuffix(q, "/") {
+		re, err := regexp.Compile("(?i)" + q[1:len(q)-1])
+		if err != nil {
+			return textFilter{}, fmt.Errorf("filter %s: %w", q, err)
+		}
+		return textFilter{re: re}, nil
+	}
+	return textFilter{sub: strings.ToLower(q)}, nil
+}
+
+// match reports whether any of fields matches; an empty filter matches
+// everything.
+func (f textFilter) match(fields ...string) bool {
+	if f.re == nil && f.sub == "" {
+		return true
+	}
+	for _, s := range fields {
+		if f.re != nil && f.re.MatchString(s) || f.re == nil && strings.Contains(strings.ToLower(s), f.sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterItems[T any](items []T, f textFilter, fields func(T) []string) []T {
+	out := items[:0]
+	for _, it := range items {
+		if f.match(fields(it)...) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortKeys(t *testing.T) {
+	items := []BackgroundItem{
+		{Label: "b", Scope: "user", Loaded: true},
+		{Label: "a", Scope: "system"},
+		{Label: "C", Scope: "user"},
+	}
+	labels := func() string {
+		var out []string
+		for _, it := range items {
+			out = append(out, it.Label)
+		}
+		return strings.Join(out, ",")
+	}
+	backgroundSortKeys.sort(items, "name")
+	if got := labels(); got != "a,b,C" {
+		t.Fatalf("sort by name: %s", got)
+	}
+	backgroundSortKeys.sort(items, "loaded:desc")
+	if got := labels(); got != "b,a,C" {
+		t.Fatalf("sort by loaded:desc: %s", got)
+	}
+	for _, spec := range []string{"colour", "name:up"} {
+		if _, _, err := backgroundSortKeys.parse(spec); err == nil {
+			t.Fatalf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestTextFilter(t *testing.T) {
+	items := []BackgroundItem{
+		{Label: "com.adobe.AdobeCreativeCloud", Scope: "user"},
+		{Label: "com.google.keystone.agent", Path: "/Library/LaunchAgents/com.google.keystone.agent.plist", Scope: "system"},
+		{Label: "org.example.sync", Scope: "user"},
+	}
+	f, err := parseTextFilter("KEYSTONE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filterItems(slices.Clone(items), f, backgroundFilterFields); len(got) != 1 || got[0].Label != "com.google.keystone.agent" {
+		t.Fatalf("substring filter: %+v", got)
+	}
+	f, err = parseTextFilter(`/^com\.(adobe|google)\./`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filterItems(slices.Clone(items), f, backgroundFilterFields); len(got) != 2 {
+		t.Fatalf("regexp filter: %+v", got)
+	}
+	if _, err := parseTextFilter("/com.(adobe/"); err == nil {
+		t.Fatalf("expected an invalid regexp to be rejected")
+	}
+	if f := tuiFilter("/com.(adobe/"); !f.match("x/com.(adobe/") {
+		t.Fatalf("expected an unfinished regexp to match as text in the TUI")
+	}
+}
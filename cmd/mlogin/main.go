@@ -2,18 +2,17 @@ package main
 
 import (
 	"bufio"
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
-	"os/user"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
 )
 
 var (
@@ -22,89 +21,253 @@ var (
 	date    = "unknown"
 )
 
-type LoginItem struct {
-	Name   string `json:"name"`
-	Path   string `json:"path"`
-	Hidden bool   `json:"hidden"`
-}
+// LoginItem and SystemExtensionItem come from the library, so the CLI's
+// output matches what programs embedding pkg/mlogin get.
+type LoginItem = mlogin.LoginItem
 
 type BackgroundItem struct {
-	Label    string `json:"label"`
-	Path     string `json:"path"`
-	Scope    string `json:"scope"`
-	Kind     string `json:"kind"`
-	Loaded   bool   `json:"loaded"`
-	Disabled *bool  `json:"disabled,omitempty"`
+	Label      string `json:"label"`
+	Path       string `json:"path"`
+	Scope      string `json:"scope"`
+	Kind       string `json:"kind"`
+	Loaded     bool   `json:"loaded"`
+	Disabled   *bool  `json:"disabled,omitempty"`
+	Health     string `json:"health,omitempty"`
+	Provenance string `json:"provenance,omitempty"`
+	Trigger    string `json:"trigger,omitempty"`
+	Managed    bool   `json:"managed,omitempty"`
+	// Risk is the heuristic score from assessRisk; only computed for
+	// --long and --suspicious-only.
+	Risk        int      `json:"risk,omitempty"`
+	RiskReasons []string `json:"risk_reasons,omitempty"`
+	// Signature is only verified for --long.
+	Signature *CodeSignature `json:"signature,omitempty"`
+	// Verification is only assessed for --verify.
+	Verification *ProgramVerification `json:"verification,omitempty"`
+	// SHA256 is the program's hash, only computed for --hashes.
+	SHA256 string `json:"sha256,omitempty"`
 }
 
-type SystemExtensionItem struct {
-	Category string `json:"category"`
-	Enabled  bool   `json:"enabled"`
-	Active   bool   `json:"active"`
-	TeamID   string `json:"team_id"`
-	BundleID string `json:"bundle_id"`
-	Version  string `json:"version,omitempty"`
-	Name     string `json:"name"`
-	State    string `json:"state"`
-}
+type SystemExtensionItem = mlogin.SystemExtensionItem
 
 func main() {
-	if err := run(os.Args[1:]); err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
+	err := run(os.Args[1:])
+	code := exitCode(err)
+	var status exitStatusError
+	// The remote mlogin has already reported its error, and the flag
+	// package prints its own help.
+	if code != exitOK && !errors.As(err, &status) {
+		reportError(os.Stderr, err)
 	}
+	os.Exit(code)
 }
 
 func run(args []string) error {
+	// A broken config must not lock the user out of version, help, and
+	// doctor, which is where they would go to find out what is wrong.
+	if err := loadConfig(); err != nil {
+		warnf("ignoring config: %v", err)
+	}
+	args = parseGlobalFlags(args)
+	if err := checkOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	if err := setCommandTimeout(timeoutFlag); err != nil {
+		return err
+	}
+	if noColor {
+		disableColor()
+	}
 	if len(args) == 0 {
 		printUsage()
 		return nil
 	}
+	if remoteHost != "" {
+		return runRemote(remoteHost, args)
+	}
+	if outputFormat == outputTable {
+		outputFormat = ""
+	}
+	if isPagedCommand(args) {
+		defer startPager()()
+	}
 
-	switch args[0] {
-	case "version", "--version", "-v":
-		printVersion()
-		return nil
-	case "login":
-		return runLogin(args[1:])
-	case "background", "bg":
-		return runBackground(args[1:])
-	case "extensions", "ext":
-		return runExtensions(args[1:])
-	case "tui", "ui":
-		return runTUI()
-	case "help", "-h", "--help":
-		printUsage()
-		return nil
-	default:
-		return fmt.Errorf("unknown command %q", args[0])
+	return dispatch(args)
+}
+
+// parseGlobalFlags removes flags that apply to every command from args and
+// applies them.
+func parseGlobalFlags(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			// Everything after -- belongs to the command (e.g. the program
+			// arguments of background create).
+			return append(out, args[i:]...)
+		case a == "--no-sudo":
+			escalation = escalateNone
+		case a == "--no-color":
+			noColor = true
+		case a == "--no-pager":
+			noPager = true
+		case a == "--dry-run":
+			dryRun = true
+		case a == "--quiet":
+			quiet = true
+		case a == "--verbose":
+			verbose = true
+		case (a == "--host" || a == "--remote-mlogin") && i+1 < len(args):
+			i++
+			if a == "--host" {
+				remoteHost = args[i]
+			} else {
+				remoteMlogin = args[i]
+			}
+		case strings.HasPrefix(a, "--host="):
+			remoteHost = strings.TrimPrefix(a, "--host=")
+		case strings.HasPrefix(a, "--remote-mlogin="):
+			remoteMlogin = strings.TrimPrefix(a, "--remote-mlogin=")
+		case (a == "--output" || a == "-o") && i+1 < len(args):
+			i++
+			outputFormat = args[i]
+		case strings.HasPrefix(a, "--output="):
+			outputFormat = strings.TrimPrefix(a, "--output=")
+		case len(out) > 0 && out[0] == "fleet" && (a == "--timeout" || strings.HasPrefix(a, "--timeout=")):
+			// fleet has its own per-host --timeout.
+			out = append(out, a)
+		case a == "--timeout" && i+1 < len(args):
+			i++
+			timeoutFlag = args[i]
+		case strings.HasPrefix(a, "--timeout="):
+			timeoutFlag = strings.TrimPrefix(a, "--timeout=")
+		default:
+			out = append(out, a)
+		}
 	}
+	return out
 }
 
 func printUsage() {
-	fmt.Println(`mlogin - manage macOS login and background items
+	fmt.Println(usageText)
+}
+
+// usageText is the overview `mlogin help` prints. Command help is cut
+// from its "mlogin <command>" lines.
+const usageText = `mlogin - manage macOS login and background items
 
 Usage:
+  mlogin [--no-sudo] [--no-color] [--no-pager] [--dry-run] [--quiet|--verbose] [--timeout 1m] [--host user@mac [--remote-mlogin PATH]] [-o table|json|ndjson|yaml|csv|tsv] <command> ...
+
   mlogin version
   mlogin tui
 
-  mlogin login list [--json]
+  mlogin login list [--json] [--columns name,path] [--sort name|path[:desc]] [--filter text|/regexp/] [--output jamf-ea]
   mlogin login add --path <app path> [--hidden]
   mlogin login remove (--name <item name> | --path <app path>)
+  mlogin login ensure --path <app path> [--state present|absent] [--hidden] [--json]
+
+  mlogin background list [--json] [--columns label,scope,loaded] [--sort name|path|scope|loaded[:desc]] [--filter text|/regexp/] [--long] [--suspicious-only] [--verify] [--hashes] [--output jamf-ea] [--scope user|system|all] [--include-apple] [--hide-apple=false]
+  mlogin background enable --label <label|pattern> [--scope user|system] [--yes] [--brew]
+  mlogin background disable --label <label|pattern> [--scope user|system] [--yes] [--brew]
+  mlogin background disable --vendor <name|team id> [--scope user|system] [--yes]
+  mlogin background enable|disable|unload --labels-from <file|-> [--scope user|system] [--yes]
+  mlogin background ensure --label <label> --state enabled|disabled [--scope user|system] [--json]
+  mlogin background load --plist <plist path> [--scope user|system] [--skip-validate]
+  mlogin background validate --plist <plist path> [--scope user|system]
+  mlogin background unload (--label <label|pattern> | --plist <plist path>) [--scope user|system] [--yes]
+  mlogin background reload --label <label> [--scope user|system|all]
+  mlogin background kickstart --label <label> [--scope user|system] [--force]
+  mlogin background logs --label <label> [--scope user|system|all] [--lines N] [--follow]
+  mlogin background create --label <label> --program <path> [--interval N] [--run-at-load]
+                           [--log <path>] [--scope user|system] [--load] [-- args...]
+  mlogin background edit --label <label> [--scope user|system|all] [--reload]
+  mlogin background delete (--label <label> | --plist <plist path>) [--scope user|system] [--permanent] [--yes]
+  mlogin background restore [--label <label>] [--load]
+  mlogin background health [--label <label>] [--scope user|system|all] [--all] [--json]
+  mlogin background drift [--label <label>] [--scope user|system|all]
+  mlogin background show (--label <label> | --plist <plist path>) [--format json|yaml]
+  mlogin background env --label <label> [--scope user|system|all] [--json]
+  mlogin background info --label <label> [--scope user|system|all] [--json] [--hashes]
+  mlogin background why --label <label> [--scope user|system|all] [--json]
+  mlogin background blame --label <label> [--scope user|system|all]
+  mlogin background prune [--scope user|system|all] [--dry-run] [--yes]
+  mlogin extensions list [--json] [--columns bundle_id,state] [--sort name|category|state|loaded[:desc]] [--filter text|/regexp/] [--raw] [--output jamf-ea] [--category network|endpoint-security|driver]
+  mlogin extensions info --bundle-id <bundle id> [--json]
+  mlogin extensions approve --bundle-id <bundle id>
+  mlogin extensions uninstall --bundle-id <bundle id> [--team-id <team id>] [--yes]
+  mlogin extensions developer-mode on|off|status
+  mlogin extensions reset [--force]
+  mlogin kexts list [--json] [--include-apple]
+
+  mlogin cron list [--json]
+  mlogin cron remove --line N [--source user|system] [--yes]
 
-  mlogin background list [--json] [--scope user|system|all]
-  mlogin background enable --label <label> [--scope user|system]
-  mlogin background disable --label <label> [--scope user|system]
-  mlogin background load --plist <plist path> [--scope user|system]
-  mlogin background unload --label <label> [--scope user|system]
-  mlogin background delete --label <label> --plist <plist path> [--scope user|system]
-  mlogin extensions list [--json]
+  mlogin at list [--json]
+  mlogin at remove --id <job id> [--yes]
+
+  mlogin hooks [list] [--json]
+  mlogin hooks clear --kind login|logout [--domain user|system|root] [--yes]
+
+  mlogin emond list [--json] [--all]
+
+  mlogin folderactions list [--json]
+  mlogin folderactions remove --folder <path> [--script <name>]
+
+  mlogin authplugins list [--json] [--include-apple]
+
+  mlogin helpers list [--json]
+  mlogin helpers remove (--path <helper path> | --label <label>) [--yes]
+
+  mlogin profiles list [--json] [--relevant]
+
+  mlogin btm list [--json]
+  mlogin btm reset --all [--yes]
+
+  mlogin env-audit [--json] [--scope user|system|all]
+  mlogin audit [--json] [--suspicious-only] [--verify] [--hashes] [--output jamf-ea]
+  mlogin whois <label|path|bundle-id> [--json]
+  mlogin open --label <label> [--scope user|system|all] [--app]
+  mlogin open --bundle-id <extension bundle id>
+  mlogin open settings login-items|extensions|security|full-disk-access|automation
+
+  mlogin snapshot [--out state.json] [--commit]
+  mlogin log [-n 20] [--short]
+  mlogin diff old.json [new.json] [--json]
+  mlogin baseline set
+  mlogin baseline check [--json]
+  mlogin watch [--json] [--notify] [--webhook URL [--webhook-format json|slack]] [--interval 2s] [--poll 30s]
+  mlogin serve [--listen 127.0.0.1:8377] [--token TOKEN] [--read-only] [--allow-remote]
+  mlogin mcp [--read-only]
+  mlogin exporter [--listen 127.0.0.1:9377] [--refresh 1m]
+  mlogin agent install [--mode watch|baseline] [--interval 1h] [--force]
+  mlogin agent uninstall
+  mlogin agent status [--json]
+  mlogin history [--json] [-n 50] [--target TEXT]
+  mlogin fleet --hosts <file> [--format json|csv] [--concurrency 8] [--timeout 2m] <command> [args...]
+  mlogin report [--format html|md] [--out <file>] [--verify]
+  mlogin timeline init
+  mlogin timeline [--label <label>] [-n 50] [--json]
+  mlogin stats [--json] [--days 30] [--include-apple]
+  mlogin doctor [--json]
+  mlogin schema login-item|background-item|system-extension|audit-surface|snapshot|snapshot-change|watch-event|action|timeline-event
+  mlogin apply <manifest.yaml> [--dry-run] [--yes]
+  mlogin profile list
+  mlogin profile save <name> [--force]
+  mlogin profile switch <name> [--dry-run] [--yes]
 
 Notes:
   - tui gives an interactive table view and quick actions.
   - login commands use System Events via osascript.
-  - system background commands may require sudo.`)
-}
+  - system background commands may require sudo; launchctl calls that fail
+    with a permission error are retried via sudo (or an administrator
+    prompt in the tui) unless --no-sudo is given.
+  - --scope is inferred from the plist location when omitted.
+  - as root, --user <name> or --uid <uid> targets another user's agents.
+  - action commands also accept --scope user-domain (user/<uid>) and
+    --scope loginwindow (login/<asid>).
+  - mlogin <command> --help prints the usage of one command.`
 
 func printVersion() {
 	fmt.Printf("mlogin %s\n", version)
@@ -113,25 +276,49 @@ func printVersion() {
 }
 
 func runLogin(args []string) error {
+	c := client()
 	if len(args) == 0 {
-		return errors.New("missing login subcommand")
+		return usagef("missing login subcommand")
 	}
 
 	switch args[0] {
 	case "list":
 		fs := flag.NewFlagSet("login list", flag.ContinueOnError)
 		jsonOut := fs.Bool("json", false, "output JSON")
-		if err := fs.Parse(args[1:]); err != nil {
+		columnList := fs.String("columns", "", "comma-separated fields to show in the table, e.g. name,path")
+		sortBy := fs.String("sort", "", "order by name|path|hidden, optionally with :desc")
+		filterQuery := fs.String("filter", "", "only list items whose name or path contains this (or matches /regexp/)")
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
-		items, err := listLoginItems()
+		filter, err := parseTextFilter(*filterQuery)
 		if err != nil {
 			return err
 		}
-		if *jsonOut {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(items)
+		if *sortBy != "" {
+			if _, _, err := loginSortKeys.parse(*sortBy); err != nil {
+				return err
+			}
+		}
+		columns, err := parseColumns(*columnList, LoginItem{})
+		if err != nil {
+			return err
+		}
+		items, err := listLoginItems(c)
+		if err != nil {
+			return err
+		}
+		items = filterItems(items, filter, loginFilterFields)
+		loginSortKeys.sort(items, *sortBy)
+		if outputFormat == outputJamfEA {
+			printJamfEA(jamfLoginItems(items))
+			return nil
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(items)
+		}
+		if columns != nil {
+			return printColumns(os.Stdout, items, columns)
 		}
 		printLoginItems(items)
 		return nil
@@ -139,183 +326,693 @@ func runLogin(args []string) error {
 		fs := flag.NewFlagSet("login add", flag.ContinueOnError)
 		path := fs.String("path", "", "app path")
 		hidden := fs.Bool("hidden", false, "start hidden")
-		if err := fs.Parse(args[1:]); err != nil {
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
 		if *path == "" {
-			return errors.New("--path is required")
+			return usagef("--path is required")
 		}
-		return addLoginItem(*path, *hidden)
+		if err := addLoginItem(c, *path, *hidden); err != nil {
+			return err
+		}
+		abspath, _ := filepath.Abs(*path)
+		successf("added login item: %s", abspath)
+		return nil
+	case "ensure":
+		return runLoginEnsure(args[1:])
 	case "remove":
 		fs := flag.NewFlagSet("login remove", flag.ContinueOnError)
 		name := fs.String("name", "", "login item name")
 		path := fs.String("path", "", "login item app path")
-		if err := fs.Parse(args[1:]); err != nil {
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
 		if *name == "" && *path == "" {
-			return errors.New("provide --name or --path")
+			return usagef("provide --name or --path")
 		}
-		return removeLoginItem(*name, *path)
+		if err := removeLoginItem(c, *name, *path); err != nil {
+			return err
+		}
+		successf("removed matching login items")
+		return nil
 	default:
-		return fmt.Errorf("unknown login subcommand %q", args[0])
+		return usagef("unknown login subcommand %q", args[0])
 	}
 }
 
 func runBackground(args []string) error {
+	c := client()
 	if len(args) == 0 {
-		return errors.New("missing background subcommand")
+		return usagef("missing background subcommand")
 	}
 
 	switch args[0] {
 	case "list":
 		fs := flag.NewFlagSet("background list", flag.ContinueOnError)
 		jsonOut := fs.Bool("json", false, "output JSON")
-		scope := fs.String("scope", "all", "user|system|all")
-		if err := fs.Parse(args[1:]); err != nil {
+		scope := fs.String("scope", defaultScope("all"), "user|system|all")
+		includeApple := fs.Bool("include-apple", false, "also list Apple items from /System/Library (read-only)")
+		hideApple := fs.Bool("hide-apple", defaultHideApple(), "hide Apple/first-party labels (use --hide-apple=false to show)")
+		long := fs.Bool("long", false, "also read each plist and show what triggers it")
+		suspiciousOnly := fs.Bool("suspicious-only", false, "only list items that score as suspicious")
+		verify := fs.Bool("verify", false, "assess each program with Gatekeeper and check its quarantine attribute")
+		hashes := fs.Bool("hashes", false, "compute the SHA-256 of each program")
+		columnList := fs.String("columns", "", "comma-separated fields to show in the table, e.g. label,scope,loaded")
+		sortBy := fs.String("sort", "", "order by name|path|scope|kind|loaded|health, optionally with :desc")
+		filterQuery := fs.String("filter", "", "only list items whose label, path, scope, or kind contains this (or matches /regexp/)")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
-		items, warnings, err := listBackgroundItems(*scope)
+		filter, err := parseTextFilter(*filterQuery)
 		if err != nil {
 			return err
 		}
+		if *sortBy != "" {
+			if _, _, err := backgroundSortKeys.parse(*sortBy); err != nil {
+				return err
+			}
+		}
+		columns, err := parseColumns(*columnList, BackgroundItem{})
+		if err != nil {
+			return err
+		}
+		if err := applyUser(); err != nil {
+			return err
+		}
+		items, warnings, err := listBackgroundItems(c, *scope, *includeApple)
+		if err != nil {
+			return err
+		}
+		if *hideApple && !*includeApple {
+			items = withoutAppleItems(items)
+		}
+		if *long {
+			fillTriggers(items)
+			fillSignatures(items)
+			// Matching bundle and Team ID rules reads each program's
+			// signature, so profiles are only consulted for --long.
+			if err := fillManaged(items); err != nil {
+				warnings = append(warnings, err.Error())
+			}
+		}
+		if *long || *suspiciousOnly {
+			fillRisk(items)
+		}
+		if *verify {
+			fillVerification(items)
+		}
+		if *hashes {
+			fillHashes(items)
+		}
+		if *suspiciousOnly {
+			items = suspiciousItems(items)
+		}
+		items = filterItems(items, filter, backgroundFilterFields)
+		backgroundSortKeys.sort(items, *sortBy)
 		for _, w := range warnings {
-			fmt.Fprintln(os.Stderr, "warning:", w)
+			warn(w)
 		}
-		if *jsonOut {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(items)
+		if outputFormat == outputJamfEA {
+			printJamfEA(jamfBackgroundItems(items))
+			return nil
 		}
-		printBackgroundItems(items)
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(items)
+		}
+		if columns != nil {
+			return printColumns(os.Stdout, items, columns)
+		}
+		printBackgroundItems(items, *long)
 		return nil
+	case "ensure":
+		return runBackgroundEnsure(args[1:])
 	case "enable", "disable":
 		fs := flag.NewFlagSet("background enable/disable", flag.ContinueOnError)
-		label := fs.String("label", "", "launchd label")
-		scope := fs.String("scope", "user", "user|system")
-		if err := fs.Parse(args[1:]); err != nil {
+		label := labelFlag(fs, "label", "launchd label or glob pattern (e.g. 'com.adobe.*')")
+		scope := fs.String("scope", "", "user|system (default: inferred from plist location)")
+		yes := addYesFlags(fs, "skip confirmation when several labels match")
+		viaBrew := fs.Bool("brew", false, "delegate Homebrew-managed services to brew services start/stop")
+		vendor := fs.String("vendor", "", "match every item whose label names this vendor or whose program has this Team ID")
+		labelsFrom := fs.String("labels-from", "", "read labels from a file, one per line (- for stdin)")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
+		if err := applyUser(); err != nil {
+			return err
+		}
+		if *labelsFrom != "" {
+			labels, err := readLabelsFrom(*labelsFrom)
+			if err != nil {
+				return err
+			}
+			items, err := itemsForLabels(labels, *scope)
+			if err != nil {
+				return err
+			}
+			return runBackgroundVerbBulk(c, args[0], items, *yes, *viaBrew)
+		}
+		if *vendor != "" {
+			if *label != "" {
+				return usagef("use either --label or --vendor, not both")
+			}
+			items, err := matchVendorItems(c, *vendor, *scope)
+			if err != nil {
+				return err
+			}
+			return runBackgroundVerbBulk(c, args[0], items, *yes, *viaBrew)
+		}
 		if *label == "" {
-			return errors.New("--label is required")
+			return usagef("--label or --vendor is required")
+		}
+		if isLabelPattern(*label) {
+			items, err := matchBackgroundItems(c, *label, *scope)
+			if err != nil {
+				return err
+			}
+			return runBackgroundVerbBulk(c, args[0], items, *yes, *viaBrew)
 		}
-		domain, err := launchDomain(*scope)
+		resolved, err := inferScope(*label, *scope)
 		if err != nil {
 			return err
 		}
-		verb := args[0]
-		if err := runLaunchctl(verb, domain+"/"+*label); err != nil {
-			return err
+		item := BackgroundItem{Label: *label, Scope: resolved}
+		if matches, err := findBackgroundPlists(*label, resolved); err == nil && len(matches) > 0 {
+			item.Path = matches[0].Path
 		}
-		fmt.Printf("%sd %s in %s\n", verb, *label, domain)
-		return nil
+		return runBackgroundVerb(c, args[0], item, *viaBrew)
 	case "load":
 		fs := flag.NewFlagSet("background load", flag.ContinueOnError)
 		plist := fs.String("plist", "", "plist path")
 		scope := fs.String("scope", "user", "user|system")
-		if err := fs.Parse(args[1:]); err != nil {
+		skipValidate := fs.Bool("skip-validate", false, "load without validating the plist first")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if err := applyUser(); err != nil {
 			return err
 		}
 		if *plist == "" {
-			return errors.New("--plist is required")
+			return usagef("--plist is required")
 		}
-		domain, err := launchDomain(*scope)
+		domain, err := launchDomain(c, *scope)
 		if err != nil {
 			return err
 		}
-		if err := runLaunchctl("bootstrap", domain, *plist); err != nil {
+		if err := checkNotSIPProtected("", *plist); err != nil {
 			return err
 		}
-		fmt.Printf("loaded %s into %s\n", *plist, domain)
+		if !*skipValidate {
+			problems, err := validateBackgroundPlist(*plist, *scope)
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%s failed validation (use --skip-validate to override):\n  - %s", *plist, strings.Join(problems, "\n  - "))
+			}
+		}
+		err = c.LoadService(context.Background(), domain, *plist)
+		recordAction("background load", *plist, *scope, "", err)
+		if err != nil {
+			return err
+		}
+		successf("loaded %s into %s", *plist, domain)
 		return nil
+	case "validate":
+		fs := flag.NewFlagSet("background validate", flag.ContinueOnError)
+		plist := fs.String("plist", "", "plist path")
+		scope := fs.String("scope", "user", "user|system")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *plist == "" {
+			return usagef("--plist is required")
+		}
+		return runValidate(*plist, *scope)
 	case "unload":
 		fs := flag.NewFlagSet("background unload", flag.ContinueOnError)
-		label := fs.String("label", "", "launchd label")
+		label := labelFlag(fs, "label", "launchd label or glob pattern (e.g. 'com.adobe.*')")
+		plist := fs.String("plist", "", "plist path (label is read from it)")
+		scope := fs.String("scope", "", "user|system (default: inferred from plist location)")
+		yes := addYesFlags(fs, "skip confirmation when several labels match")
+		labelsFrom := fs.String("labels-from", "", "read labels from a file, one per line (- for stdin)")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if err := applyUser(); err != nil {
+			return err
+		}
+		if *labelsFrom != "" {
+			labels, err := readLabelsFrom(*labelsFrom)
+			if err != nil {
+				return err
+			}
+			items, err := itemsForLabels(labels, *scope)
+			if err != nil {
+				return err
+			}
+			return runBackgroundVerbBulk(c, "unload", items, *yes, false)
+		}
+		if *label == "" && *plist == "" {
+			return usagef("provide --label or --plist")
+		}
+		if isLabelPattern(*label) {
+			items, err := matchBackgroundItems(c, *label, *scope)
+			if err != nil {
+				return err
+			}
+			return runBackgroundVerbBulk(c, "unload", items, *yes, false)
+		}
+		item, err := resolveBackgroundTarget(*label, *plist, *scope)
+		if err != nil {
+			return err
+		}
+		return runBackgroundVerb(c, "unload", item, false)
+	case "reload":
+		fs := flag.NewFlagSet("background reload", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", defaultScope("all"), "user|system|all")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if err := applyUser(); err != nil {
+			return err
+		}
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		item, err := resolveBackgroundPlist(*label, *scope)
+		if err != nil {
+			return err
+		}
+		return reloadBackgroundPlist(c, item)
+	case "kickstart":
+		fs := flag.NewFlagSet("background kickstart", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", "", "user|system (default: inferred from plist location)")
+		force := fs.Bool("force", false, "kill the running instance before restarting")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if err := applyUser(); err != nil {
+			return err
+		}
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		resolved, err := inferScope(*label, *scope)
+		if err != nil {
+			return err
+		}
+		return kickstartBackgroundItem(c, *label, resolved, *force)
+	case "logs":
+		fs := flag.NewFlagSet("background logs", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", defaultScope("all"), "user|system|all")
+		lines := fs.Int("lines", 50, "number of lines to show")
+		follow := fs.Bool("follow", false, "keep streaming new output")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		return showBackgroundLogs(*label, *scope, *lines, *follow)
+	case "create":
+		fs := flag.NewFlagSet("background create", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		program := fs.String("program", "", "program to run")
+		interval := fs.Int("interval", 0, "run every N seconds")
+		runAtLoad := fs.Bool("run-at-load", false, "start when loaded")
+		logPath := fs.String("log", "", "file for stdout/stderr")
 		scope := fs.String("scope", "user", "user|system")
-		if err := fs.Parse(args[1:]); err != nil {
+		load := fs.Bool("load", false, "bootstrap after creating")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *label == "" || *program == "" {
+			return usagef("--label and --program are required")
+		}
+		spec := backgroundSpec{
+			label:     *label,
+			program:   *program,
+			args:      fs.Args(),
+			interval:  *interval,
+			runAtLoad: *runAtLoad,
+			logPath:   *logPath,
+		}
+		return createBackgroundItem(c, spec, *scope, *load)
+	case "edit":
+		fs := flag.NewFlagSet("background edit", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", defaultScope("all"), "user|system|all")
+		reload := fs.Bool("reload", false, "reload after saving without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		return editBackgroundItem(c, *label, *scope, *reload)
+	case "health":
+		fs := flag.NewFlagSet("background health", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "check a single launchd label")
+		scope := fs.String("scope", "", "user|system|all")
+		all := fs.Bool("all", false, "include healthy services")
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return runBackgroundHealth(c, *label, *scope, *all, *jsonOut)
+	case "drift":
+		fs := flag.NewFlagSet("background drift", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "check a single launchd label")
+		scope := fs.String("scope", "", "user|system|all")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return runBackgroundDrift(c, *label, *scope)
+	case "show":
+		fs := flag.NewFlagSet("background show", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		plist := fs.String("plist", "", "plist path")
+		scope := fs.String("scope", "", "user|system|all")
+		format := fs.String("format", "json", "json|yaml")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *label == "" && *plist == "" {
+			return usagef("provide --label or --plist")
+		}
+		return showBackgroundPlist(*label, *plist, *scope, *format)
+	case "env":
+		fs := flag.NewFlagSet("background env", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", "", "user|system|all")
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		return runBackgroundEnv(c, *label, *scope, *jsonOut)
+	case "info":
+		fs := flag.NewFlagSet("background info", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", "", "user|system|all")
+		jsonOut := fs.Bool("json", false, "output JSON")
+		hashes := fs.Bool("hashes", false, "compute the SHA-256 of the program")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		return runBackgroundInfo(c, *label, *scope, *jsonOut, *hashes)
+	case "why":
+		fs := flag.NewFlagSet("background why", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", "", "user|system|all")
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
 		if *label == "" {
-			return errors.New("--label is required")
+			return usagef("--label is required")
+		}
+		return runBackgroundWhy(c, *label, *scope, *jsonOut)
+	case "blame":
+		fs := flag.NewFlagSet("background blame", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "launchd label")
+		scope := fs.String("scope", "", "user|system|all")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
 		}
-		domain, err := launchDomain(*scope)
+		if *label == "" {
+			return usagef("--label is required")
+		}
+		resolved, err := inferScope(*label, *scope)
 		if err != nil {
 			return err
 		}
-		if err := runLaunchctl("bootout", domain+"/"+*label); err != nil {
+		item := BackgroundItem{Label: *label, Scope: resolved, Kind: "registered"}
+		if matches, err := findBackgroundPlists(*label, resolved); err == nil && len(matches) > 0 {
+			item = matches[0]
+		}
+		reason, err := blameLaunchService(c, item)
+		if err != nil {
 			return err
 		}
-		fmt.Printf("unloaded %s from %s\n", *label, domain)
+		fmt.Printf("%s: %s\n", item.Label, reason)
 		return nil
+	case "prune":
+		fs := flag.NewFlagSet("background prune", flag.ContinueOnError)
+		scope := fs.String("scope", defaultScope("all"), "user|system|all")
+		yes := fs.Bool("yes", false, "delete without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return pruneBackgroundItems(c, *scope, *yes)
 	case "delete", "remove":
 		fs := flag.NewFlagSet("background delete", flag.ContinueOnError)
-		label := fs.String("label", "", "launchd label")
+		label := labelFlag(fs, "label", "launchd label")
 		plist := fs.String("plist", "", "plist path")
-		scope := fs.String("scope", "user", "user|system")
-		if err := fs.Parse(args[1:]); err != nil {
+		scope := fs.String("scope", "", "user|system (default: inferred from plist location)")
+		permanent := fs.Bool("permanent", false, "remove the plist instead of moving it to the mlogin trash")
+		yes := addYesFlags(fs, "delete without asking")
+		applyUser := addTargetUserFlags(fs)
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if err := applyUser(); err != nil {
+			return err
+		}
+		if *label == "" && *plist == "" {
+			return usagef("provide --label or --plist")
+		}
+		item, err := resolveBackgroundTarget(*label, *plist, *scope)
+		if err != nil {
 			return err
 		}
-		if *label == "" || *plist == "" {
-			return errors.New("--label and --plist are required")
+		prompt := fmt.Sprintf("Delete %s (%s)?", item.Label, orDash(item.Path))
+		if *permanent {
+			prompt = fmt.Sprintf("Permanently delete %s (%s)? It will not go to the mlogin trash.", item.Label, orDash(item.Path))
+		}
+		if err := confirmAction(prompt, *yes); err != nil {
+			return err
 		}
-		return deleteBackgroundItem(*label, *plist, *scope)
+		return deleteBackgroundItem(c, item.Label, item.Path, item.Scope, *permanent)
+	case "restore":
+		fs := flag.NewFlagSet("background restore", flag.ContinueOnError)
+		label := labelFlag(fs, "label", "restore the latest deleted plist for this label")
+		load := fs.Bool("load", false, "bootstrap after restoring")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return restoreBackgroundItem(c, *label, *load)
 	default:
-		return fmt.Errorf("unknown background subcommand %q", args[0])
+		return usagef("unknown background subcommand %q", args[0])
 	}
 }
 
 func runExtensions(args []string) error {
+	c := client()
 	if len(args) == 0 {
-		return errors.New("missing extensions subcommand")
+		return usagef("missing extensions subcommand")
 	}
 	switch args[0] {
 	case "list":
 		fs := flag.NewFlagSet("extensions list", flag.ContinueOnError)
 		jsonOut := fs.Bool("json", false, "output JSON")
-		if err := fs.Parse(args[1:]); err != nil {
+		raw := fs.Bool("raw", false, "print systemextensionsctl output unparsed")
+		category := fs.String("category", "", "network|endpoint-security|driver")
+		columnList := fs.String("columns", "", "comma-separated fields to show in the table, e.g. bundle_id,state")
+		sortBy := fs.String("sort", "", "order by name|path|category|team|state|loaded, optionally with :desc")
+		filterQuery := fs.String("filter", "", "only list extensions whose category, team, bundle ID, name, or state contains this (or matches /regexp/)")
+		if err := parseFlags(fs, args[1:]); err != nil {
 			return err
 		}
-		items, err := listSystemExtensions()
+		filter, err := parseTextFilter(*filterQuery)
 		if err != nil {
 			return err
 		}
-		if *jsonOut {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(items)
+		if *sortBy != "" {
+			if _, _, err := extensionSortKeys.parse(*sortBy); err != nil {
+				return err
+			}
+		}
+		columns, err := parseColumns(*columnList, SystemExtensionItem{})
+		if err != nil {
+			return err
+		}
+		if *raw {
+			out, err := systemExtensionsOutput(c)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		}
+		items, err := listSystemExtensions(c)
+		if err != nil {
+			return err
+		}
+		if *category != "" {
+			full, err := extensionCategory(*category)
+			if err != nil {
+				return err
+			}
+			items = extensionsInCategory(items, full)
+		}
+		items = filterItems(items, filter, extensionFilterFields)
+		extensionSortKeys.sort(items, *sortBy)
+		if outputFormat == outputJamfEA {
+			printJamfEA(jamfSystemExtensions(items))
+			return nil
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(items)
+		}
+		if columns != nil {
+			return printColumns(os.Stdout, items, columns)
 		}
 		printSystemExtensions(items)
 		return nil
+	case "info":
+		fs := flag.NewFlagSet("extensions info", flag.ContinueOnError)
+		bundleID := labelFlag(fs, "bundle-id", "extension bundle identifier")
+		jsonOut := fs.Bool("json", false, "output JSON")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *bundleID == "" {
+			return usagef("--bundle-id is required")
+		}
+		return runExtensionsInfo(c, *bundleID, *jsonOut)
+	case "approve":
+		fs := flag.NewFlagSet("extensions approve", flag.ContinueOnError)
+		bundleID := labelFlag(fs, "bundle-id", "extension bundle identifier")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *bundleID == "" {
+			return usagef("--bundle-id is required")
+		}
+		return approveSystemExtension(c, *bundleID)
+	case "uninstall":
+		fs := flag.NewFlagSet("extensions uninstall", flag.ContinueOnError)
+		bundleID := labelFlag(fs, "bundle-id", "extension bundle identifier")
+		teamID := fs.String("team-id", "", "developer Team ID (looked up when omitted)")
+		yes := fs.Bool("yes", false, "uninstall without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if *bundleID == "" {
+			return usagef("--bundle-id is required")
+		}
+		return uninstallSystemExtension(c, *bundleID, *teamID, *yes)
+	case "developer-mode":
+		if len(args) != 2 {
+			return usagef("usage: mlogin extensions developer-mode on|off|status")
+		}
+		return setExtensionsDeveloperMode(c, args[1])
+	case "reset":
+		fs := flag.NewFlagSet("extensions reset", flag.ContinueOnError)
+		force := fs.Bool("force", false, "reset without asking")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return resetSystemExtensions(c, *force)
 	default:
-		return fmt.Errorf("unknown extensions subcommand %q", args[0])
+		return usagef("unknown extensions subcommand %q", args[0])
 	}
 }
 
-func deleteBackgroundItem(label, plistPath, scope string) error {
+// deleteBackgroundItem boots the service out and removes its plist. Unless
+// permanent is set, the plist is moved to the mlogin trash so it can be
+// brought back with `background restore`.
+func deleteBackgroundItem(c mlogin.Client, label, plistPath, scope string, permanent bool) error {
+	if err := checkNotSIPProtected(label, plistPath); err != nil {
+		return err
+	}
+	if plistPath == "" {
+		return fmt.Errorf("%s has no plist on disk; use unload instead", label)
+	}
 	absPath, err := filepath.Abs(plistPath)
 	if err != nil {
 		return err
 	}
-	domain, err := launchDomain(scope)
+	domain, err := launchDomain(c, scope)
 	if err != nil {
 		return err
 	}
 
 	// Attempt to stop the service first; if already stopped or not found, continue.
-	if err := runLaunchctl("bootout", domain+"/"+label); err != nil {
+	if err := c.UnloadService(context.Background(), domain+"/"+label); err != nil {
 		if !isIgnorableBootoutError(err) {
 			return fmt.Errorf("bootout failed for %s: %w", label, err)
 		}
 	}
 
+	if !permanent {
+		if dryRunf("move %s to the mlogin trash", absPath) {
+			return nil
+		}
+		backup, err := moveToTrash(label, scope, absPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			err = fmt.Errorf("move plist %s to trash: %w", absPath, err)
+			recordAction("background delete", label, scope, absPath, err)
+			return err
+		}
+		recordAction("background delete", label, scope, absPath+" (kept in "+backup+")", nil)
+		successf("deleted background item %s (%s, kept in %s)", label, absPath, backup)
+		return nil
+	}
+
+	if dryRunf("remove %s", absPath) {
+		return nil
+	}
 	if err := os.Remove(absPath); err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
-		return fmt.Errorf("remove plist %s: %w", absPath, err)
+		err = fmt.Errorf("remove plist %s: %w", absPath, err)
+		recordAction("background delete", label, scope, absPath, err)
+		return err
 	}
 
-	fmt.Printf("deleted background item %s (%s)\n", label, absPath)
+	recordAction("background delete", label, scope, absPath, nil)
+	successf("deleted background item %s (%s)", label, absPath)
+	return nil
+}
+
+func kickstartBackgroundItem(c mlogin.Client, label, scope string, force bool) error {
+	if err := checkNotSIPProtected(label, ""); err != nil {
+		return err
+	}
+	domain, err := launchDomain(c, scope)
+	if err != nil {
+		return err
+	}
+	pid, err := c.KickstartService(context.Background(), domain+"/"+label, force)
+	recordAction("background kickstart", domain+"/"+label, scope, "", err)
+	if err != nil {
+		return err
+	}
+	if pid > 0 {
+		successf("kickstarted %s in %s (pid %d)", label, domain, pid)
+		return nil
+	}
+	successf("kickstarted %s in %s", label, domain)
 	return nil
 }
 
@@ -327,253 +1024,288 @@ func isIgnorableBootoutError(err error) bool {
 		strings.Contains(msg, "domain does not support specified action")
 }
 
-func listLoginItems() ([]LoginItem, error) {
-	script := `
-ObjC.import('Cocoa');
-const se = Application('System Events');
-const items = se.loginItems();
-const out = items.map((item) => {
-  return {
-    name: item.name(),
-    path: item.path(),
-    hidden: item.hidden()
-  };
-});
-	$.NSFileHandle.fileHandleWithStandardOutput.writeData($(JSON.stringify(out) + "\n").dataUsingEncoding($.NSUTF8StringEncoding));
-`
-	stdout, stderr, err := runOSA(script, nil)
+func listLoginItems(c mlogin.Client) ([]LoginItem, error) {
+	stdout, stderr, err := runOSA(c, mlogin.LoginItemsScript, nil)
 	if err != nil {
 		return nil, fmt.Errorf("osascript login list failed: %w: %s", err, strings.TrimSpace(stderr))
 	}
-	var items []LoginItem
-	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout)), &items); err != nil {
-		return nil, fmt.Errorf("parse login items: %w", err)
-	}
-	sort.Slice(items, func(i, j int) bool {
-		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
-	})
-	return items, nil
+	return mlogin.ParseLoginItems(stdout)
 }
 
-func addLoginItem(path string, hidden bool) error {
+func addLoginItem(c mlogin.Client, path string, hidden bool) error {
 	abspath, err := filepath.Abs(path)
 	if err != nil {
 		return err
 	}
-	hiddenJS := "false"
-	if hidden {
-		hiddenJS = "true"
-	}
-	script := fmt.Sprintf(`
-const se = Application('System Events');
-const existing = se.loginItems.whose({path: %q})();
-for (const item of existing) {
-  item.delete();
-}
-se.loginItems.push(se.LoginItem({path: %q, hidden: %s}));
-`, abspath, abspath, hiddenJS)
-	_, stderr, err := runOSA(script, nil)
+	err = c.AddLoginItem(context.Background(), abspath, hidden)
 	if err != nil {
-		return fmt.Errorf("add login item failed: %w: %s", err, strings.TrimSpace(stderr))
+		err = fmt.Errorf("add login item failed: %w", err)
 	}
-	fmt.Printf("added login item: %s\n", abspath)
-	return nil
+	recordAction("login add", abspath, "", "", err)
+	return err
 }
 
-func removeLoginItem(name, path string) error {
-	script := `
-const se = Application('System Events');
-let removed = 0;
-const all = se.loginItems();
-for (const item of all) {
-  const matchesName = $.getenv('REMOVE_NAME') ? item.name() === $.getenv('REMOVE_NAME') : false;
-  const matchesPath = $.getenv('REMOVE_PATH') ? item.path() === $.getenv('REMOVE_PATH') : false;
-  if (matchesName || matchesPath) {
-    item.delete();
-    removed += 1;
-  }
-}
-if (removed === 0) {
-  throw new Error('no matching login item found');
-}
-`
-	env := map[string]string{}
-	if name != "" {
-		env["REMOVE_NAME"] = name
-	}
+func removeLoginItem(c mlogin.Client, name, path string) error {
+	target := name
 	if path != "" {
 		abspath, err := filepath.Abs(path)
 		if err != nil {
 			return err
 		}
-		env["REMOVE_PATH"] = abspath
+		path, target = abspath, abspath
 	}
-	_, stderr, err := runOSA(script, env)
+	err := c.RemoveLoginItem(context.Background(), name, path)
 	if err != nil {
-		return fmt.Errorf("remove login item failed: %w: %s", err, strings.TrimSpace(stderr))
+		err = fmt.Errorf("remove login item failed: %w", err)
 	}
-	fmt.Println("removed matching login items")
-	return nil
+	recordAction("login remove", target, "", "present", err)
+	return err
 }
 
-func listBackgroundItems(scope string) ([]BackgroundItem, []string, error) {
-	scope = strings.ToLower(scope)
-	if scope != "user" && scope != "system" && scope != "all" {
-		return nil, nil, errors.New("scope must be user, system, or all")
-	}
-
-	var dirs []struct {
-		scope string
-		kind  string
-		dir   string
-	}
-	if scope == "user" || scope == "all" {
-		home, err := os.UserHomeDir()
+// listBackgroundItems lists the launchd jobs for scope through the library,
+// for the targeted user, and adds the legacy StartupItems. With
+// includeApple, the read-only /System/Library directories are scanned too
+// and their items are marked with the "apple" provenance.
+func listBackgroundItems(c mlogin.Client, scope string, includeApple bool) ([]BackgroundItem, []string, error) {
+	opts := mlogin.ListOptions{Scope: scope, IncludeApple: includeApple}
+	if targetUser != nil {
+		domain, err := launchDomain(c, "user")
 		if err != nil {
 			return nil, nil, err
 		}
-		dirs = append(dirs, struct {
-			scope string
-			kind  string
-			dir   string
-		}{scope: "user", kind: "agent", dir: filepath.Join(home, "Library/LaunchAgents")})
-	}
-	if scope == "system" || scope == "all" {
-		dirs = append(dirs,
-			struct {
-				scope string
-				kind  string
-				dir   string
-			}{scope: "system", kind: "agent", dir: "/Library/LaunchAgents"},
-			struct {
-				scope string
-				kind  string
-				dir   string
-			}{scope: "system", kind: "daemon", dir: "/Library/LaunchDaemons"},
-		)
-	}
-
-	loadedUser := map[string]bool{}
-	if scope == "user" || scope == "all" {
-		labels, err := getLoadedUserLabels()
-		if err == nil {
-			loadedUser = labels
-		}
+		opts.Home, opts.UserDomain = targetUser.HomeDir, domain
 	}
-
-	disabledByScope := map[string]map[string]bool{}
-	warnings := []string{}
-	if scope == "user" || scope == "all" {
-		domain, err := launchDomain("user")
-		if err == nil {
-			m, err := getDisabledLabels(domain)
-			if err != nil {
-				warnings = append(warnings, "could not read user disabled state: "+err.Error())
-			} else {
-				disabledByScope["user"] = m
+	listed, warnings, err := c.ListBackgroundItems(context.Background(), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]BackgroundItem, 0, len(listed))
+	for _, it := range listed {
+		items = append(items, BackgroundItem{
+			Label:      it.Label,
+			Path:       it.Path,
+			Scope:      it.Scope,
+			Kind:       it.Kind,
+			Loaded:     it.Loaded,
+			Disabled:   it.Disabled,
+			Health:     it.Health,
+			Provenance: it.Provenance,
+		})
+	}
+	if scope = strings.ToLower(scope); scope == "system" || scope == "all" {
+		items = append(items, findStartupItems()...)
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Scope != items[j].Scope {
+				return items[i].Scope < items[j].Scope
 			}
+			return items[i].Label < items[j].Label
+		})
+	}
+	return items, warnings, nil
+}
+
+// checkNotSIPProtected refuses to act on plists that live on the sealed
+// system volume, or labels that belong to such plists.
+func checkNotSIPProtected(label, path string) error {
+	if path != "" {
+		if abs, err := filepath.Abs(path); err == nil && strings.HasPrefix(abs, "/System/") {
+			return sipError(label)
+		}
+	}
+	if label == "" {
+		return nil
+	}
+	for _, d := range mlogin.AppleLaunchDirs {
+		if _, err := os.Stat(filepath.Join(d.Dir, label+".plist")); err == nil {
+			return sipError(label)
 		}
 	}
-	if scope == "system" || scope == "all" {
-		m, err := getDisabledLabels("system")
+	return nil
+}
+
+func sipError(label string) error {
+	return fmt.Errorf("%s is a macOS system item in /System/Library, which is protected by System Integrity Protection (SIP); it cannot be changed, unloaded, or deleted", label)
+}
+
+// launchDirs returns the LaunchAgents/LaunchDaemons directories scanned for
+// the given scope (user, system, or all), for the targeted user.
+func launchDirs(scope string) ([]mlogin.LaunchDir, error) {
+	home := ""
+	if scope == "user" || scope == "all" {
+		h, err := launchUserHome()
 		if err != nil {
-			warnings = append(warnings, "could not read system disabled state (try sudo): "+err.Error())
-		} else {
-			disabledByScope["system"] = m
+			return nil, err
 		}
+		home = h
 	}
+	return mlogin.LaunchDirs(scope, home), nil
+}
 
-	var items []BackgroundItem
+// findBackgroundPlists scans the launchd directories for the scope and
+// returns every plist whose Label matches label.
+func findBackgroundPlists(label, scope string) ([]BackgroundItem, error) {
+	scope = strings.ToLower(scope)
+	if scope != "user" && scope != "system" && scope != "all" {
+		return nil, usagef("--scope must be user, system, or all")
+	}
+	dirs, err := launchDirs(scope)
+	if err != nil {
+		return nil, err
+	}
+	var matches []BackgroundItem
 	for _, d := range dirs {
-		entries, err := os.ReadDir(d.dir)
+		entries, err := os.ReadDir(d.Dir)
 		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
-			warnings = append(warnings, fmt.Sprintf("could not read %s: %v", d.dir, err))
 			continue
 		}
 		for _, e := range entries {
 			if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".plist") {
 				continue
 			}
-			p := filepath.Join(d.dir, e.Name())
-			label, err := readPlistLabel(p)
-			if err != nil || label == "" {
+			p := filepath.Join(d.Dir, e.Name())
+			l, err := readPlistLabel(p)
+			if err != nil || l != label {
 				continue
 			}
-			item := BackgroundItem{
-				Label:  label,
-				Path:   p,
-				Scope:  d.scope,
-				Kind:   d.kind,
-				Loaded: d.scope == "user" && loadedUser[label],
-			}
-			if m, ok := disabledByScope[d.scope]; ok {
-				if disabled, exists := m[label]; exists {
-					v := disabled
-					item.Disabled = &v
-				}
-			}
-			items = append(items, item)
+			matches = append(matches, BackgroundItem{Label: l, Path: p, Scope: d.Scope, Kind: d.Kind})
 		}
 	}
+	return matches, nil
+}
 
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].Scope != items[j].Scope {
-			return items[i].Scope < items[j].Scope
+// inferScope returns scope when it is set explicitly. Otherwise the scope is
+// taken from where the label's plist lives; labels without a plist default to
+// user, and labels found in both user and system directories are ambiguous.
+func inferScope(label, scope string) (string, error) {
+	if scope != "" {
+		return strings.ToLower(scope), nil
+	}
+	matches, err := findBackgroundPlists(label, "all")
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "user", nil
+	}
+	for _, m := range matches[1:] {
+		if m.Scope != matches[0].Scope {
+			return "", usagef("label %q exists in both user and system directories; pass --scope", label)
 		}
-		return items[i].Label < items[j].Label
-	})
-	return items, warnings, nil
+	}
+	return matches[0].Scope, nil
 }
 
-func listSystemExtensions() ([]SystemExtensionItem, error) {
-	cmd := exec.Command("systemextensionsctl", "list")
-	out, err := cmd.Output()
+// scopeForPlistPath maps a plist path to the scope of the launchd directory
+// containing it, or "" when it is outside the standard directories.
+func scopeForPlistPath(path string) string {
+	abs, err := filepath.Abs(path)
 	if err != nil {
-		return nil, err
+		return ""
+	}
+	dirs, err := launchDirs("all")
+	if err != nil {
+		return ""
 	}
+	for _, d := range dirs {
+		if filepath.Dir(abs) == d.Dir {
+			return d.Scope
+		}
+	}
+	return ""
+}
 
-	var items []SystemExtensionItem
-	currentCategory := ""
-	s := bufio.NewScanner(bytes.NewReader(out))
-	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-		if line == "" || strings.HasSuffix(line, "extension(s)") {
-			continue
+// resolveBackgroundTarget completes a label/plist pair when only one is
+// given: the label is read from the plist, or the plist is found by scanning
+// for the label (prompting when several match). The scope is inferred unless
+// set explicitly.
+func resolveBackgroundTarget(label, plist, scope string) (BackgroundItem, error) {
+	item := BackgroundItem{Label: label, Path: plist}
+	switch {
+	case plist != "" && label == "":
+		l, err := readPlistLabel(plist)
+		if err != nil {
+			return BackgroundItem{}, fmt.Errorf("read label from %s: %w", plist, err)
 		}
-		if strings.HasPrefix(line, "--- ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				currentCategory = parts[1]
-			}
-			continue
+		if l == "" {
+			return BackgroundItem{}, fmt.Errorf("%s has no Label", plist)
 		}
-		if strings.HasPrefix(line, "enabled") {
-			continue
+		item.Label = l
+	case plist == "" && label != "":
+		searchScope := scope
+		if searchScope == "" {
+			searchScope = "all"
+		}
+		matches, err := findBackgroundPlists(label, searchScope)
+		if err != nil {
+			return BackgroundItem{}, err
+		}
+		switch len(matches) {
+		case 0:
+			return BackgroundItem{}, notFoundf("no plist found for label %q; pass --plist", label)
+		case 1:
+			item = matches[0]
+		default:
+			options := make([]string, 0, len(matches))
+			for _, m := range matches {
+				options = append(options, fmt.Sprintf("%s (%s %s)", m.Path, m.Scope, m.Kind))
+			}
+			i, err := chooseOne(fmt.Sprintf("Label %q matches several plists:", label), options)
+			if err != nil {
+				return BackgroundItem{}, err
+			}
+			item = matches[i]
 		}
+	}
 
-		cols := splitTabColumns(line)
-		if len(cols) < 6 {
-			continue
+	if scope != "" {
+		item.Scope = strings.ToLower(scope)
+		return item, nil
+	}
+	if item.Scope == "" {
+		item.Scope = scopeForPlistPath(item.Path)
+	}
+	resolved, err := inferScope(item.Label, item.Scope)
+	if err != nil {
+		return BackgroundItem{}, err
+	}
+	item.Scope = resolved
+	return item, nil
+}
+
+// resolveBackgroundPlist finds the single plist for label, failing when it
+// is missing or present in more than one location.
+func resolveBackgroundPlist(label, scope string) (BackgroundItem, error) {
+	matches, err := findBackgroundPlists(label, scope)
+	if err != nil {
+		return BackgroundItem{}, err
+	}
+	switch len(matches) {
+	case 0:
+		return BackgroundItem{}, notFoundf("no plist found for label %q", label)
+	case 1:
+		return matches[0], nil
+	default:
+		paths := make([]string, 0, len(matches))
+		for _, m := range matches {
+			paths = append(paths, m.Path)
 		}
-		bundleID, version := parseBundleVersion(cols[3])
-		state := strings.Trim(cols[5], "[]")
-		items = append(items, SystemExtensionItem{
-			Category: currentCategory,
-			Enabled:  cols[0] == "*",
-			Active:   cols[1] == "*",
-			TeamID:   cols[2],
-			BundleID: bundleID,
-			Version:  version,
-			Name:     cols[4],
-			State:    state,
-		})
+		return BackgroundItem{}, fmt.Errorf("label %q is ambiguous, found in: %s (pass --scope)", label, strings.Join(paths, ", "))
 	}
-	if err := s.Err(); err != nil {
+}
+
+func listSystemExtensions(c mlogin.Client) ([]SystemExtensionItem, error) {
+	out, err := systemExtensionsOutput(c)
+	if err != nil {
 		return nil, err
 	}
+	items, err := mlogin.ParseSystemExtensions(out)
+	if errors.Is(err, mlogin.ErrUnrecognizedOutput) {
+		return nil, fmt.Errorf("%w; run `mlogin extensions list --raw` to see its output", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fillHostApps(items)
 	sort.Slice(items, func(i, j int) bool {
 		if items[i].Category != items[j].Category {
 			return items[i].Category < items[j].Category
@@ -583,130 +1315,150 @@ func listSystemExtensions() ([]SystemExtensionItem, error) {
 	return items, nil
 }
 
-func splitTabColumns(line string) []string {
-	raw := strings.Split(line, "\t")
-	out := make([]string, 0, len(raw))
-	for _, p := range raw {
-		p = strings.TrimSpace(p)
-		if p == "" {
-			continue
-		}
-		out = append(out, p)
-	}
-	return out
-}
-
-func parseBundleVersion(value string) (string, string) {
-	i := strings.LastIndex(value, " (")
-	if i == -1 || !strings.HasSuffix(value, ")") {
-		return value, ""
-	}
-	return value[:i], strings.TrimSuffix(strings.TrimPrefix(value[i+1:], "("), ")")
-}
-
 func readPlistLabel(path string) (string, error) {
-	cmd := exec.Command("/usr/libexec/PlistBuddy", "-c", "Print :Label", path)
+	cmd, done := toolCommand("/usr/libexec/PlistBuddy", "-c", "Print :Label", path)
 	out, err := cmd.Output()
-	if err != nil {
+	if err = done(err); err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(out)), nil
 }
 
-func getLoadedUserLabels() (map[string]bool, error) {
-	cmd := exec.Command("launchctl", "list")
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
+// getLoadedDomainLabels returns the services launchd reports for domain via
+// `launchctl print`.
+func getLoadedDomainLabels(c mlogin.Client, domain string) (map[string]mlogin.LaunchStatus, error) {
+	return c.LoadedServices(context.Background(), domain)
+}
+
+func getDisabledLabels(c mlogin.Client, domain string) (map[string]bool, error) {
+	return c.DisabledServices(context.Background(), domain)
+}
+
+// toggleService runs launchctl enable or disable, by verb, for target.
+func toggleService(c mlogin.Client, verb, target string) error {
+	if verb == "enable" {
+		return c.EnableService(context.Background(), target)
 	}
-	labels := map[string]bool{}
-	s := bufio.NewScanner(bytes.NewReader(out))
-	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-		if line == "" || strings.HasPrefix(line, "PID") {
-			continue
+	return c.DisableService(context.Background(), target)
+}
+
+// runLaunchctlOutput runs launchctl to read state and returns its stdout.
+func runLaunchctlOutput(c mlogin.Client, args ...string) (string, error) {
+	return c.Launchctl(context.Background(), args...)
+}
+
+// launchDomain maps a scope to its launchctl domain target:
+//
+//	user         gui/<uid>     the logged-in GUI session
+//	user-domain  user/<uid>    the per-user background domain
+//	loginwindow  login/<asid>  the login window's audit session
+//	system       system
+func launchDomain(c mlogin.Client, scope string) (string, error) {
+	switch strings.ToLower(scope) {
+	case "system":
+		return "system", nil
+	case "user", "user-domain":
+		u, err := launchUser()
+		if err != nil {
+			return "", err
 		}
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
-			continue
+		uid, err := strconv.Atoi(u.Uid)
+		if err != nil {
+			return "", err
+		}
+		if strings.ToLower(scope) == "user-domain" {
+			return fmt.Sprintf("user/%d", uid), nil
 		}
-		labels[parts[2]] = true
+		return fmt.Sprintf("gui/%d", uid), nil
+	case "loginwindow":
+		asid, err := loginWindowASID(c)
+		if err != nil {
+			return "", fmt.Errorf("resolve loginwindow session (try sudo): %w", err)
+		}
+		return fmt.Sprintf("login/%d", asid), nil
+	default:
+		return "", usagef("--scope must be user, user-domain, loginwindow, or system")
 	}
-	return labels, s.Err()
 }
 
-func getDisabledLabels(domain string) (map[string]bool, error) {
-	cmd := exec.Command("launchctl", "print-disabled", domain)
+// loginWindowASID returns the audit session ID of the loginwindow process,
+// which identifies the login/<asid> launchd domain.
+func loginWindowASID(c mlogin.Client) (int, error) {
+	cmd, done := toolCommand("pgrep", "-x", "loginwindow")
 	out, err := cmd.Output()
+	err = done(err)
 	if err != nil {
-		return nil, err
+		return 0, errors.New("loginwindow is not running")
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return 0, errors.New("loginwindow is not running")
+	}
+	info, err := runLaunchctlOutput(c, "procinfo", fields[0])
+	if err != nil {
+		return 0, err
+	}
+	asid, ok := parseProcinfoASID(info)
+	if !ok {
+		return 0, errors.New("no audit session id in launchctl procinfo output")
 	}
+	return asid, nil
+}
 
-	labels := map[string]bool{}
-	s := bufio.NewScanner(bytes.NewReader(out))
+// parseProcinfoASID finds the "asid = N" (or "audit session id = N") line in
+// `launchctl procinfo` output.
+func parseProcinfoASID(out string) (int, bool) {
+	s := bufio.NewScanner(strings.NewReader(out))
 	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-		if !strings.Contains(line, "=>") {
+		key, value, found := strings.Cut(s.Text(), "=")
+		if !found {
 			continue
 		}
-		parts := strings.Split(line, "=>")
-		if len(parts) != 2 {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "asid" && key != "audit session id" {
 			continue
 		}
-		label := strings.Trim(strings.TrimSpace(parts[0]), `"`)
-		state := strings.Trim(strings.TrimSpace(strings.TrimSuffix(parts[1], ";")), `"`)
-		if label == "" {
-			continue
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil {
+			return n, true
 		}
-		labels[label] = state == "disabled" || state == "true"
 	}
-	return labels, s.Err()
+	return 0, false
 }
 
-func runLaunchctl(args ...string) error {
-	cmd := exec.Command("launchctl", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		msg := strings.TrimSpace(stderr.String())
-		if msg != "" {
-			return fmt.Errorf("%w: %s", err, msg)
-		}
-		return err
+// stdinReader is shared so consecutive prompts don't lose buffered input.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stdin and reports whether it was answered yes.
+// Under --dry-run nothing will change, so it answers yes without asking.
+func confirm(prompt string) bool {
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "%s (y/n) y (dry run)\n", prompt)
+		return true
 	}
-	return nil
+	fmt.Fprintf(os.Stderr, "%s (y/n) ", prompt)
+	answer, _ := stdinReader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
-func launchDomain(scope string) (string, error) {
-	switch strings.ToLower(scope) {
-	case "system":
-		return "system", nil
-	case "user":
-		u, err := user.Current()
-		if err != nil {
-			return "", err
-		}
-		uid, err := strconv.Atoi(u.Uid)
-		if err != nil {
-			return "", err
-		}
-		return fmt.Sprintf("gui/%d", uid), nil
-	default:
-		return "", errors.New("scope must be user or system")
+// chooseOne prints numbered options and reads the user's pick from stdin.
+func chooseOne(prompt string, options []string) (int, error) {
+	fmt.Fprintln(os.Stderr, prompt)
+	for i, o := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, o)
 	}
+	fmt.Fprintf(os.Stderr, "Choose 1-%d: ", len(options))
+	answer, _ := stdinReader.ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(options) {
+		return 0, errors.New("no valid choice made")
+	}
+	return n - 1, nil
 }
 
-func runOSA(script string, env map[string]string) (string, string, error) {
-	cmd := exec.Command("osascript", "-l", "JavaScript", "-e", script)
-	cmd.Env = os.Environ()
-	for k, v := range env {
-		cmd.Env = append(cmd.Env, k+"="+v)
-	}
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	return stdout.String(), stderr.String(), err
+func runOSA(c mlogin.Client, script string, env map[string]string) (string, string, error) {
+	return runTool(c, mlogin.Command{Name: "osascript", Args: []string{"-l", "JavaScript", "-e", script}, Env: env})
 }
 
 func printLoginItems(items []LoginItem) {
@@ -714,25 +1466,76 @@ func printLoginItems(items []LoginItem) {
 		fmt.Println("No login items found")
 		return
 	}
-	fmt.Printf("%-32s %-6s %s\n", "NAME", "HIDDEN", "PATH")
+	fmt.Println(headerStyle.Render(fmt.Sprintf("%-32s %-6s %s", "NAME", "HIDDEN", "PATH")))
 	for _, it := range items {
 		fmt.Printf("%-32s %-6t %s\n", it.Name, it.Hidden, it.Path)
 	}
 }
 
-func printBackgroundItems(items []BackgroundItem) {
+func printBackgroundItems(items []BackgroundItem, long bool) {
 	if len(items) == 0 {
 		fmt.Println("No background items found")
 		return
 	}
-	fmt.Printf("%-8s %-7s %-7s %-8s %s\n", "SCOPE", "KIND", "LOADED", "DISABLE", "LABEL")
+	if long {
+		fmt.Println(headerStyle.Render(fmt.Sprintf("%-8s %-10s %-7s %-8s %-8s %-16s %s", "SCOPE", "KIND", "LOADED", "DISABLE", "HEALTH", "TRIGGER", "LABEL")))
+	} else {
+		fmt.Println(headerStyle.Render(fmt.Sprintf("%-8s %-10s %-7s %-8s %-8s %s", "SCOPE", "KIND", "LOADED", "DISABLE", "HEALTH", "LABEL")))
+	}
 	for _, it := range items {
 		disabled := "?"
 		if it.Disabled != nil {
 			disabled = fmt.Sprintf("%t", *it.Disabled)
 		}
-		fmt.Printf("%-8s %-7s %-7t %-8s %s\n", it.Scope, it.Kind, it.Loaded, disabled, it.Label)
+		label := it.Label
+		if it.Provenance != "" {
+			label += " (" + it.Provenance + ")"
+		}
+		if it.Managed {
+			label += " [managed]"
+		}
+		health := it.Health
+		if health == "" {
+			health = "-"
+		}
+		// Pad before styling: escape codes would throw off the widths.
+		disabled = fmt.Sprintf("%-8s", disabled)
+		if it.Disabled != nil && *it.Disabled {
+			disabled = disabledStyle.Render(disabled)
+		}
+		health = fmt.Sprintf("%-8s", health)
+		if it.Health == "failing" {
+			health = disabledStyle.Render(health)
+		}
+		if it.Risk >= suspiciousRisk {
+			label = suspiciousStyle.Render(label)
+		}
+		if long {
+			trigger := it.Trigger
+			if trigger == "" {
+				trigger = "-"
+			}
+			fmt.Printf("%-8s %-10s %-7t %s %s %-16s %s\n", it.Scope, it.Kind, it.Loaded, disabled, health, trigger, label)
+		} else {
+			fmt.Printf("%-8s %-10s %-7t %s %s %s\n", it.Scope, it.Kind, it.Loaded, disabled, health, label)
+		}
+		if it.Path == "" {
+			fmt.Println("  (no plist on disk)")
+			continue
+		}
 		fmt.Printf("  %s\n", it.Path)
+		if long && it.Signature != nil {
+			fmt.Printf("  %s\n", describeSignature(*it.Signature))
+		}
+		if it.Verification != nil {
+			fmt.Printf("  %s\n", describeVerification(*it.Verification))
+		}
+		if it.SHA256 != "" {
+			fmt.Printf("  sha256: %s\n", it.SHA256)
+		}
+		if it.Risk > 0 {
+			fmt.Printf("  %s\n", riskStyle(it.Risk).Render(fmt.Sprintf("risk: %d (%s)", it.Risk, strings.Join(it.RiskReasons, ", "))))
+		}
 	}
 }
 
@@ -741,8 +1544,21 @@ func printSystemExtensions(items []SystemExtensionItem) {
 		fmt.Println("No system extensions found")
 		return
 	}
-	fmt.Printf("%-43s %-7s %-6s %-10s %-38s %s\n", "CATEGORY", "ENABLED", "ACTIVE", "TEAMID", "BUNDLEID", "NAME")
+	fmt.Println(headerStyle.Render(fmt.Sprintf("%-43s %-7s %-6s %-10s %-38s %s", "CATEGORY", "ENABLED", "ACTIVE", "TEAMID", "BUNDLEID", "NAME")))
 	for _, it := range items {
-		fmt.Printf("%-43s %-7t %-6t %-10s %-38s %s\n", it.Category, it.Enabled, it.Active, it.TeamID, it.BundleID, it.Name)
+		enabled := fmt.Sprintf("%-7t", it.Enabled)
+		if !it.Enabled {
+			enabled = disabledStyle.Render(enabled)
+		}
+		fmt.Printf("%-43s %s %-6t %-10s %-38s %s\n", it.Category, enabled, it.Active, it.TeamID, it.BundleID, it.Name)
+		switch {
+		case it.HostApp == "":
+		case it.HostAppExists != nil && !*it.HostAppExists:
+			fmt.Printf("  app: %s %s\n", it.HostApp, cautionStyle.Render("(missing)"))
+		case it.HostAppVersion != "":
+			fmt.Printf("  app: %s (%s)\n", it.HostApp, it.HostAppVersion)
+		default:
+			fmt.Printf("  app: %s\n", it.HostApp)
+		}
 	}
 }
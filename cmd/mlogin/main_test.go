@@ -2,7 +2,13 @@ package main
 
 import (
 	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"golang.org/x/term"
 )
 
 func TestIsIgnorableBootoutError(t *testing.T) {
@@ -24,23 +30,167 @@ func TestIsIgnorableBootoutError(t *testing.T) {
 	}
 }
 
-func TestParseBundleVersion(t *testing.T) {
-	bundle, version := parseBundleVersion("io.tailscale.ipn.macsys.network-extension (1.94.1/101.94.1)")
-	if bundle != "io.tailscale.ipn.macsys.network-extension" {
-		t.Fatalf("unexpected bundle: %q", bundle)
+func TestScopeForPlistPath(t *testing.T) {
+	t.Setenv("HOME", "/Users/test")
+	cases := map[string]string{
+		"/Users/test/Library/LaunchAgents/com.foo.plist": "user",
+		"/Library/LaunchDaemons/com.foo.plist":           "system",
+		"/Library/LaunchAgents/com.foo.plist":            "system",
+		"/tmp/com.foo.plist":                             "",
+	}
+	for path, want := range cases {
+		if got := scopeForPlistPath(path); got != want {
+			t.Fatalf("scopeForPlistPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestParseProcinfoASID(t *testing.T) {
+	out := "program path = /System/Library/CoreServices/loginwindow.app/Contents/MacOS/loginwindow\n\tasid = 100004\n"
+	asid, ok := parseProcinfoASID(out)
+	if !ok || asid != 100004 {
+		t.Fatalf("parseProcinfoASID = %d, %v", asid, ok)
+	}
+	if _, ok := parseProcinfoASID("pid = 12\n"); ok {
+		t.Fatalf("expected no asid")
+	}
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	defer func() { escalation = escalateSudo }()
+	args := parseGlobalFlags([]string{"--no-sudo", "background", "list"})
+	if len(args) != 2 || args[0] != "background" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	if escalation != escalateNone {
+		t.Fatalf("expected escalation to be disabled")
+	}
+
+	escalation = escalateSudo
+	args = parseGlobalFlags([]string{"background", "create", "--label", "x", "--", "/bin/prog", "--no-sudo"})
+	if len(args) != 7 || args[6] != "--no-sudo" || escalation != escalateSudo {
+		t.Fatalf("--no-sudo after -- should be left to the program: %v", args)
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"/bin/launchctl", "disable", "system/it's"})
+	want := `'/bin/launchctl' 'disable' 'system/it'\''s'`
+	if got != want {
+		t.Fatalf("shellJoin = %s, want %s", got, want)
+	}
+}
+
+func TestParseGlobalFlagsHost(t *testing.T) {
+	defer func() { remoteHost, remoteMlogin = "", "mlogin" }()
+	args := parseGlobalFlags([]string{"--host", "admin@mini", "background", "create", "--remote-mlogin=/opt/bin/mlogin", "--", "--host", "x"})
+	if remoteHost != "admin@mini" || remoteMlogin != "/opt/bin/mlogin" {
+		t.Fatalf("unexpected remote settings: %q %q", remoteHost, remoteMlogin)
+	}
+	want := []string{"background", "create", "--", "--host", "x"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Fatalf("got %v, want %v", args, want)
+	}
+}
+
+func TestParseGlobalFlagsOutput(t *testing.T) {
+	defer func() { outputFormat = "" }()
+	args := parseGlobalFlags([]string{"background", "list", "-o", "csv"})
+	if outputFormat != "csv" || strings.Join(args, " ") != "background list" {
+		t.Fatalf("unexpected output %q and args %v", outputFormat, args)
+	}
+	parseGlobalFlags([]string{"--output=yaml", "audit"})
+	if outputFormat != "yaml" {
+		t.Fatalf("unexpected output %q", outputFormat)
+	}
+}
+
+func TestRemoteCommand(t *testing.T) {
+	got := remoteCommand("admin@mini", "mlogin", []string{"background", "list", "--json"}, true)
+	want := []string{"-t", "admin@mini", "--", `'mlogin' 'background' 'list' '--json'`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestIsPagedCommand(t *testing.T) {
+	for _, args := range [][]string{{"background", "list", "--long"}, {"audit"}, {"ext", "list"}} {
+		if !isPagedCommand(args) {
+			t.Fatalf("expected %v to be paged", args)
+		}
+	}
+	for _, args := range [][]string{{"background", "disable", "--label", "x"}, {"tui"}, {"login"}} {
+		if isPagedCommand(args) {
+			t.Fatalf("expected %v not to be paged", args)
+		}
+	}
+}
+
+func TestAddYesFlagsForce(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	yes := addYesFlags(fs, "skip confirmation")
+	if err := fs.Parse([]string{"--force"}); err != nil {
+		t.Fatal(err)
+	}
+	if !*yes {
+		t.Fatal("--force did not set --yes")
+	}
+	if err := confirmAction("Delete everything?", *yes); err != nil {
+		t.Fatalf("confirmAction with --yes: %v", err)
+	}
+}
+
+func TestConfigCannotSkipResetConfirmation(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	off := false
+	config.Confirm = &off
+	if err := confirmAction("Delete it?", false); err != nil {
+		t.Fatalf("confirm: false still asked: %v", err)
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("stdin is a terminal; confirmReset would prompt")
+	}
+	if err := confirmReset("Reset everything?", false); !errors.Is(err, errNotInteractive) {
+		t.Fatalf("confirmReset without a terminal = %v, want %v", err, errNotInteractive)
+	}
+	if err := confirmReset("Reset everything?", true); err != nil {
+		t.Fatalf("confirmReset with --yes: %v", err)
+	}
+}
+
+// withConfigFile points MLOGIN_CONFIG at a file holding content and
+// restores everything loading it may change once the test ends.
+func withConfigFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MLOGIN_CONFIG", path)
+	saved, savedOutput, savedNoColor := config, outputFormat, noColor
+	t.Cleanup(func() { config, outputFormat, noColor = saved, savedOutput, savedNoColor })
+}
+
+func TestOutputTableOverridesConfig(t *testing.T) {
+	withConfigFile(t, "output: json\ncolor: false\n")
+	if err := run([]string{"-o", "table", "version"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if outputFormat != "" {
+		t.Fatalf("-o table left output %q", outputFormat)
 	}
-	if version != "1.94.1/101.94.1" {
-		t.Fatalf("unexpected version: %q", version)
+	if !noColor {
+		t.Fatal("config was not loaded")
 	}
 }
 
-func TestSplitTabColumns(t *testing.T) {
-	line := "*\t*\tW5364U7YZB\tio.tailscale.ipn.macsys.network-extension (1.94.1/101.94.1)\tTailscale Network Extension\t[activated enabled]"
-	cols := splitTabColumns(line)
-	if len(cols) != 6 {
-		t.Fatalf("expected 6 cols, got %d (%v)", len(cols), cols)
+func TestInvalidConfigIsIgnored(t *testing.T) {
+	withConfigFile(t, "outptu: json\n")
+	if err := run([]string{"version"}); err != nil {
+		t.Fatalf("run with an invalid config: %v", err)
 	}
-	if cols[2] != "W5364U7YZB" {
-		t.Fatalf("unexpected team id column: %q", cols[2])
+	if outputFormat != "" {
+		t.Fatalf("invalid config set output %q", outputFormat)
 	}
 }
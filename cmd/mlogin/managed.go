@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// serviceManagementPayload is the configuration profile payload MDM uses to
+// mark background items as managed so users cannot turn them off.
+const serviceManagementPayload = "com.apple.servicemanagement"
+
+// managedRule is one entry of a service management payload's Rules array.
+type managedRule struct {
+	Type    string
+	Value   string
+	Comment string
+}
+
+// loadManagedRules reads installed configuration profiles and returns the
+// service management rules they carry. No profiles means no rules.
+func loadManagedRules() ([]managedRule, error) {
+	m, err := showProfiles()
+	if err != nil || m == nil {
+		return nil, err
+	}
+	return parseManagedRules(m), nil
+}
+
+// managedRules caches loadManagedRules so bulk actions only shell out once.
+var managedRules = sync.OnceValues(loadManagedRules)
+
+// parseManagedRules walks the decoded `profiles show` output and collects
+// the Rules of every service management payload, whichever level (computer
+// or user) the profile was installed at.
+func parseManagedRules(v any) []managedRule {
+	var rules []managedRule
+	switch v := v.(type) {
+	case map[string]any:
+		if plistString(v, "PayloadType") == serviceManagementPayload {
+			content, _ := v["PayloadContent"].(map[string]any)
+			if content == nil {
+				content = v
+			}
+			raw, _ := content["Rules"].([]any)
+			for _, r := range raw {
+				rm, ok := r.(map[string]any)
+				if !ok {
+					continue
+				}
+				rules = append(rules, managedRule{
+					Type:    plistString(rm, "RuleType"),
+					Value:   plistString(rm, "RuleValue"),
+					Comment: plistString(rm, "Comment"),
+				})
+			}
+			return rules
+		}
+		for _, e := range v {
+			rules = append(rules, parseManagedRules(e)...)
+		}
+	case []any:
+		for _, e := range v {
+			rules = append(rules, parseManagedRules(e)...)
+		}
+	}
+	return rules
+}
+
+// matchManagedRule returns the first rule that covers item. Label rules are
+// checked against the label; bundle and team rules need the program's code
+// signature, which is only read when such a rule exists.
+func matchManagedRule(rules []managedRule, item BackgroundItem) (managedRule, bool) {
+	var sig map[string]string
+	signature := func() map[string]string {
+		if sig != nil {
+			return sig
+		}
+		sig = map[string]string{}
+		if item.Path == "" {
+			return sig
+		}
+		plist, err := readPlist(item.Path)
+		if err != nil {
+			return sig
+		}
+		if info, err := codesignInfo(plistProgram(plist)); err == nil {
+			sig = info
+		}
+		return sig
+	}
+	for _, r := range rules {
+		if r.Value == "" {
+			continue
+		}
+		var ok bool
+		switch r.Type {
+		case "Label":
+			ok = item.Label == r.Value
+		case "LabelPrefix":
+			ok = strings.HasPrefix(item.Label, r.Value)
+		case "BundleIdentifier":
+			ok = signature()["Identifier"] == r.Value
+		case "BundleIdentifierPrefix":
+			ok = strings.HasPrefix(signature()["Identifier"], r.Value)
+		case "TeamIdentifier":
+			ok = signature()["TeamIdentifier"] == r.Value
+		}
+		if ok {
+			return r, true
+		}
+	}
+	return managedRule{}, false
+}
+
+// fillManaged marks items covered by an installed service management
+// profile. A `profiles` that needs root is not an error: without it there
+// is nothing to mark.
+func fillManaged(items []BackgroundItem) error {
+	rules, err := managedRules()
+	if err != nil {
+		if isPermissionError(err) {
+			return nil
+		}
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	for i := range items {
+		if _, ok := matchManagedRule(rules, items[i]); ok {
+			items[i].Managed = true
+		}
+	}
+	return nil
+}
+
+// managedWarning explains why disabling item will not stick, or returns ""
+// when no installed profile covers it.
+func managedWarning(item BackgroundItem) string {
+	rules, err := managedRules()
+	if err != nil {
+		return ""
+	}
+	r, ok := matchManagedRule(rules, item)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s is managed by an MDM profile (%s %s); it will be re-enabled", item.Label, r.Type, r.Value)
+}
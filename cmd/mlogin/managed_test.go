@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseManagedRules(t *testing.T) {
+	out := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>_computerlevel</key>
+	<array>
+		<dict>
+			<key>ProfileIdentifier</key>
+			<string>com.example.mdm</string>
+			<key>ProfileItems</key>
+			<array>
+				<dict>
+					<key>PayloadType</key>
+					<string>com.apple.servicemanagement</string>
+					<key>PayloadContent</key>
+					<dict>
+						<key>Rules</key>
+						<array>
+							<dict>
+								<key>RuleType</key>
+								<string>LabelPrefix</string>
+								<key>RuleValue</key>
+								<string>com.example.</string>
+								<key>Comment</key>
+								<string>Example agents</string>
+							</dict>
+						</array>
+					</dict>
+				</dict>
+				<dict>
+					<key>PayloadType</key>
+					<string>com.apple.dock</string>
+				</dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`)
+	m, err := decodePlistXML(out)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	rules := parseManagedRules(m)
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+	if rules[0].Type != "LabelPrefix" || rules[0].Value != "com.example." || rules[0].Comment != "Example agents" {
+		t.Fatalf("unexpected rule: %+v", rules[0])
+	}
+}
+
+func TestMatchManagedRule(t *testing.T) {
+	rules := []managedRule{
+		{Type: "Label", Value: "com.corp.agent"},
+		{Type: "LabelPrefix", Value: "com.example."},
+	}
+	tests := []struct {
+		label string
+		want  bool
+	}{
+		{"com.corp.agent", true},
+		{"com.corp.agent.helper", false},
+		{"com.example.updater", true},
+		{"com.other.agent", false},
+	}
+	for _, tt := range tests {
+		_, got := matchManagedRule(rules, BackgroundItem{Label: tt.label})
+		if got != tt.want {
+			t.Fatalf("matchManagedRule(%q) = %t, want %t", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestFillManagedIgnoresRootOnlyProfiles(t *testing.T) {
+	saved := managedRules
+	defer func() { managedRules = saved }()
+	managedRules = func() ([]managedRule, error) {
+		return nil, errors.New("profiles show: exit status 1: this command requires root")
+	}
+	if err := fillManaged([]BackgroundItem{{Label: "com.example.agent"}}); err != nil {
+		t.Fatalf("fillManaged: %v", err)
+	}
+	managedRules = func() ([]managedRule, error) {
+		return nil, errors.New("profiles show: exit status 1: parse error")
+	}
+	if err := fillManaged(nil); err == nil {
+		t.Fatalf("expected other profiles errors to surface")
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// mcpProtocolVersions are the Model Context Protocol revisions this server
+// speaks, newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+type mcpRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool is one tool offered to the assistant. Tools that change state
+// are only run after the user approves them in a dialog, independently of
+// whatever the assistant's client asks.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	Annotations map[string]any `json:"annotations,omitempty"`
+	mutates     bool
+	call        func(args map[string]any) (any, error)
+}
+
+type mcpServer struct {
+	tools   []mcpTool
+	confirm func(prompt string) bool
+}
+
+func runMCP(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	readOnly := fs.Bool("read-only", false, "only offer the tools that inspect state")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	// stdout carries the protocol, so anything the shared helpers print
+	// goes to stderr instead.
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	srv := &mcpServer{tools: mcpTools(c, *readOnly), confirm: func(prompt string) bool { return confirmDialog(c, prompt) }}
+	return srv.serve(os.Stdin, out)
+}
+
+func (s *mcpServer) serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req mcpRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := enc.Encode(mcpResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &mcpError{Code: -32700, Message: "parse error"}}); err != nil {
+				return err
+			}
+			continue
+		}
+		// Requests without an id are notifications and get no reply.
+		if len(req.ID) == 0 {
+			continue
+		}
+		result, rpcErr := s.handle(req)
+		if err := enc.Encode(mcpResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *mcpServer) handle(req mcpRequest) (any, *mcpError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		protocol := mcpProtocolVersions[0]
+		if slices.Contains(mcpProtocolVersions, params.ProtocolVersion) {
+			protocol = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": protocol,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "mlogin", "version": version},
+			"instructions":    "Inspect and manage macOS login items, launchd agents and daemons, and other autostart surfaces. Changes are confirmed by the user in a dialog.",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &mcpError{Code: -32602, Message: "invalid params"}
+		}
+		i := slices.IndexFunc(s.tools, func(t mcpTool) bool { return t.Name == params.Name })
+		if i < 0 {
+			return nil, &mcpError{Code: -32602, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+		}
+		return s.callTool(s.tools[i], params.Arguments), nil
+	default:
+		return nil, &mcpError{Code: -32601, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
+
+// callTool runs t and wraps its outcome as a tool result; failures are
+// reported to the assistant as tool errors, not protocol errors.
+func (s *mcpServer) callTool(t mcpTool, args map[string]any) map[string]any {
+	text := func(v string, isError bool) map[string]any {
+		return map[string]any{"content": []map[string]any{{"type": "text", "text": v}}, "isError": isError}
+	}
+	if t.mutates {
+		data, _ := json.Marshal(args)
+		if !s.confirm(fmt.Sprintf("An assistant wants to run %s %s. Allow?", t.Name, data)) {
+			return text("the user declined "+t.Name, true)
+		}
+	}
+	v, err := t.call(args)
+	if err != nil {
+		return text(err.Error(), true)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return text(err.Error(), true)
+	}
+	return text(string(data), false)
+}
+
+func mcpTools(c mlogin.Client, readOnly bool) []mcpTool {
+	noArgs := map[string]any{"type": "object", "properties": map[string]any{}}
+	scopeArg := map[string]any{"type": "string", "enum": []string{"user", "system", "all"}}
+	labelArgs := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"label": map[string]any{"type": "string", "description": "launchd label"},
+			"scope": map[string]any{"type": "string", "enum": []string{"user", "system"}, "description": "default: inferred from the plist location"},
+		},
+		"required": []string{"label"},
+	}
+	readHint := map[string]any{"readOnlyHint": true}
+	tools := []mcpTool{
+		{
+			Name:        "list_login_items",
+			Description: "List the apps that open at login.",
+			InputSchema: noArgs,
+			Annotations: readHint,
+			call: func(map[string]any) (any, error) {
+				return listLoginItems(c)
+			},
+		},
+		{
+			Name:        "list_background_items",
+			Description: "List launchd agents and daemons with their loaded and disabled state. Apple's own jobs are left out.",
+			InputSchema: map[string]any{"type": "object", "properties": map[string]any{"scope": scopeArg}},
+			Annotations: readHint,
+			call: func(args map[string]any) (any, error) {
+				scope, _ := args["scope"].(string)
+				if scope == "" {
+					scope = "all"
+				}
+				items, _, err := listBackgroundItems(c, scope, false)
+				return items, err
+			},
+		},
+		{
+			Name:        "list_system_extensions",
+			Description: "List installed system extensions.",
+			InputSchema: noArgs,
+			Annotations: readHint,
+			call: func(map[string]any) (any, error) {
+				return listSystemExtensions(c)
+			},
+		},
+		{
+			Name:        "audit",
+			Description: "Report every autostart surface (login items, launchd, BTM, cron, extensions, and more) with a risk score per entry.",
+			InputSchema: map[string]any{"type": "object", "properties": map[string]any{"suspicious_only": map[string]any{"type": "boolean"}}},
+			Annotations: readHint,
+			call: func(args map[string]any) (any, error) {
+				report := runAuditProviders(c, auditProviders)
+				if only, _ := args["suspicious_only"].(bool); only {
+					for i := range report {
+						report[i].Entries = suspiciousEntries(report[i].Entries)
+					}
+				}
+				return report, nil
+			},
+		},
+	}
+	if readOnly {
+		return tools
+	}
+	for _, verb := range []string{"enable", "disable"} {
+		tools = append(tools, mcpTool{
+			Name:        verb + "_service",
+			Description: fmt.Sprintf("%s a launchd agent or daemon so it does (not) start at boot or login. The user is asked to confirm.", strings.ToUpper(verb[:1])+verb[1:]),
+			InputSchema: labelArgs,
+			Annotations: map[string]any{"destructiveHint": verb == "disable", "idempotentHint": true},
+			mutates:     true,
+			call: func(args map[string]any) (any, error) {
+				label, _ := args["label"].(string)
+				scope, _ := args["scope"].(string)
+				if label == "" {
+					return nil, errors.New("label is required")
+				}
+				items, err := itemsForLabels([]string{label}, scope)
+				if err != nil {
+					return nil, err
+				}
+				if err := runBackgroundVerb(c, verb, items[0], false); err != nil {
+					return nil, err
+				}
+				return map[string]string{"label": items[0].Label, "scope": items[0].Scope, "action": verb}, nil
+			},
+		})
+	}
+	return tools
+}
+
+// confirmDialog asks the user at the Mac itself, so an assistant cannot
+// approve its own changes.
+func confirmDialog(c mlogin.Client, prompt string) bool {
+	script := `
+ObjC.import('stdlib');
+const app = Application.currentApplication();
+app.includeStandardAdditions = true;
+app.displayDialog($.getenv('CONFIRM_PROMPT'), { withTitle: 'mlogin', buttons: ['Deny', 'Allow'], defaultButton: 'Deny', cancelButton: 'Deny', withIcon: 'caution' });
+`
+	_, _, err := runOSA(c, script, map[string]string{"CONFIRM_PROMPT": prompt})
+	return err == nil
+}
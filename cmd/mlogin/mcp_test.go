@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMCPServer(t *testing.T) {
+	called := false
+	srv := &mcpServer{
+		tools: []mcpTool{
+			{Name: "echo", InputSchema: map[string]any{"type": "object"}, call: func(args map[string]any) (any, error) {
+				return args, nil
+			}},
+			{Name: "disable_service", InputSchema: map[string]any{"type": "object"}, mutates: true, call: func(map[string]any) (any, error) {
+				called = true
+				return nil, nil
+			}},
+		},
+		confirm: func(string) bool { return false },
+	}
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"x":"y"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"disable_service","arguments":{"label":"com.foo"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"nope"}`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := srv.serve(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	var responses []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, r)
+	}
+	if len(responses) != 5 {
+		t.Fatalf("expected 5 responses (none for the notification), got %d", len(responses))
+	}
+	if v := responses[0]["result"].(map[string]any)["protocolVersion"]; v != "2024-11-05" {
+		t.Fatalf("expected the client's protocol version, got %v", v)
+	}
+	if tools := responses[1]["result"].(map[string]any)["tools"].([]any); len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %v", tools)
+	}
+	echo := responses[2]["result"].(map[string]any)
+	if echo["isError"] != false || !strings.Contains(echo["content"].([]any)[0].(map[string]any)["text"].(string), `"x": "y"`) {
+		t.Fatalf("unexpected echo result: %v", echo)
+	}
+	if declined := responses[3]["result"].(map[string]any); declined["isError"] != true || called {
+		t.Fatalf("expected a declined mutation to be skipped, got %v (called %t)", declined, called)
+	}
+	if responses[4]["error"].(map[string]any)["code"].(float64) != -32601 {
+		t.Fatalf("expected method not found, got %v", responses[4])
+	}
+}
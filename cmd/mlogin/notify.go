@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// notificationTitle phrases an event the way a user thinks about it, for
+// example "New LaunchAgent installed".
+func notificationTitle(e WatchEvent) string {
+	noun := "autostart item"
+	switch e.Surface {
+	case "launchd":
+		switch e.Details["kind"] {
+		case "agent":
+			noun = "LaunchAgent"
+		case "daemon":
+			noun = "LaunchDaemon"
+		default:
+			noun = "launchd job"
+		}
+	case "login items":
+		noun = "login item"
+	case "background task management":
+		noun = "background item"
+	}
+	switch e.Change {
+	case "added":
+		if noun == "LaunchAgent" || noun == "LaunchDaemon" {
+			return "New " + noun + " installed"
+		}
+		return "New " + noun + " added"
+	case "removed":
+		return upperFirst(noun) + " removed"
+	default:
+		return upperFirst(noun) + " changed"
+	}
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// notifyWatchEvent posts a Notification Center banner for e.
+func notifyWatchEvent(c mlogin.Client, e WatchEvent) error {
+	script := `
+ObjC.import('stdlib');
+const app = Application.currentApplication();
+app.includeStandardAdditions = true;
+app.displayNotification($.getenv('NOTIFY_BODY'), { withTitle: 'mlogin', subtitle: $.getenv('NOTIFY_TITLE') });
+`
+	env := map[string]string{"NOTIFY_TITLE": notificationTitle(e), "NOTIFY_BODY": e.Name}
+	_, stderr, err := runOSA(c, script, env)
+	if err != nil {
+		return fmt.Errorf("post notification failed: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	return nil
+}
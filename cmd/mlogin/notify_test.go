@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestNotificationTitle(t *testing.T) {
+	tests := []struct {
+		event WatchEvent
+		want  string
+	}{
+		{WatchEvent{Surface: "launchd", Change: "added", Details: map[string]string{"kind": "agent"}}, "New LaunchAgent installed"},
+		{WatchEvent{Surface: "launchd", Change: "removed", Details: map[string]string{"kind": "daemon"}}, "LaunchDaemon removed"},
+		{WatchEvent{Surface: "login items", Change: "added"}, "New login item added"},
+		{WatchEvent{Surface: "background task management", Change: "modified"}, "Background item changed"},
+	}
+	for _, tt := range tests {
+		if got := notificationTitle(tt.event); got != tt.want {
+			t.Fatalf("notificationTitle(%+v) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// settingsPanes are the System Settings panes `mlogin open settings` links
+// to.
+var settingsPanes = map[string]string{
+	"login-items":      "x-apple.systempreferences:com.apple.LoginItems-Settings.extension",
+	"extensions":       "x-apple.systempreferences:com.apple.ExtensionsPreferences",
+	"security":         securitySettingsURL,
+	"full-disk-access": "x-apple.systempreferences:com.apple.preference.security?Privacy_AllFiles",
+	"automation":       "x-apple.systempreferences:com.apple.preference.security?Privacy_Automation",
+}
+
+func runOpen(args []string) error {
+	c := client()
+	if len(args) > 0 && args[0] == "settings" {
+		if len(args) != 2 {
+			return usagef("usage: mlogin open settings %s", strings.Join(sortedKeys(settingsPanes), "|"))
+		}
+		url, ok := settingsPanes[args[1]]
+		if !ok {
+			msg := fmt.Sprintf("unknown settings pane %q", args[1])
+			if s := suggest(args[1], sortedKeys(settingsPanes)); s != "" {
+				msg += fmt.Sprintf("; did you mean %q?", s)
+			}
+			return usagef("%s (want %s)", msg, strings.Join(sortedKeys(settingsPanes), ", "))
+		}
+		return openWith(url)
+	}
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	label := labelFlag(fs, "label", "launchd label whose plist to reveal")
+	scope := fs.String("scope", defaultScope("all"), "user|system|all")
+	app := fs.Bool("app", false, "reveal the app the item's program belongs to instead of its plist")
+	bundleID := labelFlag(fs, "bundle-id", "system extension whose app to reveal")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	switch {
+	case *label != "" && *bundleID != "":
+		return usagef("provide either --label or --bundle-id")
+	case *label != "":
+		path, err := revealPathForLabel(*label, *scope, *app)
+		if err != nil {
+			return err
+		}
+		return openWith("-R", path)
+	case *bundleID != "":
+		ext, err := findSystemExtension(c, *bundleID)
+		if err != nil {
+			return err
+		}
+		if ext.HostApp == "" {
+			return notFoundf("no app found for system extension %q", *bundleID)
+		}
+		return openWith("-R", ext.HostApp)
+	}
+	return usagef("missing --label, --bundle-id, or settings <pane>")
+}
+
+// revealPathForLabel returns the plist of label or, with app, the app
+// bundle its program lives in.
+func revealPathForLabel(label, scope string, app bool) (string, error) {
+	item, err := resolveBackgroundPlist(label, scope)
+	if err != nil {
+		return "", err
+	}
+	if !app {
+		return item.Path, nil
+	}
+	plist, err := readPlist(item.Path)
+	if err != nil {
+		return "", err
+	}
+	program := plistProgram(plist)
+	if owner := owningApp(program, plist); owner != "" {
+		return owner, nil
+	}
+	if program == "" {
+		return "", notFoundf("%s has no program", item.Path)
+	}
+	return program, nil
+}
+
+// openWith runs open(1), which reveals files in Finder with -R and hands
+// x-apple.systempreferences: URLs to System Settings.
+func openWith(args ...string) error {
+	cmd, done := toolCommand("open", args...)
+	out, err := cmd.CombinedOutput()
+	if err = done(err); err != nil {
+		return fmt.Errorf("open %s: %w: %s", args[len(args)-1], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunOpenSettingsPane(t *testing.T) {
+	err := runOpen([]string{"settings", "logn-items"})
+	if err == nil || !strings.Contains(err.Error(), `did you mean "login-items"?`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if exitCode(err) != exitUsage {
+		t.Fatalf("exitCode = %d, want %d", exitCode(err), exitUsage)
+	}
+	if err := runOpen(nil); err == nil || !strings.HasPrefix(err.Error(), "missing ") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// outputFormat is the global --output/-o value. Empty keeps each
+// command's default: a table, or indented JSON with --json.
+var outputFormat string
+
+// outputJamfEA is the --output value for Jamf Pro extension attributes,
+// which read a single value between <result> tags from the script output.
+const outputJamfEA = "jamf-ea"
+
+// outputTable asks for each command's default output, overriding an
+// output set in the config file.
+const outputTable = "table"
+
+var outputFormats = []string{outputTable, "json", "ndjson", "yaml", "csv", "tsv", outputJamfEA}
+
+func checkOutputFormat(output string) error {
+	if output != "" && !slices.Contains(outputFormats, output) {
+		return usagef("unknown output format %q (want %s)", output, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// writeOutput prints v in the --output format, JSON when none was given.
+func writeOutput(v any) error {
+	return encodeOutput(os.Stdout, outputFormat, v)
+}
+
+func encodeOutput(w io.Writer, format string, v any) error {
+	if format == "" || format == "json" || format == "ndjson" || format == "yaml" {
+		var err error
+		if v, err = withSchemaVersion(v); err != nil {
+			return err
+		}
+	}
+	switch format {
+	case "", "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "ndjson":
+		return writeNDJSON(w, v)
+	case "yaml":
+		return writeYAML(w, v)
+	case "csv", "tsv":
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		records, err := tableRecords(data)
+		if err != nil {
+			return err
+		}
+		columns, rows := tabulate(records)
+		cw := csv.NewWriter(w)
+		if format == "tsv" {
+			cw.Comma = '\t'
+		}
+		cw.Write(columns)
+		cw.WriteAll(rows)
+		return cw.Error()
+	case outputJamfEA:
+		return usagef("--output %s is only supported by login list, background list, extensions list and audit", format)
+	default:
+		return checkOutputFormat(format)
+	}
+}
+
+// writeNDJSON writes each element of a list as one JSON object per line,
+// and anything else as a single line.
+func writeNDJSON(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var items []json.RawMessage
+	if json.Unmarshal(data, &items) != nil {
+		items = []json.RawMessage{data}
+	}
+	bw := bufio.NewWriter(w)
+	for _, it := range items {
+		bw.Write(it)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// jsonObject is a decoded JSON object that remembers its key order, so
+// table columns follow the order the fields are declared in.
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *jsonObject) set(key string, v any) {
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// decodeOrdered decodes JSON like json.Unmarshal into an any, except that
+// objects become jsonObject and numbers json.Number.
+func decodeOrdered(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeOrderedValue(dec)
+}
+
+func decodeOrderedValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		var obj jsonObject
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.(string), v)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// tableRecords flattens JSON into table records: one per object in a
+// top-level array, and one per object of a nested array field (such as an
+// audit surface's entries) with the parent's other fields carried along.
+func tableRecords(data []byte) ([]jsonObject, error) {
+	v, err := decodeOrdered(data)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	var records []jsonObject
+	for _, it := range items {
+		records = append(records, flattenRecord(it)...)
+	}
+	return records, nil
+}
+
+func flattenRecord(it any) []jsonObject {
+	obj, ok := it.(jsonObject)
+	if !ok {
+		var rec jsonObject
+		rec.set("value", tableCell(it))
+		return []jsonObject{rec}
+	}
+	var base jsonObject
+	var nestedKey string
+	var nested []any
+	at := 0
+	for _, k := range obj.keys {
+		v := obj.values[k]
+		if arr, ok := v.([]any); ok && nestedKey == "" && len(arr) > 0 {
+			if _, isObj := arr[0].(jsonObject); isObj {
+				nestedKey, nested, at = k, arr, len(base.keys)
+				continue
+			}
+		}
+		base.set(k, tableCell(v))
+	}
+	if nestedKey == "" {
+		return []jsonObject{base}
+	}
+	var out []jsonObject
+	for _, n := range nested {
+		for _, child := range flattenRecord(n) {
+			var rec jsonObject
+			for _, k := range base.keys[:at] {
+				rec.set(k, base.values[k])
+			}
+			for _, k := range child.keys {
+				rec.set(nestedKey+"."+k, child.values[k])
+			}
+			for _, k := range base.keys[at:] {
+				rec.set(k, base.values[k])
+			}
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// tableCell renders a value for one cell; lists and objects are JSON.
+func tableCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// tabulate lines records up under the union of their columns, in the
+// order the columns first appear.
+func tabulate(records []jsonObject) ([]string, [][]string) {
+	columns := []string{}
+	seen := map[string]bool{}
+	for _, rec := range records {
+		for _, k := range rec.keys {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	rows := make([][]string, 0, len(records))
+	for _, rec := range records {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			if v, ok := rec.values[c].(string); ok {
+				row[i] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+// parseColumns checks a --columns list against the JSON field names of
+// item, so a typo fails before anything is listed. An empty list keeps the
+// command's own table.
+func parseColumns(list string, item any) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var known []string
+	t := reflect.TypeOf(item)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known = append(known, name)
+		}
+	}
+	var columns []string
+	for _, c := range strings.Split(list, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !slices.Contains(known, c) {
+			return nil, usagef("unknown column %q (want %s)", c, strings.Join(known, ", "))
+		}
+		columns = append(columns, c)
+	}
+	if len(columns) == 0 {
+		return nil, usagef("--columns lists no columns")
+	}
+	return columns, nil
+}
+
+// printColumns prints a table of just the given fields of items, sized to
+// its contents. Fields an item leaves out are shown as -.
+func printColumns(w io.Writer, items any, columns []string) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	records, err := tableRecords(data)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, rec := range records {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			v, _ := rec.values[c].(string)
+			row[i] = orDash(v)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
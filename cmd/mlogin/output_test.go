@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeOutputTable(t *testing.T) {
+	yes := true
+	items := []BackgroundItem{
+		{Label: "com.example.agent", Path: "/Library/LaunchAgents/com.example.agent.plist", Scope: "system", Kind: "LaunchAgent", Loaded: true},
+		{Label: "com.example.off", Scope: "user", Kind: "LaunchAgent", Disabled: &yes, RiskReasons: []string{"runs from /tmp"}},
+	}
+	var b strings.Builder
+	if err := encodeOutput(&b, "tsv", items); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 rows, got:\n%s", b.String())
+	}
+	if lines[0] != "label\tpath\tscope\tkind\tloaded\tdisabled\trisk_reasons" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if lines[2] != "com.example.off\t\tuser\tLaunchAgent\tfalse\ttrue\t\"[\"\"runs from /tmp\"\"]\"" {
+		t.Fatalf("unexpected row %q", lines[2])
+	}
+}
+
+func TestEncodeOutputRejectsJamfEA(t *testing.T) {
+	var b strings.Builder
+	if err := encodeOutput(&b, outputJamfEA, []LoginItem{}); err == nil {
+		t.Fatalf("expected jamf-ea to be rejected outside the list commands")
+	}
+	if err := encodeOutput(&b, "yaml", []LoginItem{{Name: "Dropbox", Path: "/Applications/Dropbox.app"}}); err != nil || !strings.Contains(b.String(), "name: Dropbox") {
+		t.Fatalf("unexpected yaml %q (%v)", b.String(), err)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var b strings.Builder
+	if err := encodeOutput(&b, "ndjson", []LoginItem{{Name: "A"}, {Name: "B", Hidden: true}}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"schema_version":1,"name":"A","path":"","hidden":false}` + "\n" + `{"schema_version":1,"name":"B","path":"","hidden":true}` + "\n"
+	if b.String() != want {
+		t.Fatalf("got %q, want %q", b.String(), want)
+	}
+	b.Reset()
+	if err := encodeOutput(&b, "ndjson", []LoginItem{}); err != nil || b.String() != "" {
+		t.Fatalf("expected no lines for an empty list, got %q (%v)", b.String(), err)
+	}
+}
+
+func TestPrintColumns(t *testing.T) {
+	columns, err := parseColumns("label, loaded,health", BackgroundItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	items := []BackgroundItem{{Label: "com.example.agent", Loaded: true, Health: "running"}, {Label: "x"}}
+	if err := printColumns(&b, items, columns); err != nil {
+		t.Fatal(err)
+	}
+	want := "LABEL              LOADED  HEALTH\ncom.example.agent  true    running\nx                  false   -\n"
+	if b.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if _, err := parseColumns("label,colour", BackgroundItem{}); err == nil || !strings.Contains(err.Error(), "scope") {
+		t.Fatalf("expected unknown column error listing the known ones, got %v", err)
+	}
+}
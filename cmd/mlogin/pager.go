@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// noPager is the global --no-pager flag.
+var noPager bool
+
+// pagedCommands are the listings long enough to scroll off screen.
+var pagedCommands = []string{
+	"login list", "background list", "bg list", "extensions list", "ext list",
+	"audit", "history", "timeline", "diff", "cron list",
+}
+
+func isPagedCommand(args []string) bool {
+	for _, c := range pagedCommands {
+		words := strings.Fields(c)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == c {
+			return true
+		}
+	}
+	return false
+}
+
+// startPager sends stdout through $PAGER (less by default) when it is a
+// terminal, the way git does: unless $LESS says otherwise, less exits at
+// once when the output fits on one screen, keeps colors, and leaves the
+// output on screen. The returned func waits for the pager to exit.
+func startPager() (wait func()) {
+	noop := func() {}
+	if noPager || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return noop
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	if pager == "cat" {
+		return noop
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return noop
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = r, os.Stdout, os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return noop
+	}
+	r.Close()
+	stdout := os.Stdout
+	os.Stdout = w
+	return func() {
+		w.Close()
+		cmd.Wait()
+		os.Stdout = stdout
+	}
+}
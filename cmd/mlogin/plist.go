@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readPlist loads a property list in any on-disk format (XML, binary, JSON)
+// by normalizing it through plutil and decoding the XML form.
+func readPlist(path string) (map[string]any, error) {
+	out, err := plistXML(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodePlistXML(out)
+}
+
+// readPlistValue is readPlist for property lists whose root is not a dict.
+func readPlistValue(path string) (any, error) {
+	out, err := plistXML(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodePlistXMLValue(out)
+}
+
+func plistXML(path string) ([]byte, error) {
+	cmd, done := toolCommand("plutil", "-convert", "xml1", "-o", "-", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("read plist %s: %w: %s", path, err, msg)
+		}
+		return nil, fmt.Errorf("read plist %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// decodePlistXML decodes an XML property list whose root is a dict. Dates and
+// data blobs are returned as their string representation.
+func decodePlistXML(data []byte) (map[string]any, error) {
+	v, err := decodePlistXMLValue(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("plist root is not a dict")
+	}
+	return m, nil
+}
+
+// decodePlistXMLValue decodes an XML property list with any root type.
+func decodePlistXMLValue(data []byte) (any, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("plist has no root element")
+			}
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+		return decodePlistValue(d, start)
+	}
+}
+
+func decodePlistValue(d *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		m := map[string]any{}
+		for {
+			key, done, err := nextPlistElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return m, nil
+			}
+			if key.Name.Local != "key" {
+				return nil, fmt.Errorf("expected <key> in dict, got <%s>", key.Name.Local)
+			}
+			var name string
+			if err := d.DecodeElement(&name, &key); err != nil {
+				return nil, err
+			}
+			vstart, done, err := nextPlistElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return nil, fmt.Errorf("missing value for key %q", name)
+			}
+			v, err := decodePlistValue(d, vstart)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = v
+		}
+	case "array":
+		out := []any{}
+		for {
+			vstart, done, err := nextPlistElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return out, nil
+			}
+			v, err := decodePlistValue(d, vstart)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	case "string", "date":
+		var s string
+		err := d.DecodeElement(&s, &start)
+		return s, err
+	case "data":
+		var s string
+		err := d.DecodeElement(&s, &start)
+		return strings.Join(strings.Fields(s), ""), err
+	case "integer":
+		var s string
+		if err := d.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case "real":
+		var s string
+		if err := d.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	default:
+		return nil, fmt.Errorf("unsupported plist element <%s>", start.Name.Local)
+	}
+}
+
+// nextPlistElement returns the next start element inside the current
+// container, or done=true when the container's end tag is reached.
+func nextPlistElement(d *xml.Decoder) (xml.StartElement, bool, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.StartElement{}, false, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, false, nil
+		case xml.EndElement:
+			return xml.StartElement{}, true, nil
+		}
+	}
+}
+
+func plistString(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func plistStrings(m map[string]any, key string) []string {
+	raw, _ := m[key].([]any)
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// plistProgram returns the executable launchd will run: Program if set,
+// otherwise the first element of ProgramArguments.
+func plistProgram(m map[string]any) string {
+	if p := plistString(m, "Program"); p != "" {
+		return p
+	}
+	if args := plistStrings(m, "ProgramArguments"); len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
+// encodePlistXML renders m as an XML property list. Keys are written in
+// sorted order so generated files are stable.
+func encodePlistXML(m map[string]any) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n")
+	if err := encodePlistValue(&b, m, 0); err != nil {
+		return nil, err
+	}
+	b.WriteString("</plist>\n")
+	return b.Bytes(), nil
+}
+
+func encodePlistValue(b *bytes.Buffer, v any, depth int) error {
+	indent := strings.Repeat("\t", depth)
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString(indent + "<dict>\n")
+		for _, k := range keys {
+			b.WriteString(indent + "\t<key>" + plistEscape(k) + "</key>\n")
+			if err := encodePlistValue(b, v[k], depth+1); err != nil {
+				return err
+			}
+		}
+		b.WriteString(indent + "</dict>\n")
+	case []any:
+		b.WriteString(indent + "<array>\n")
+		for _, e := range v {
+			if err := encodePlistValue(b, e, depth+1); err != nil {
+				return err
+			}
+		}
+		b.WriteString(indent + "</array>\n")
+	case []string:
+		b.WriteString(indent + "<array>\n")
+		for _, e := range v {
+			b.WriteString(indent + "\t<string>" + plistEscape(e) + "</string>\n")
+		}
+		b.WriteString(indent + "</array>\n")
+	case string:
+		b.WriteString(indent + "<string>" + plistEscape(v) + "</string>\n")
+	case bool:
+		if v {
+			b.WriteString(indent + "<true/>\n")
+		} else {
+			b.WriteString(indent + "<false/>\n")
+		}
+	case int:
+		b.WriteString(indent + "<integer>" + strconv.Itoa(v) + "</integer>\n")
+	case int64:
+		b.WriteString(indent + "<integer>" + strconv.FormatInt(v, 10) + "</integer>\n")
+	case float64:
+		b.WriteString(indent + "<real>" + strconv.FormatFloat(v, 'g', -1, 64) + "</real>\n")
+	default:
+		return fmt.Errorf("unsupported plist value of type %T", v)
+	}
+	return nil
+}
+
+func plistEscape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// lintPlist checks plist syntax with plutil -lint.
+func lintPlist(path string) error {
+	cmd, done := toolCommand("plutil", "-lint", path)
+	out, err := cmd.CombinedOutput()
+	err = done(err)
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func plistInt(m map[string]any, key string) (int64, bool) {
+	switch v := m[key].(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
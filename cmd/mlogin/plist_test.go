@@ -0,0 +1,110 @@
+package main
+
+import "testing"
+
+func TestDecodePlistXML(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.example.agent</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/usr/local/bin/agent</string>
+		<string>--serve</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>StartInterval</key>
+	<integer>3600</integer>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>PATH</key>
+		<string>/usr/bin</string>
+	</dict>
+</dict>
+</plist>`)
+
+	m, err := decodePlistXML(data)
+	if err != nil {
+		t.Fatalf("decodePlistXML: %v", err)
+	}
+	if got := plistString(m, "Label"); got != "com.example.agent" {
+		t.Fatalf("unexpected label: %q", got)
+	}
+	if got := plistProgram(m); got != "/usr/local/bin/agent" {
+		t.Fatalf("unexpected program: %q", got)
+	}
+	if got, _ := m["RunAtLoad"].(bool); !got {
+		t.Fatalf("expected RunAtLoad true")
+	}
+	if got, _ := m["StartInterval"].(int64); got != 3600 {
+		t.Fatalf("unexpected StartInterval: %v", m["StartInterval"])
+	}
+	env, _ := m["EnvironmentVariables"].(map[string]any)
+	if plistString(env, "PATH") != "/usr/bin" {
+		t.Fatalf("unexpected env: %v", env)
+	}
+}
+
+func TestLogPredicate(t *testing.T) {
+	got := logPredicate("com.example.agent", "/usr/local/bin/agent")
+	want := `subsystem == "com.example.agent" OR process == "agent"`
+	if got != want {
+		t.Fatalf("logPredicate = %q, want %q", got, want)
+	}
+}
+
+func TestPlistPathForRejectsUnsafeLabels(t *testing.T) {
+	for _, label := range []string{"", "../../x", "com.example/agent", ".hidden"} {
+		if _, err := plistPathFor(label, "system"); err == nil {
+			t.Fatalf("expected %q to be rejected", label)
+		}
+	}
+	if path, err := plistPathFor("com.example.daemon", "system"); err != nil || path != "/Library/LaunchDaemons/com.example.daemon.plist" {
+		t.Fatalf("plistPathFor = %q, %v", path, err)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("a\nb\nc\n", 2); got != "b\nc\n" {
+		t.Fatalf("lastLines = %q", got)
+	}
+	if got := lastLines("a\nb", 5); got != "a\nb\n" {
+		t.Fatalf("lastLines = %q", got)
+	}
+	if got := lastLines("", 5); got != "" {
+		t.Fatalf("lastLines = %q", got)
+	}
+}
+
+func TestEncodePlistXMLRoundTrip(t *testing.T) {
+	spec := backgroundSpec{
+		label:    "com.me.backup",
+		program:  "/usr/local/bin/backup.sh",
+		args:     []string{"--dest", "a&b"},
+		interval: 3600,
+	}
+	data, err := encodePlistXML(spec.launchdPlist())
+	if err != nil {
+		t.Fatalf("encodePlistXML: %v", err)
+	}
+	m, err := decodePlistXML(data)
+	if err != nil {
+		t.Fatalf("decodePlistXML: %v", err)
+	}
+	if plistString(m, "Label") != "com.me.backup" {
+		t.Fatalf("unexpected label in %s", data)
+	}
+	args := plistStrings(m, "ProgramArguments")
+	if len(args) != 3 || args[2] != "a&b" {
+		t.Fatalf("unexpected ProgramArguments: %v", args)
+	}
+	if got, _ := m["StartInterval"].(int64); got != 3600 {
+		t.Fatalf("unexpected StartInterval: %v", m["StartInterval"])
+	}
+	if _, ok := m["RunAtLoad"]; ok {
+		t.Fatalf("RunAtLoad should be omitted when false")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+type escalationMode int
+
+const (
+	// escalateSudo re-runs a failed launchctl call through sudo, prompting on
+	// the terminal.
+	escalateSudo escalationMode = iota
+	// escalateOsascript asks for an administrator password through the
+	// standard macOS authorization dialog; used by the TUI, which owns the
+	// terminal.
+	escalateOsascript
+	// escalateNone disables escalation (--no-sudo).
+	escalateNone
+)
+
+var escalation = escalateSudo
+
+// isPermissionError reports whether a launchctl failure was caused by
+// missing privileges.
+func isPermissionError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "operation not permitted") ||
+		strings.Contains(msg, "permission denied") ||
+		strings.Contains(msg, "not privileged") ||
+		strings.Contains(msg, "requires root")
+}
+
+// escalatingRunner repeats a launchctl change that failed for lack of
+// privileges once with escalation, unless --no-sudo was given.
+type escalatingRunner struct {
+	next mlogin.Runner
+}
+
+func (r escalatingRunner) Run(ctx context.Context, c mlogin.Command) ([]byte, error) {
+	out, err := r.next.Run(ctx, c)
+	if err != nil && c.Changes && c.Name == "launchctl" && escalation != escalateNone && os.Geteuid() != 0 && isPermissionError(err) {
+		out, err := runPrivileged("/bin/launchctl", c.Args...)
+		return []byte(out), err
+	}
+	return out, err
+}
+
+// runPrivileged runs program, an absolute path, with administrator
+// privileges using the configured escalation mode. It starts sudo or
+// osascript itself instead of going through a Runner: it is the last step
+// of escalatingRunner, sudo needs the terminal's stdin for its prompt, and
+// --timeout must not cut off someone typing a password. Callers handle
+// --dry-run before getting here.
+func runPrivileged(program string, args ...string) (string, error) {
+	var cmd *exec.Cmd
+	switch escalation {
+	case escalateSudo:
+		cmd = exec.Command("sudo", append([]string{program}, args...)...)
+		cmd.Stdin = os.Stdin
+	case escalateOsascript:
+		script := fmt.Sprintf("do shell script %s with administrator privileges", appleScriptString(shellJoin(append([]string{program}, args...))))
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		return "", fmt.Errorf("privilege escalation disabled")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	done := traceCommand(cmd)
+	err := cmd.Run()
+	done()
+	if err != nil {
+		return stdout.String(), &mlogin.CommandError{Args: cmd.Args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return stdout.String(), nil
+}
+
+// shellJoin quotes args for /bin/sh.
+func shellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(a, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
+
+// appleScriptString renders s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
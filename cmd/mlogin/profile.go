@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// profileDir holds named profiles saved by `mlogin profile save`. Each is a
+// manifest in the format `mlogin apply` reads, so it can be edited by hand.
+func profileDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Application Support", "mlogin", "profiles"), nil
+}
+
+func profilePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := profileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+func runProfile(args []string) error {
+	c := client()
+	if len(args) == 0 {
+		return usagef("missing profile subcommand")
+	}
+	switch args[0] {
+	case "list":
+		return listProfiles()
+	case "save":
+		fs := flag.NewFlagSet("profile save", flag.ContinueOnError)
+		force := fs.Bool("force", false, "overwrite an existing profile")
+		name, err := parseProfileArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return saveProfile(c, name, *force)
+	case "switch":
+		fs := flag.NewFlagSet("profile switch", flag.ContinueOnError)
+		yes := fs.Bool("yes", false, "switch without asking")
+		name, err := parseProfileArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return switchProfile(c, name, *yes)
+	default:
+		return usagef("unknown profile subcommand %q", args[0])
+	}
+}
+
+// parseProfileArgs parses fs and returns the profile name, which may come
+// before or after the flags.
+func parseProfileArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return "", err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		return "", usagef("missing profile name")
+	}
+	return name, nil
+}
+
+func listProfiles() error {
+	dir, err := profileDir()
+	if err != nil {
+		return err
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if len(matches) == 0 {
+		fmt.Println("No profiles saved")
+		return nil
+	}
+	sort.Strings(matches)
+	for _, m := range matches {
+		fmt.Println(strings.TrimSuffix(filepath.Base(m), ".yaml"))
+	}
+	return nil
+}
+
+// manifestFromState records the current login items and the enabled or
+// disabled state of each background item. Switching back removes login items
+// added since; background items added since are left alone.
+func manifestFromState(login []LoginItem, items []BackgroundItem) Manifest {
+	var m Manifest
+	m.Policy.UnlistedLoginItems = "remove"
+	m.Policy.UnlistedBackground = "keep"
+	for _, it := range login {
+		m.LoginItems = append(m.LoginItems, ManifestLoginItem{Path: it.Path, Hidden: it.Hidden})
+	}
+	for _, it := range items {
+		if it.Disabled == nil || it.Provenance == "legacy" {
+			continue
+		}
+		if *it.Disabled {
+			m.Background.Disabled = append(m.Background.Disabled, it.Label)
+		} else {
+			m.Background.Enabled = append(m.Background.Enabled, it.Label)
+		}
+	}
+	return m
+}
+
+func saveProfile(c mlogin.Client, name string, force bool) error {
+	path, err := profilePath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("profile %q already exists; pass --force to overwrite", name)
+	}
+	login, err := listLoginItems(c)
+	if err != nil {
+		return err
+	}
+	items, _, err := listBackgroundItems(c, "all", false)
+	if err != nil {
+		return err
+	}
+	m := manifestFromState(login, withoutAppleItems(items))
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writeYAML(f, m); err != nil {
+		return err
+	}
+	successf("saved profile %s (%d login item(s), %d background item(s)) to %s", name, len(m.LoginItems), len(m.Background.Enabled)+len(m.Background.Disabled), path)
+	return nil
+}
+
+func switchProfile(c mlogin.Client, name string, yes bool) error {
+	path, err := profilePath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("no profile named %q (see mlogin profile list)", name)
+	}
+	m, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+	return applyManifest(c, m, yes)
+}
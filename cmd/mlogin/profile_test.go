@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestManifestFromStateRoundTrip(t *testing.T) {
+	yes, no := true, false
+	login := []LoginItem{{Name: "Slack", Path: "/Applications/Slack.app"}}
+	items := []BackgroundItem{
+		{Label: "com.docker.helper", Scope: "system", Disabled: &no},
+		{Label: "com.slack.agent", Scope: "user", Disabled: &yes},
+		{Label: "com.unknown.agent", Scope: "user"},
+	}
+	m := manifestFromState(login, items)
+	var b strings.Builder
+	if err := writeYAML(&b, m); err != nil {
+		t.Fatalf("writeYAML: %v", err)
+	}
+	var saved Manifest
+	if err := readYAML([]byte(b.String()), &saved); err != nil {
+		t.Fatalf("readYAML: %v\n%s", err, b.String())
+	}
+	if len(saved.Background.Enabled) != 1 || len(saved.Background.Disabled) != 1 {
+		t.Fatalf("unexpected background state: %+v", saved.Background)
+	}
+
+	actions, _, err := planApply(saved, login, items)
+	if err != nil || len(actions) != 0 {
+		t.Fatalf("expected no changes against the saved state, got %v %v", actions, err)
+	}
+
+	// On a "home" machine state with Docker disabled and a new login item,
+	// switching back re-enables Docker and removes the extra login item.
+	items[0].Disabled = &yes
+	login = append(login, LoginItem{Name: "Spotify", Path: "/Applications/Spotify.app"})
+	actions, _, err = planApply(saved, login, items)
+	if err != nil || len(actions) != 2 {
+		t.Fatalf("expected 2 changes, got %v %v", actions, err)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type ConfigProfile struct {
+	Identifier   string           `json:"identifier"`
+	Name         string           `json:"name,omitempty"`
+	Organization string           `json:"organization,omitempty"`
+	Level        string           `json:"level"`
+	InstallDate  string           `json:"install_date,omitempty"`
+	Payloads     []ProfilePayload `json:"payloads,omitempty"`
+}
+
+type ProfilePayload struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier,omitempty"`
+	// Controls names what a payload governs when it affects login items,
+	// launchd services, or extensions; it is empty for other payloads.
+	Controls string `json:"controls,omitempty"`
+}
+
+// profilePayloadControls are the payload types that restrict what mlogin can
+// change: toggles of items they cover are reverted or refused.
+var profilePayloadControls = map[string]string{
+	serviceManagementPayload:                      "managed login items / launchd services",
+	"com.apple.loginitems.managed":                "login items",
+	"com.apple.loginwindow":                       "login window and login items",
+	"com.apple.system-extension-policy":           "system extensions",
+	"com.apple.syspolicy.kernel-extension-policy": "kernel extensions",
+	"com.apple.TCC.configuration-profile-policy":  "privacy permissions (TCC)",
+	"com.apple.webcontent-filter":                 "network extensions (content filter)",
+	"com.apple.vpn.managed":                       "network extensions (VPN)",
+}
+
+func runProfiles(args []string) error {
+	if len(args) == 0 {
+		return usagef("missing profiles subcommand")
+	}
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("profiles list", flag.ContinueOnError)
+		jsonOut := fs.Bool("json", false, "output JSON")
+		relevant := fs.Bool("relevant", false, "only show profiles with payloads that control login items, services, or extensions")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		profiles, err := listConfigProfiles()
+		if err != nil {
+			return err
+		}
+		if *relevant {
+			profiles = relevantProfiles(profiles)
+		}
+		if *jsonOut || outputFormat != "" {
+			return writeOutput(profiles)
+		}
+		printConfigProfiles(profiles)
+		return nil
+	default:
+		return usagef("unknown profiles subcommand %q", args[0])
+	}
+}
+
+// showProfiles returns the decoded `profiles show` output: a dictionary
+// keyed by "_computerlevel" or a user name, each holding that level's
+// profiles. It is nil when no profiles are installed.
+func showProfiles() (map[string]any, error) {
+	cmd, done := toolCommand("profiles", "show", "-output", "stdout-xml")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("profiles show: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("profiles show: %w", err)
+	}
+	if !bytes.Contains(out, []byte("<plist")) {
+		return nil, nil
+	}
+	m, err := decodePlistXML(out)
+	if err != nil {
+		return nil, fmt.Errorf("parse profiles output: %w", err)
+	}
+	return m, nil
+}
+
+func listConfigProfiles() ([]ConfigProfile, error) {
+	m, err := showProfiles()
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigProfiles(m), nil
+}
+
+// parseConfigProfiles flattens the per-level profile arrays of `profiles
+// show` output, computer-level profiles first.
+func parseConfigProfiles(m map[string]any) []ConfigProfile {
+	levels := make([]string, 0, len(m))
+	for level := range m {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if (levels[i] == "_computerlevel") != (levels[j] == "_computerlevel") {
+			return levels[i] == "_computerlevel"
+		}
+		return levels[i] < levels[j]
+	})
+	var profiles []ConfigProfile
+	for _, level := range levels {
+		raw, _ := m[level].([]any)
+		name := strings.TrimPrefix(level, "_computerlevel")
+		if name == "" {
+			name = "computer"
+		} else {
+			name = "user:" + name
+		}
+		for _, r := range raw {
+			pm, ok := r.(map[string]any)
+			if !ok {
+				continue
+			}
+			p := ConfigProfile{
+				Identifier:   plistString(pm, "ProfileIdentifier"),
+				Name:         plistString(pm, "ProfileDisplayName"),
+				Organization: plistString(pm, "ProfileOrganization"),
+				Level:        name,
+				InstallDate:  plistString(pm, "ProfileInstallDate"),
+			}
+			items, _ := pm["ProfileItems"].([]any)
+			for _, it := range items {
+				im, ok := it.(map[string]any)
+				if !ok {
+					continue
+				}
+				t := plistString(im, "PayloadType")
+				p.Payloads = append(p.Payloads, ProfilePayload{
+					Type:       t,
+					Identifier: plistString(im, "PayloadIdentifier"),
+					Controls:   profilePayloadControls[t],
+				})
+			}
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
+
+// relevantProfiles keeps the profiles with at least one payload listed in
+// profilePayloadControls.
+func relevantProfiles(profiles []ConfigProfile) []ConfigProfile {
+	var out []ConfigProfile
+	for _, p := range profiles {
+		for _, pl := range p.Payloads {
+			if pl.Controls != "" {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func printConfigProfiles(profiles []ConfigProfile) {
+	if len(profiles) == 0 {
+		fmt.Println("No configuration profiles installed")
+		return
+	}
+	for _, p := range profiles {
+		fmt.Printf("%s (%s)\n", orDash(p.Name), p.Identifier)
+		fmt.Printf("  level: %s", p.Level)
+		if p.Organization != "" {
+			fmt.Printf("  organization: %s", p.Organization)
+		}
+		if p.InstallDate != "" {
+			fmt.Printf("  installed: %s", p.InstallDate)
+		}
+		fmt.Println()
+		for _, pl := range p.Payloads {
+			if pl.Controls != "" {
+				fmt.Printf("  ! %s  controls %s\n", pl.Type, pl.Controls)
+				continue
+			}
+			fmt.Printf("    %s\n", pl.Type)
+		}
+	}
+}
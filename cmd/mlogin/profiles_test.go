@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestParseConfigProfiles(t *testing.T) {
+	out := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>alice</key>
+	<array>
+		<dict>
+			<key>ProfileIdentifier</key>
+			<string>com.example.user</string>
+			<key>ProfileItems</key>
+			<array>
+				<dict>
+					<key>PayloadType</key>
+					<string>com.apple.dock</string>
+				</dict>
+			</array>
+		</dict>
+	</array>
+	<key>_computerlevel</key>
+	<array>
+		<dict>
+			<key>ProfileIdentifier</key>
+			<string>com.example.mdm</string>
+			<key>ProfileDisplayName</key>
+			<string>Example MDM</string>
+			<key>ProfileOrganization</key>
+			<string>Example Corp</string>
+			<key>ProfileItems</key>
+			<array>
+				<dict>
+					<key>PayloadType</key>
+					<string>com.apple.servicemanagement</string>
+					<key>PayloadIdentifier</key>
+					<string>com.example.mdm.sm</string>
+				</dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`)
+	m, err := decodePlistXML(out)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	profiles := parseConfigProfiles(m)
+	if len(profiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(profiles))
+	}
+	p := profiles[0]
+	if p.Identifier != "com.example.mdm" || p.Level != "computer" || p.Organization != "Example Corp" {
+		t.Fatalf("unexpected first profile: %+v", p)
+	}
+	if len(p.Payloads) != 1 || p.Payloads[0].Controls == "" {
+		t.Fatalf("expected service management payload to be flagged: %+v", p.Payloads)
+	}
+	if profiles[1].Level != "user:alice" || profiles[1].Payloads[0].Controls != "" {
+		t.Fatalf("unexpected user profile: %+v", profiles[1])
+	}
+	relevant := relevantProfiles(profiles)
+	if len(relevant) != 1 || relevant[0].Identifier != "com.example.mdm" {
+		t.Fatalf("unexpected relevant profiles: %+v", relevant)
+	}
+}
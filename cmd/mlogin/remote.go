@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// remoteHost is set by --host: the command then runs on that Mac over SSH
+// instead of locally. remoteMlogin is the mlogin binary there.
+var (
+	remoteHost   string
+	remoteMlogin = "mlogin"
+)
+
+// exitStatusError carries a remote mlogin's exit status back to main.
+type exitStatusError struct {
+	code int
+}
+
+func (e exitStatusError) Error() string {
+	return fmt.Sprintf("remote mlogin exited with status %d", e.code)
+}
+
+// checkSSHHost rejects destinations ssh would parse as something else: a
+// leading - makes it an option such as -oProxyCommand, which runs a local
+// command.
+func checkSSHHost(host string) error {
+	switch {
+	case host == "":
+		return errors.New("empty SSH host")
+	case strings.HasPrefix(host, "-"):
+		return fmt.Errorf("invalid SSH host %q: must not start with -", host)
+	case strings.ContainsFunc(host, unicode.IsSpace):
+		return fmt.Errorf("invalid SSH host %q: must not contain spaces", host)
+	}
+	return nil
+}
+
+// runRemote runs mlogin on host with args, connected to this terminal so
+// --json output, confirmation prompts, and the TUI all work as if local.
+// SSH keys and host settings come from the user's ssh config.
+func runRemote(host string, args []string) error {
+	if err := checkSSHHost(host); err != nil {
+		return usagef("--host: %w", err)
+	}
+	if escalation == escalateNone {
+		args = append([]string{"--no-sudo"}, args...)
+	}
+	if outputFormat != "" {
+		args = append([]string{"--output", outputFormat}, args...)
+	}
+	if noColor {
+		args = append([]string{"--no-color"}, args...)
+	}
+	if noPager {
+		args = append([]string{"--no-pager"}, args...)
+	}
+	if dryRun {
+		args = append([]string{"--dry-run"}, args...)
+	}
+	if quiet {
+		args = append([]string{"--quiet"}, args...)
+	}
+	if verbose {
+		args = append([]string{"--verbose"}, args...)
+	}
+	if timeoutFlag != "" {
+		args = append([]string{"--timeout", timeoutFlag}, args...)
+	}
+	tty := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	sshArgs := remoteCommand(host, remoteMlogin, args, tty)
+	cmd := exec.Command("ssh", sshArgs...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// ssh itself exits with 255 when it cannot connect.
+		if exitErr.ExitCode() == 255 {
+			return fmt.Errorf("ssh %s failed", host)
+		}
+		return exitStatusError{code: exitErr.ExitCode()}
+	}
+	return err
+}
+
+// remoteCommand builds the ssh arguments. The remote command is quoted for
+// the remote shell; a terminal is allocated when there is one locally.
+func remoteCommand(host, bin string, args []string, tty bool) []string {
+	sshArgs := []string{}
+	if tty {
+		sshArgs = append(sshArgs, "-t")
+	}
+	return append(sshArgs, host, "--", shellJoin(append([]string{bin}, args...)))
+}
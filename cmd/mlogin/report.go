@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report is the audit rendered for people: a ticket attachment or
+// something to send a less technical user.
+type Report struct {
+	Hostname  string
+	CreatedAt time.Time
+	Surfaces  []AuditSurface
+	// Signatures holds the code signature of each program, by path.
+	Signatures map[string]CodeSignature
+}
+
+type reportFinding struct {
+	Surface string
+	Entry   AuditEntry
+}
+
+func runReport(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	format := fs.String("format", "", "html|md (default: from the --out extension, else md)")
+	out := fs.String("out", "", "write the report to this file (default: stdout)")
+	verify := fs.Bool("verify", false, "also assess each program with Gatekeeper")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = "md"
+		if strings.HasSuffix(*out, ".html") || strings.HasSuffix(*out, ".htm") {
+			*format = "html"
+		}
+	}
+	if *format != "html" && *format != "md" {
+		return usagef("--format must be html or md")
+	}
+
+	r := Report{CreatedAt: time.Now(), Surfaces: runAuditProviders(c, auditProviders), Signatures: map[string]CodeSignature{}}
+	r.Hostname, _ = os.Hostname()
+	for i := range r.Surfaces {
+		if *verify {
+			verifyAuditEntries(r.Surfaces[i].Entries)
+		}
+		for _, e := range r.Surfaces[i].Entries {
+			if e.Program == "" {
+				continue
+			}
+			if _, done := r.Signatures[e.Program]; done {
+				continue
+			}
+			if _, err := os.Stat(e.Program); err == nil {
+				r.Signatures[e.Program] = verifyCodeSignature(e.Program)
+			}
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	var err error
+	if *format == "html" {
+		err = renderHTMLReport(w, r)
+	} else {
+		err = renderMarkdownReport(w, r)
+	}
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "wrote %s\n", *out)
+	}
+	return nil
+}
+
+func (r Report) Findings() []reportFinding {
+	var out []reportFinding
+	for _, s := range r.Surfaces {
+		for _, e := range suspiciousEntries(s.Entries) {
+			out = append(out, reportFinding{Surface: s.Surface, Entry: e})
+		}
+	}
+	return out
+}
+
+func (r Report) EntryCount() int {
+	n := 0
+	for _, s := range r.Surfaces {
+		n += len(s.Entries)
+	}
+	return n
+}
+
+// Signer describes who signed program, for the signature column.
+func (r Report) Signer(program string) string {
+	sig, ok := r.Signatures[program]
+	switch {
+	case !ok:
+		return "-"
+	case !sig.Valid && sig.Signer == "":
+		return "unsigned"
+	case !sig.Valid:
+		return sig.Signer + " (invalid)"
+	default:
+		return sig.Signer
+	}
+}
+
+func entryProgram(e AuditEntry) string {
+	if e.Command != "" {
+		return e.Command
+	}
+	return e.Program
+}
+
+func renderMarkdownReport(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Autostart report for %s\n\n", orDash(r.Hostname))
+	fmt.Fprintf(&b, "Generated %s by mlogin. %d items across %d surfaces.\n\n", r.CreatedAt.Format("2006-01-02 15:04 MST"), r.EntryCount(), len(r.Surfaces))
+
+	findings := r.Findings()
+	b.WriteString("## Suspicious findings\n\n")
+	if len(findings) == 0 {
+		b.WriteString("None.\n\n")
+	} else {
+		b.WriteString("| Surface | Item | Risk | Why |\n|---|---|---|---|\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", mdCell(f.Surface), mdCell(f.Entry.Name), f.Entry.Risk, mdCell(strings.Join(f.Entry.RiskReasons, "; ")))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, s := range r.Surfaces {
+		fmt.Fprintf(&b, "## %s (%d)\n\n", upperFirst(s.Surface), len(s.Entries))
+		if s.Error != "" {
+			fmt.Fprintf(&b, "Could not read this surface: %s\n\n", s.Error)
+		}
+		if len(s.Entries) == 0 {
+			if s.Error == "" {
+				b.WriteString("Nothing found.\n\n")
+			}
+			continue
+		}
+		b.WriteString("| Item | Runs | Signed by | Risk | Details |\n|---|---|---|---|---|\n")
+		for _, e := range s.Entries {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %s |\n", mdCell(e.Name), mdCode(entryProgram(e)), mdCell(r.Signer(e.Program)), e.Risk, mdCell(e.Detail))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdCell keeps a value from breaking out of its table cell.
+func mdCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func mdCode(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return "`" + strings.ReplaceAll(mdCell(s), "`", "'") + "`"
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"title":   upperFirst,
+	"program": entryProgram,
+	"join":    strings.Join,
+	"isSuspicious": func(e AuditEntry) bool {
+		return e.Risk >= suspiciousRisk
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Autostart report for {{.Hostname}}</title>
+<style>
+body { font: 14px -apple-system, BlinkMacSystemFont, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+code { font-size: 12px; word-break: break-all; }
+tr.suspicious { background: #fdecea; }
+.muted { color: #777; }
+</style>
+</head>
+<body>
+<h1>Autostart report for {{.Hostname}}</h1>
+<p class="muted">Generated {{.CreatedAt.Format "2006-01-02 15:04 MST"}} by mlogin. {{.EntryCount}} items across {{len .Surfaces}} surfaces.</p>
+<h2>Suspicious findings</h2>
+{{with .Findings}}<table>
+<tr><th>Surface</th><th>Item</th><th>Risk</th><th>Why</th></tr>
+{{range .}}<tr class="suspicious"><td>{{.Surface}}</td><td>{{.Entry.Name}}</td><td>{{.Entry.Risk}}</td><td>{{join .Entry.RiskReasons "; "}}</td></tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}
+{{range .Surfaces}}<h2>{{title .Surface}} ({{len .Entries}})</h2>
+{{if .Error}}<p>Could not read this surface: {{.Error}}</p>{{end}}
+{{if .Entries}}<table>
+<tr><th>Item</th><th>Runs</th><th>Signed by</th><th>Risk</th><th>Details</th></tr>
+{{range .Entries}}<tr{{if isSuspicious .}} class="suspicious"{{end}}><td>{{.Name}}</td><td><code>{{program .}}</code></td><td>{{$.Signer .Program}}</td><td>{{.Risk}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>{{else if not .Error}}<p class="muted">Nothing found.</p>{{end}}
+{{end}}</body>
+</html>
+`))
+
+func renderHTMLReport(w io.Writer, r Report) error {
+	return htmlReportTemplate.Execute(w, r)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() Report {
+	return Report{
+		Hostname:  "mac1",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+		Surfaces: []AuditSurface{
+			{Surface: "launchd", Entries: []AuditEntry{
+				{Name: "com.evil.agent", Program: "/tmp/x", Command: "/tmp/x --a|b", Risk: suspiciousRisk, RiskReasons: []string{"runs from /tmp", "unsigned"}},
+				{Name: "<com.ok>", Program: "/Applications/Ok.app/Contents/MacOS/Ok", Detail: "user agent"},
+			}},
+			{Surface: "cron", Error: "crontab -l failed"},
+		},
+		Signatures: map[string]CodeSignature{
+			"/tmp/x":                                 {Valid: false},
+			"/Applications/Ok.app/Contents/MacOS/Ok": {Valid: true, Signer: "Developer ID Application: Ok Inc (ABCDE12345)"},
+		},
+	}
+}
+
+func TestRenderMarkdownReport(t *testing.T) {
+	var b strings.Builder
+	if err := renderMarkdownReport(&b, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# Autostart report for mac1",
+		"2 items across 2 surfaces",
+		"| launchd | com.evil.agent | 3 | runs from /tmp; unsigned |",
+		"| com.evil.agent | `/tmp/x --a\\|b` | unsigned | 3 | - |",
+		"| Developer ID Application: Ok Inc (ABCDE12345) |",
+		"## Cron (0)",
+		"Could not read this surface: crontab -l failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderHTMLReport(t *testing.T) {
+	var b strings.Builder
+	if err := renderHTMLReport(&b, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"<h1>Autostart report for mac1</h1>",
+		`<tr class="suspicious"><td>launchd</td><td>com.evil.agent</td>`,
+		"<td>&lt;com.ok&gt;</td>",
+		"<td>unsigned</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// suspiciousRisk is the score from which an item counts as suspicious for
+// --suspicious-only.
+const suspiciousRisk = 3
+
+var (
+	// tempDirs are world-writable or scratch locations legitimate installers
+	// do not run persistent programs from.
+	tempDirs = []string{"/tmp/", "/private/tmp/", "/var/tmp/", "/private/var/tmp/", "/Users/Shared/"}
+	// downloadExecRe matches a download piped straight into a shell, as in
+	// "curl -fsSL https://... | sh".
+	downloadExecRe = regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z|k)?sh\b`)
+	base64Re       = regexp.MustCompile(`\bbase64\b|\bb64decode\b|\bfrombase64string\b`)
+	// appleLookalikeRe matches labels that imitate Apple's reverse-DNS
+	// prefix, including common typosquats.
+	appleLookalikeRe = regexp.MustCompile(`^com\.(apple|app1e|appie|appl|aple|apples)[.\-_]`)
+)
+
+// riskSignature is the code signature of a program as returned by
+// codesignInfo; err is set when the program is unsigned.
+type riskSignature struct {
+	info map[string]string
+	err  error
+}
+
+// assessRisk scores a persistence entry on simple heuristics and returns the
+// reasons that contributed. program may be empty when the surface has none,
+// in which case signing is not judged; sig is only consulted for programs
+// that exist.
+func assessRisk(label, program, command string, sig func(string) riskSignature) (int, []string) {
+	score := 0
+	var reasons []string
+	add := func(points int, reason string) {
+		score += points
+		reasons = append(reasons, reason)
+	}
+
+	paths := commandPaths(program, command)
+	for _, p := range paths {
+		if dir := tempDirPrefix(p); dir != "" {
+			add(3, "runs from "+strings.TrimSuffix(dir, "/"))
+			break
+		}
+	}
+	for _, p := range paths {
+		if isHiddenPath(p) {
+			add(2, "runs from a hidden directory")
+			break
+		}
+	}
+	lower := strings.ToLower(command)
+	if downloadExecRe.MatchString(lower) {
+		add(3, "pipes a download into a shell")
+	}
+	if base64Re.MatchString(lower) {
+		add(2, "decodes base64")
+	}
+
+	var signature *riskSignature
+	if program != "" {
+		if _, err := os.Stat(program); err == nil {
+			s := sig(program)
+			signature = &s
+			if s.err != nil {
+				add(2, "program is not signed")
+			}
+		}
+	}
+	if program != "" && appleLookalikeRe.MatchString(strings.ToLower(label)) && !isAppleSigned(signature) {
+		add(3, "Apple-style label but not signed by Apple")
+	}
+	return score, reasons
+}
+
+// commandPaths returns the program and every absolute path argument of
+// command.
+func commandPaths(program, command string) []string {
+	var paths []string
+	if program != "" {
+		paths = append(paths, program)
+	}
+	for _, f := range strings.Fields(command) {
+		f = strings.Trim(f, `'"`)
+		if strings.HasPrefix(f, "/") || strings.HasPrefix(f, "~/") {
+			paths = append(paths, f)
+		}
+	}
+	return paths
+}
+
+func tempDirPrefix(path string) string {
+	for _, dir := range tempDirs {
+		if strings.HasPrefix(path, dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// isHiddenPath reports whether a directory on path starts with a dot, as
+// in ~/Library/.cache/agent.
+func isHiddenPath(path string) bool {
+	for _, part := range strings.Split(filepath.Dir(path), "/") {
+		if len(part) > 1 && strings.HasPrefix(part, ".") && part != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// isAppleSigned reports whether sig belongs to code Apple signed itself.
+// A program that is missing or was not checked is not Apple-signed.
+func isAppleSigned(sig *riskSignature) bool {
+	if sig == nil || sig.err != nil {
+		return false
+	}
+	authority := sig.info["Authority"]
+	return authority == "Software Signing" || strings.HasPrefix(authority, "Apple Mac OS Application Signing")
+}
+
+// codesignRiskSignature adapts codesignInfo for assessRisk.
+func codesignRiskSignature(program string) riskSignature {
+	info, err := codesignInfo(program)
+	return riskSignature{info: info, err: err}
+}
+
+// fillRisk scores items from their plists.
+func fillRisk(items []BackgroundItem) {
+	for i := range items {
+		if items[i].Path == "" || items[i].Kind == "startup" {
+			continue
+		}
+		plist, err := readPlist(items[i].Path)
+		if err != nil {
+			continue
+		}
+		program := plistProgram(plist)
+		command := strings.Join(plistStrings(plist, "ProgramArguments"), " ")
+		items[i].Risk, items[i].RiskReasons = assessRisk(items[i].Label, program, command, codesignRiskSignature)
+	}
+}
+
+func suspiciousItems(items []BackgroundItem) []BackgroundItem {
+	out := make([]BackgroundItem, 0, len(items))
+	for _, it := range items {
+		if it.Risk >= suspiciousRisk {
+			out = append(out, it)
+		}
+	}
+	return out
+}
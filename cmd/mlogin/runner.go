@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// client runs osascript, launchctl, and systemextensionsctl on this Mac.
+// Under --dry-run, calls that change something are printed instead of run,
+// and launchctl changes refused for lack of privileges are retried with
+// escalation. Each command makes one and passes it down to what it lists
+// and changes; tests pass one with canned output instead.
+func client() mlogin.Client {
+	return mlogin.Client{Runner: dryRunner{next: escalatingRunner{next: toolRunner{}}}}
+}
+
+// toolRunner runs tools on this Mac through toolCommand, so --timeout and
+// --verbose apply.
+type toolRunner struct{}
+
+func (toolRunner) Run(ctx context.Context, c mlogin.Command) ([]byte, error) {
+	cmd, done := toolCommandContext(ctx, c.Name, c.Args...)
+	if len(c.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range c.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err = done(err); err != nil {
+		var timeout *timeoutError
+		if errors.As(err, &timeout) {
+			return out, err
+		}
+		return out, &mlogin.CommandError{Args: cmd.Args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return out, nil
+}
+
+// runTool runs cmd through c and splits a failure into the tool's stderr
+// and its error, for messages that add their own context.
+func runTool(c mlogin.Client, cmd mlogin.Command) (stdout, stderr string, err error) {
+	out, err := c.Run(context.Background(), cmd)
+	var cmdErr *mlogin.CommandError
+	if errors.As(err, &cmdErr) {
+		return string(out), cmdErr.Stderr, cmdErr.Err
+	}
+	return string(out), "", err
+}
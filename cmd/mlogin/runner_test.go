@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// cannedRunner answers each command line with fixed output and records
+// what ran. Other commands fail with stderr, "Operation not permitted"
+// unless set.
+type cannedRunner struct {
+	out    map[string]string
+	stderr string
+	ran    []string
+}
+
+func (r *cannedRunner) Run(_ context.Context, c mlogin.Command) ([]byte, error) {
+	line := strings.Join(append([]string{c.Name}, c.Args...), " ")
+	r.ran = append(r.ran, line)
+	out, ok := r.out[line]
+	if !ok {
+		stderr := r.stderr
+		if stderr == "" {
+			stderr = "Operation not permitted"
+		}
+		return nil, &mlogin.CommandError{Args: append([]string{c.Name}, c.Args...), Stderr: stderr, Err: errors.New("exit status 1")}
+	}
+	return []byte(out), nil
+}
+
+func TestRunnerCannedOutput(t *testing.T) {
+	canned := &cannedRunner{out: map[string]string{
+		"launchctl list": "PID\tStatus\tLabel\n412\t0\tcom.example.agent\n-\t78\tcom.example.crashy\n",
+		"osascript -l JavaScript -e " + mlogin.LoginItemsScript: `[{"name":"Slack","path":"/Applications/Slack.app","hidden":false}]`,
+	}}
+	c := mlogin.Client{Runner: canned}
+
+	labels, err := c.LoadedServices(context.Background(), "")
+	if err != nil || labels["com.example.crashy"].Health() != "failing" || labels["com.example.agent"].PID != 412 {
+		t.Fatalf("LoadedServices = %v, %v", labels, err)
+	}
+	items, err := listLoginItems(c)
+	if err != nil || len(items) != 1 || items[0].Name != "Slack" {
+		t.Fatalf("listLoginItems = %v, %v", items, err)
+	}
+	_, err = runLaunchctlOutput(c, "print", "system/com.example.daemon")
+	if err == nil || err.Error() != "launchctl print system/com.example.daemon: exit status 1: Operation not permitted" || !isPermissionError(err) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestDryRunner(t *testing.T) {
+	canned := &cannedRunner{out: map[string]string{"launchctl print-disabled system": ""}}
+	c := mlogin.Client{Runner: dryRunner{next: canned}}
+	var buf bytes.Buffer
+	dryRun, dryRunOutput = true, &buf
+	defer func() { dryRun, dryRunOutput = false, os.Stdout }()
+
+	if _, err := getDisabledLabels(c, "system"); err != nil {
+		t.Fatalf("getDisabledLabels: %v", err)
+	}
+	if err := toggleService(c, "disable", "system/com.example.daemon"); err != nil {
+		t.Fatalf("toggleService: %v", err)
+	}
+	if strings.Join(canned.ran, ";") != "launchctl print-disabled system" {
+		t.Fatalf("ran %q", canned.ran)
+	}
+	if buf.String() != "would run: launchctl disable system/com.example.daemon\n" {
+		t.Fatalf("printed %q", buf.String())
+	}
+}
+
+func TestLaunchctlMissingServiceIsNotFound(t *testing.T) {
+	c := mlogin.Client{Runner: &cannedRunner{stderr: `Could not find service "com.example.gone" in domain for port`}}
+	_, err := runLaunchctlOutput(c, "print", "system/com.example.gone")
+	if exitCode(err) != exitNotFound || !strings.Contains(err.Error(), "Could not find service") {
+		t.Fatalf("unexpected error %v (exit %d)", err, exitCode(err))
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// outputSchemaVersion is bumped whenever a type in the JSON output changes
+// incompatibly. It is added to every object mlogin prints as JSON, so
+// integrations can tell which shape they are reading.
+const outputSchemaVersion = 1
+
+// schemaTypes are the types `mlogin schema` describes.
+var schemaTypes = map[string]any{
+	"login-item":       LoginItem{},
+	"background-item":  BackgroundItem{},
+	"system-extension": SystemExtensionItem{},
+	"audit-surface":    AuditSurface{},
+	"snapshot":         Snapshot{},
+	"snapshot-change":  SnapshotChange{},
+	"watch-event":      WatchEvent{},
+	"action":           ActionRecord{},
+	"timeline-event":   TimelineEvent{},
+	"error":            ErrorOutput{},
+}
+
+func runSchema(args []string) error {
+	if len(args) != 1 {
+		return usagef("usage: mlogin schema <type>\ntypes: %s", strings.Join(schemaTypeNames(), ", "))
+	}
+	v, ok := schemaTypes[args[0]]
+	if !ok {
+		return usagef("unknown schema type %q (want %s)", args[0], strings.Join(schemaTypeNames(), ", "))
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonSchema(args[0], reflect.TypeOf(v)))
+}
+
+func schemaTypeNames() []string {
+	names := make([]string, 0, len(schemaTypes))
+	for name := range schemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonSchema describes t as a JSON Schema document, including the
+// schema_version every printed object carries.
+func jsonSchema(name string, t reflect.Type) map[string]any {
+	s := schemaFor(t)
+	props := s["properties"].(map[string]any)
+	if _, ok := props["schema_version"]; !ok {
+		props["schema_version"] = map[string]any{"type": "integer", "const": outputSchemaVersion}
+	}
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["$id"] = fmt.Sprintf("https://github.com/j4n-e4t/mlogin/schema/v%d/%s.json", outputSchemaVersion, name)
+	s["title"] = t.Name()
+	return s
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaFor(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		props := map[string]any{}
+		var required []string
+		addStructFields(t, props, &required)
+		s := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s["required"] = required
+		}
+		return s
+	}
+	return map[string]any{}
+}
+
+// addStructFields adds the JSON fields of t, including those of embedded
+// structs, the way encoding/json lays them out.
+func addStructFields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addStructFields(f.Type, props, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// withSchemaVersion adds schema_version to v when it is an object, or to
+// each object in it when it is a list.
+func withSchemaVersion(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeOrdered(data)
+	if err != nil {
+		return nil, err
+	}
+	switch d := decoded.(type) {
+	case jsonObject:
+		return versioned(d), nil
+	case []any:
+		for i, it := range d {
+			if obj, ok := it.(jsonObject); ok {
+				d[i] = versioned(obj)
+			}
+		}
+		return d, nil
+	}
+	return decoded, nil
+}
+
+func versioned(obj jsonObject) jsonObject {
+	if _, ok := obj.values["schema_version"]; ok {
+		return obj
+	}
+	var out jsonObject
+	out.set("schema_version", json.Number(fmt.Sprint(outputSchemaVersion)))
+	for _, k := range obj.keys {
+		out.set(k, obj.values[k])
+	}
+	return out
+}
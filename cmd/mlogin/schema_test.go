@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s := jsonSchema("timeline-event", reflect.TypeOf(TimelineEvent{}))
+	props := s["properties"].(map[string]any)
+	for _, name := range []string{"schema_version", "source", "time", "surface", "fields"} {
+		if _, ok := props[name]; !ok {
+			t.Fatalf("missing property %q in %v", name, props)
+		}
+	}
+	if got := props["time"].(map[string]any)["format"]; got != "date-time" {
+		t.Fatalf("unexpected time format %v", got)
+	}
+	required := s["required"].([]string)
+	if !slices.Contains(required, "source") || slices.Contains(required, "fields") {
+		t.Fatalf("unexpected required fields %v", required)
+	}
+}
+
+func TestWithSchemaVersion(t *testing.T) {
+	var b strings.Builder
+	if err := encodeOutput(&b, "json", LoginItem{Name: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "{\n  \"schema_version\": 1,\n  \"name\": \"A\"") {
+		t.Fatalf("unexpected output %s", b.String())
+	}
+	// A snapshot keeps its own schema version.
+	v, err := withSchemaVersion(Snapshot{SchemaVersion: snapshotSchemaVersion + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.(jsonObject).values["schema_version"]; tableCell(got) != "2" {
+		t.Fatalf("unexpected snapshot schema version %v", got)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func runServe(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8377", "address to listen on")
+	token := fs.String("token", os.Getenv("MLOGIN_TOKEN"), "bearer token required by mutation endpoints (default: $MLOGIN_TOKEN, or a random token printed at startup)")
+	readOnly := fs.Bool("read-only", false, "disable the mutation endpoints")
+	allowRemote := fs.Bool("allow-remote", false, "allow listening on a non-loopback address")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	host, _, err := net.SplitHostPort(*listen)
+	if err != nil {
+		return usagef("--listen: %w", err)
+	}
+	if !*allowRemote && !isLoopbackHost(host) {
+		return fmt.Errorf("refusing to listen on %s: the API can change startup items; pass --allow-remote to expose it beyond this machine", *listen)
+	}
+	if !*readOnly && *token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		*token = hex.EncodeToString(b)
+		fmt.Fprintf(os.Stderr, "mutation token: %s\n", *token)
+	}
+	// Nobody is at a terminal to answer a sudo prompt, so privileged
+	// actions fail with a permission error instead of hanging a request.
+	escalation = escalateNone
+	fmt.Fprintf(os.Stderr, "listening on http://%s\n", *listen)
+	srv := &http.Server{
+		Addr:    *listen,
+		Handler: newServeHandler(c, *token, *readOnly, *allowRemote),
+		// Slow or idle clients must not tie up the server, least of all
+		// with --allow-remote. Responses are not bounded: audit can take
+		// a while to check every signature.
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	return srv.ListenAndServe()
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newServeHandler routes the REST API. Reads are open to local callers;
+// mutations need the bearer token, which a web page cannot send without a
+// CORS preflight this server never answers. Unless remote access is
+// allowed, requests must also name a loopback Host, which defeats DNS
+// rebinding.
+func newServeHandler(c mlogin.Client, token string, readOnly, allowRemote bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		items, err := listLoginItems(c)
+		writeServeResult(w, items, err)
+	})
+	mux.HandleFunc("GET /background", func(w http.ResponseWriter, r *http.Request) {
+		scope := r.URL.Query().Get("scope")
+		if scope == "" {
+			scope = "all"
+		}
+		if scope != "user" && scope != "system" && scope != "all" {
+			writeServeError(w, http.StatusBadRequest, errors.New("scope must be user, system, or all"))
+			return
+		}
+		items, warnings, err := listBackgroundItems(c, scope, r.URL.Query().Get("include_apple") == "true")
+		writeServeResult(w, map[string]any{"items": items, "warnings": warnings}, err)
+	})
+	mux.HandleFunc("GET /extensions", func(w http.ResponseWriter, r *http.Request) {
+		items, err := listSystemExtensions(c)
+		writeServeResult(w, items, err)
+	})
+	mux.HandleFunc("GET /audit", func(w http.ResponseWriter, r *http.Request) {
+		report := runAuditProviders(c, auditProviders)
+		if r.URL.Query().Get("suspicious_only") == "true" {
+			for i := range report {
+				report[i].Entries = suspiciousEntries(report[i].Entries)
+			}
+		}
+		writeServeResult(w, report, nil)
+	})
+
+	mutate := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if readOnly {
+				writeServeError(w, http.StatusForbidden, errors.New("server is read-only"))
+				return
+			}
+			got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				writeServeError(w, http.StatusUnauthorized, errors.New("missing or wrong bearer token"))
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("POST /background/{label}/{verb}", mutate(func(w http.ResponseWriter, r *http.Request) {
+		verb := r.PathValue("verb")
+		if verb != "enable" && verb != "disable" && verb != "unload" {
+			writeServeError(w, http.StatusNotFound, fmt.Errorf("unknown action %q", verb))
+			return
+		}
+		items, err := itemsForLabels([]string{r.PathValue("label")}, r.URL.Query().Get("scope"))
+		if err != nil {
+			writeServeError(w, serveErrorStatus(err), err)
+			return
+		}
+		writeServeResult(w, map[string]string{"label": items[0].Label, "scope": items[0].Scope, "action": verb}, runBackgroundVerb(c, verb, items[0], false))
+	}))
+	mux.HandleFunc("POST /login", mutate(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Path   string `json:"path"`
+			Hidden bool   `json:"hidden"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Path == "" {
+			writeServeError(w, http.StatusBadRequest, errors.New(`body must be {"path": "...", "hidden": false}`))
+			return
+		}
+		writeServeResult(w, req, addLoginItem(c, req.Path, req.Hidden))
+	}))
+	mux.HandleFunc("DELETE /login", mutate(func(w http.ResponseWriter, r *http.Request) {
+		name, path := r.URL.Query().Get("name"), r.URL.Query().Get("path")
+		if name == "" && path == "" {
+			writeServeError(w, http.StatusBadRequest, errors.New("name or path is required"))
+			return
+		}
+		writeServeResult(w, map[string]string{"name": name, "path": path}, removeLoginItem(c, name, path))
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowRemote {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = r.Host
+			}
+			if !isLoopbackHost(strings.Trim(host, "[]")) {
+				writeServeError(w, http.StatusForbidden, fmt.Errorf("host %q not allowed", r.Host))
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeServeResult(w http.ResponseWriter, v any, err error) {
+	if err != nil {
+		writeServeError(w, serveErrorStatus(err), err)
+		return
+	}
+	if v, err = withSchemaVersion(v); err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// serveErrorStatus maps err to the HTTP status of the matching exit code:
+// bad requests and missing items are the caller's, anything else ours.
+func serveErrorStatus(err error) int {
+	switch exitCode(err) {
+	case exitUsage:
+		return http.StatusBadRequest
+	case exitNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeServeError writes err as the ErrorOutput the CLI prints under
+// --output json, with the code taken from the HTTP status where that is
+// more telling.
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	d := errorDetail(err)
+	switch status {
+	case http.StatusBadRequest:
+		d.Code, d.Hint = "usage", "see the serve section of the README for the request format"
+	case http.StatusUnauthorized:
+		d.Code, d.Hint = "unauthorized", "send the mutation token in an Authorization: Bearer header"
+	case http.StatusForbidden:
+		d.Code, d.Hint = "forbidden", ""
+	case http.StatusNotFound:
+		d.Code = "not_found"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorOutput{Error: d})
+}
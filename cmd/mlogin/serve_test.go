@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func TestServeHandlerGuards(t *testing.T) {
+	tests := []struct {
+		name     string
+		readOnly bool
+		method   string
+		target   string
+		host     string
+		auth     string
+		want     int
+	}{
+		{"missing token", false, "POST", "/login", "127.0.0.1:8377", "", http.StatusUnauthorized},
+		{"wrong token", false, "DELETE", "/login?name=Foo", "localhost:8377", "Bearer nope", http.StatusUnauthorized},
+		{"read-only", true, "POST", "/background/com.foo.agent/disable", "127.0.0.1:8377", "Bearer secret", http.StatusForbidden},
+		{"bad body", false, "POST", "/login", "127.0.0.1:8377", "Bearer secret", http.StatusBadRequest},
+		{"unknown action", false, "POST", "/background/com.foo.agent/delete", "127.0.0.1:8377", "Bearer secret", http.StatusNotFound},
+		{"rebound host", false, "GET", "/login", "evil.example:8377", "", http.StatusForbidden},
+		{"bad scope", false, "GET", "/background?scope=nope", "[::1]:8377", "", http.StatusBadRequest},
+		{"bad action scope", false, "POST", "/background/com.foo.agent/disable?scope=nope", "127.0.0.1:8377", "Bearer secret", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		h := newServeHandler(mlogin.Client{Runner: &cannedRunner{}}, "secret", tt.readOnly, false)
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader("{}"))
+		req.Host = tt.host
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Fatalf("%s: got status %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body.String())
+		}
+		var out ErrorOutput
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil || out.Error.Code == "" || out.Error.Message == "" {
+			t.Fatalf("%s: unexpected error body %s", tt.name, rec.Body.String())
+		}
+	}
+}
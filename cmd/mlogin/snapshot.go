@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// snapshotSchemaVersion is bumped whenever Snapshot changes incompatibly.
+const snapshotSchemaVersion = 1
+
+// Snapshot is the autostart state of the machine at one point in time. It is
+// the input to diff, backup, and migration.
+type Snapshot struct {
+	SchemaVersion int                   `json:"schema_version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	Hostname      string                `json:"hostname,omitempty"`
+	LoginItems    []LoginItem           `json:"login_items"`
+	Background    []BackgroundItem      `json:"background_items"`
+	Extensions    []SystemExtensionItem `json:"system_extensions"`
+	// Surfaces holds every other audit surface in normalized form.
+	Surfaces []AuditSurface `json:"surfaces"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// snapshotTypedSurfaces are audit surfaces a Snapshot stores in their own
+// typed fields instead of Surfaces.
+var snapshotTypedSurfaces = map[string]bool{"login items": true, "launchd": true, "system extensions": true}
+
+func runSnapshot(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	out := fs.String("out", "", "write the snapshot to this file (default: stdout)")
+	commit := fs.Bool("commit", false, "commit the snapshot to the history repository (see mlogin log)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	snap := takeSnapshot(c)
+	for _, w := range snap.Warnings {
+		warn(w)
+	}
+	if _, ok := timelineEnabled(); ok {
+		fillHashes(snap.Background)
+		if err := recordTimelineSnapshot(snap); err != nil {
+			warn("timeline:", err)
+		}
+	}
+	if *commit {
+		if *out != "" {
+			if err := writeSnapshot(*out, snap); err != nil {
+				return err
+			}
+		}
+		return commitSnapshot(snap)
+	}
+	if *out == "" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	}
+	if err := writeSnapshot(*out, snap); err != nil {
+		return err
+	}
+	successf("wrote snapshot to %s", *out)
+	return nil
+}
+
+// takeSnapshot collects the current state. Surfaces that cannot be read are
+// recorded as warnings so a partial snapshot is still written.
+func takeSnapshot(c mlogin.Client) Snapshot {
+	snap := Snapshot{SchemaVersion: snapshotSchemaVersion, CreatedAt: time.Now().UTC()}
+	snap.Hostname, _ = os.Hostname()
+
+	var err error
+	if snap.LoginItems, err = listLoginItems(c); err != nil {
+		snap.Warnings = append(snap.Warnings, "login items: "+err.Error())
+	}
+	items, warnings, err := listBackgroundItems(c, "all", false)
+	if err != nil {
+		snap.Warnings = append(snap.Warnings, "background items: "+err.Error())
+	}
+	snap.Background = withoutAppleItems(items)
+	snap.Warnings = append(snap.Warnings, warnings...)
+	if snap.Extensions, err = listSystemExtensions(c); err != nil {
+		snap.Warnings = append(snap.Warnings, "system extensions: "+err.Error())
+	}
+
+	var providers []auditProvider
+	for _, p := range auditProviders {
+		if !snapshotTypedSurfaces[p.surface] {
+			providers = append(providers, p)
+		}
+	}
+	snap.Surfaces = runAuditProviders(c, providers)
+	return snap
+}
+
+func writeSnapshot(path string, snap Snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// readSnapshot loads a snapshot written by this or an older mlogin.
+func readSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	if snap.SchemaVersion == 0 || snap.SchemaVersion > snapshotSchemaVersion {
+		return Snapshot{}, fmt.Errorf("%s: unsupported snapshot schema version %d", path, snap.SchemaVersion)
+	}
+	return snap, nil
+}
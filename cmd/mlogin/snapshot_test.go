@@ -0,0 +1,35 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	snap := Snapshot{
+		SchemaVersion: snapshotSchemaVersion,
+		CreatedAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		LoginItems:    []LoginItem{{Name: "Example", Path: "/Applications/Example.app"}},
+		Background:    []BackgroundItem{{Label: "com.example.agent", Scope: "user", Kind: "agent"}},
+	}
+	if err := writeSnapshot(path, snap); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := readSnapshot(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !got.CreatedAt.Equal(snap.CreatedAt) || len(got.LoginItems) != 1 || got.Background[0].Label != "com.example.agent" {
+		t.Fatalf("unexpected snapshot: %+v", got)
+	}
+
+	snap.SchemaVersion = snapshotSchemaVersion + 1
+	if err := writeSnapshot(path, snap); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := readSnapshot(path); err == nil {
+		t.Fatalf("expected newer schema version to be rejected")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// startupItemDirs hold pre-launchd StartupItems. macOS deprecated them long
+// ago, but leftovers from old installers (and malware imitating them) still
+// turn up, so they are listed with the "legacy" provenance.
+var startupItemDirs = []string{"/Library/StartupItems", "/System/Library/StartupItems"}
+
+// findStartupItems returns one item per StartupItems bundle: a directory
+// holding an executable of the same name and usually a
+// StartupParameters.plist.
+func findStartupItems() []BackgroundItem {
+	var items []BackgroundItem
+	for _, dir := range startupItemDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			items = append(items, BackgroundItem{
+				Label:      e.Name(),
+				Path:       filepath.Join(dir, e.Name()),
+				Scope:      "system",
+				Kind:       "startup",
+				Provenance: "legacy",
+			})
+		}
+	}
+	return items
+}
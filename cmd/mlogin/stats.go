@@ -0,0 +1,209 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// Stats is the overview `mlogin stats` prints: login items, third-party
+// launchd items, and system extensions counted together.
+type Stats struct {
+	Total    int            `json:"total"`
+	ByScope  map[string]int `json:"by_scope"`
+	ByKind   map[string]int `json:"by_kind"`
+	ByVendor map[string]int `json:"by_vendor"`
+	ByTeamID map[string]int `json:"by_team_id"`
+	Enabled  int            `json:"enabled"`
+	Disabled int            `json:"disabled"`
+	// Unsigned counts programs whose code signature is missing or invalid.
+	Unsigned int `json:"unsigned"`
+	// Recent are the launchd items whose plist was created or changed in
+	// the last RecentDays days.
+	Recent     []string `json:"recent"`
+	RecentDays int      `json:"recent_days"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// statsEntry is one item as stats counts it.
+type statsEntry struct {
+	Label    string
+	Scope    string
+	Kind     string
+	Vendor   string
+	TeamID   string
+	Disabled bool
+	Unsigned bool
+	// Changed is the plist's modification time, zero when there is none.
+	Changed time.Time
+}
+
+func runStats(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	days := fs.Int("days", 30, "count items added or changed in this many days as recent")
+	includeApple := fs.Bool("include-apple", false, "count Apple's own launchd items too")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	entries, errs := collectStatsEntries(c, *includeApple)
+	stats := summarizeStats(entries, time.Now().AddDate(0, 0, -*days))
+	stats.RecentDays = *days
+	stats.Errors = errs
+	if *jsonOut || outputFormat != "" {
+		return writeOutput(stats)
+	}
+	printStats(stats)
+	return nil
+}
+
+// collectStatsEntries gathers every surface stats counts. A surface that
+// cannot be read is reported instead of failing the overview.
+func collectStatsEntries(c mlogin.Client, includeApple bool) ([]statsEntry, []string) {
+	var entries []statsEntry
+	var errs []string
+
+	login, err := listLoginItems(c)
+	if err != nil {
+		errs = append(errs, "login items: "+err.Error())
+	}
+	for _, it := range login {
+		sig := verifyCodeSignature(it.Path)
+		entries = append(entries, statsEntry{Label: it.Name, Scope: "user", Kind: "login item", TeamID: sig.TeamID, Unsigned: !sig.Valid})
+	}
+
+	items, _, err := listBackgroundItems(c, "all", includeApple)
+	if err != nil {
+		errs = append(errs, "launchd: "+err.Error())
+	}
+	if !includeApple {
+		items = withoutAppleItems(items)
+	}
+	fillSignatures(items)
+	for _, it := range items {
+		e := statsEntry{Label: it.Label, Scope: it.Scope, Kind: it.Kind, Vendor: labelVendor(it.Label)}
+		if it.Disabled != nil {
+			e.Disabled = *it.Disabled
+		}
+		if it.Signature != nil {
+			e.TeamID, e.Unsigned = it.Signature.TeamID, !it.Signature.Valid
+		}
+		if it.Path != "" {
+			if info, err := os.Stat(it.Path); err == nil {
+				e.Changed = info.ModTime()
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	extensions, err := listSystemExtensions(c)
+	if err != nil {
+		errs = append(errs, "system extensions: "+err.Error())
+	}
+	for _, ext := range extensions {
+		entries = append(entries, statsEntry{
+			Label:    ext.BundleID,
+			Scope:    "system",
+			Kind:     "system extension",
+			Vendor:   labelVendor(ext.BundleID),
+			TeamID:   ext.TeamID,
+			Disabled: !ext.Enabled,
+		})
+	}
+	return entries, errs
+}
+
+// summarizeStats counts entries; those changed after since are recent.
+func summarizeStats(entries []statsEntry, since time.Time) Stats {
+	s := Stats{
+		ByScope:  map[string]int{},
+		ByKind:   map[string]int{},
+		ByVendor: map[string]int{},
+		ByTeamID: map[string]int{},
+		Recent:   []string{},
+	}
+	for _, e := range entries {
+		s.Total++
+		s.ByScope[e.Scope]++
+		s.ByKind[e.Kind]++
+		if e.Vendor != "" {
+			s.ByVendor[e.Vendor]++
+		}
+		if e.TeamID != "" {
+			s.ByTeamID[e.TeamID]++
+		}
+		if e.Disabled {
+			s.Disabled++
+		} else {
+			s.Enabled++
+		}
+		if e.Unsigned {
+			s.Unsigned++
+		}
+		if !e.Changed.IsZero() && e.Changed.After(since) {
+			s.Recent = append(s.Recent, e.Label)
+		}
+	}
+	sort.Strings(s.Recent)
+	return s
+}
+
+// labelVendor returns the vendor component of a reverse-DNS label or
+// bundle ID: "adobe" for com.adobe.acc.installer. Labels with fewer than
+// three components have none.
+func labelVendor(label string) string {
+	parts := strings.Split(strings.ToLower(label), ".")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[1]
+}
+
+func printStats(s Stats) {
+	row := func(name, value string) {
+		fmt.Printf("%s %s\n", headerStyle.Render(fmt.Sprintf("%-14s", name)), value)
+	}
+	row("Items", fmt.Sprintf("%d (%d enabled, %d disabled)", s.Total, s.Enabled, s.Disabled))
+	unsigned := fmt.Sprint(s.Unsigned)
+	if s.Unsigned > 0 {
+		unsigned = cautionStyle.Render(unsigned)
+	}
+	row("Unsigned", unsigned)
+	recent := fmt.Sprint(len(s.Recent))
+	if len(s.Recent) > 0 {
+		recent += ": " + strings.Join(s.Recent, ", ")
+	}
+	row(fmt.Sprintf("New (%dd)", s.RecentDays), recent)
+	row("By scope", formatCounts(s.ByScope, 0))
+	row("By kind", formatCounts(s.ByKind, 0))
+	row("By vendor", formatCounts(s.ByVendor, 8))
+	row("By Team ID", formatCounts(s.ByTeamID, 8))
+	for _, e := range s.Errors {
+		warn(e)
+	}
+}
+
+// formatCounts renders counts as "name n, ..." by descending count, keeping
+// the first limit (all when limit is 0).
+func formatCounts(counts map[string]int, limit int) string {
+	names := sortedKeys(counts)
+	sort.SliceStable(names, func(i, j int) bool { return counts[names[i]] > counts[names[j]] })
+	if len(names) == 0 {
+		return "-"
+	}
+	var parts []string
+	for i, name := range names {
+		if limit > 0 && i == limit {
+			parts = append(parts, fmt.Sprintf("+%d more", len(names)-limit))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarizeStats(t *testing.T) {
+	now := time.Now()
+	s := summarizeStats([]statsEntry{
+		{Label: "com.adobe.acc.installer", Scope: "system", Kind: "daemon", Vendor: "adobe", TeamID: "JQ525L2MZD", Changed: now.Add(-time.Hour)},
+		{Label: "com.adobe.agsservice", Scope: "system", Kind: "daemon", Vendor: "adobe", TeamID: "JQ525L2MZD", Disabled: true, Changed: now.AddDate(0, 0, -90)},
+		{Label: "Slack", Scope: "user", Kind: "login item", Unsigned: true},
+	}, now.AddDate(0, 0, -30))
+	if s.Total != 3 || s.Enabled != 2 || s.Disabled != 1 || s.Unsigned != 1 {
+		t.Fatalf("unexpected totals %+v", s)
+	}
+	if s.ByScope["system"] != 2 || s.ByKind["login item"] != 1 || s.ByVendor["adobe"] != 2 || s.ByTeamID["JQ525L2MZD"] != 2 {
+		t.Fatalf("unexpected counts %+v", s)
+	}
+	if strings.Join(s.Recent, ",") != "com.adobe.acc.installer" {
+		t.Fatalf("unexpected recent %v", s.Recent)
+	}
+	if got := formatCounts(map[string]int{"a": 1, "b": 3, "c": 2}, 2); got != "b 3, c 2, +1 more" {
+		t.Fatalf("formatCounts = %q", got)
+	}
+	if labelVendor("com.google.keystone.agent") != "google" || labelVendor("backup") != "" {
+		t.Fatalf("unexpected vendors")
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// targetUser is the account whose LaunchAgents and GUI domain background
+// commands operate on. It is nil for the invoking user and set by --user or
+// --uid when an administrator manages someone else's agents as root.
+var targetUser *user.User
+
+// addTargetUserFlags registers --user and --uid on fs. The returned function
+// must be called after parsing to apply them.
+func addTargetUserFlags(fs *flag.FlagSet) func() error {
+	name := fs.String("user", "", "operate on this user's agents (requires root)")
+	uid := fs.Int("uid", -1, "operate on the agents of this uid (requires root)")
+	return func() error {
+		var u *user.User
+		var err error
+		switch {
+		case *name != "" && *uid >= 0:
+			return usagef("use either --user or --uid, not both")
+		case *name != "":
+			u, err = user.Lookup(*name)
+		case *uid >= 0:
+			u, err = user.LookupId(strconv.Itoa(*uid))
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if u.Uid != strconv.Itoa(os.Getuid()) && os.Geteuid() != 0 {
+			return fmt.Errorf("managing %s's agents requires root (try sudo)", u.Username)
+		}
+		targetUser = u
+		return nil
+	}
+}
+
+// launchUser returns the user whose GUI domain and LaunchAgents are targeted.
+func launchUser() (*user.User, error) {
+	if targetUser != nil {
+		return targetUser, nil
+	}
+	return user.Current()
+}
+
+// launchUserHome returns the home directory of the targeted user.
+func launchUserHome() (string, error) {
+	if targetUser != nil {
+		return targetUser.HomeDir, nil
+	}
+	return os.UserHomeDir()
+}
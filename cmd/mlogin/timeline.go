@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The timeline store is opt-in: snapshot and watch only record into it
+// once `mlogin timeline init` has created the database. It is driven
+// through the sqlite3 tool that ships with macOS.
+const timelineSchema = `
+CREATE TABLE IF NOT EXISTS events (
+  id INTEGER PRIMARY KEY,
+  time TEXT NOT NULL,
+  source TEXT NOT NULL,
+  surface TEXT NOT NULL,
+  name TEXT NOT NULL,
+  change TEXT NOT NULL,
+  fields TEXT,
+  details TEXT
+);
+CREATE INDEX IF NOT EXISTS events_name ON events(name);
+CREATE TABLE IF NOT EXISTS state (
+  id INTEGER PRIMARY KEY CHECK (id = 1),
+  snapshot TEXT NOT NULL
+);
+`
+
+// TimelineEvent is a recorded change; Source is snapshot or watch.
+type TimelineEvent struct {
+	Source string `json:"source"`
+	WatchEvent
+}
+
+func timelinePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Application Support", "mlogin", "timeline.db"), nil
+}
+
+// timelineEnabled returns the database path when the store has been
+// initialized.
+func timelineEnabled() (string, bool) {
+	path, err := timelinePath()
+	if err != nil {
+		return "", false
+	}
+	_, err = os.Stat(path)
+	return path, err == nil
+}
+
+func runTimeline(args []string) error {
+	if len(args) > 0 && args[0] == "init" {
+		path, err := timelinePath()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+		if _, err := runSQLite(path, timelineSchema); err != nil {
+			return err
+		}
+		successf("timeline enabled at %s; mlogin snapshot and mlogin watch now record into it", path)
+		return nil
+	}
+	fs := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	label := labelFlag(fs, "label", "only show events for this label or item name")
+	n := fs.Int("n", 50, "show at most this many of the most recent events (0 for all)")
+	jsonOut := fs.Bool("json", false, "output JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, ok := timelineEnabled()
+	if !ok {
+		return errors.New("timeline is not enabled; run mlogin timeline init")
+	}
+	out, err := runSQLite(path, timelineQuery(*label, *n), "-json")
+	if err != nil {
+		return err
+	}
+	events, err := parseTimelineRows(out)
+	if err != nil {
+		return err
+	}
+	if *jsonOut || outputFormat != "" {
+		return writeOutput(events)
+	}
+	printTimeline(events)
+	return nil
+}
+
+func runSQLite(path, sql string, flags ...string) (string, error) {
+	cmd, done := toolCommand("sqlite3", append(flags, path)...)
+	cmd.Stdin = strings.NewReader(sql)
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		msg := ""
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("sqlite3 %s: %w: %s", path, err, msg)
+	}
+	return string(out), nil
+}
+
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// timelineInsertSQL builds one transaction that stores events.
+func timelineInsertSQL(source string, events []WatchEvent) string {
+	var b strings.Builder
+	b.WriteString("BEGIN;\n")
+	for _, e := range events {
+		fields, _ := json.Marshal(e.Fields)
+		details, _ := json.Marshal(e.Details)
+		fmt.Fprintf(&b, "INSERT INTO events (time, source, surface, name, change, fields, details) VALUES (%s, %s, %s, %s, %s, %s, %s);\n",
+			sqlQuote(e.Time.UTC().Format(time.RFC3339)), sqlQuote(source), sqlQuote(e.Surface), sqlQuote(e.Name), sqlQuote(e.Change), sqlQuote(string(fields)), sqlQuote(string(details)))
+	}
+	b.WriteString("COMMIT;\n")
+	return b.String()
+}
+
+// timelineQuery selects the most recent events, oldest first. A launchd
+// item's name carries its scope ("label (user)"), so label matches both.
+func timelineQuery(label string, n int) string {
+	where := ""
+	if label != "" {
+		where = fmt.Sprintf(" WHERE name = %s OR name LIKE %s ESCAPE '\\'", sqlQuote(label), sqlQuote(escapeLike(label)+" (%"))
+	}
+	limit := ""
+	if n > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", n)
+	}
+	return fmt.Sprintf("SELECT * FROM (SELECT id, time, source, surface, name, change, fields, details FROM events%s ORDER BY id DESC%s) ORDER BY id;\n", where, limit)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func parseTimelineRows(out string) ([]TimelineEvent, error) {
+	events := []TimelineEvent{}
+	if strings.TrimSpace(out) == "" {
+		return events, nil
+	}
+	var rows []struct {
+		ID      int64  `json:"id"`
+		Time    string `json:"time"`
+		Source  string `json:"source"`
+		Surface string `json:"surface"`
+		Name    string `json:"name"`
+		Change  string `json:"change"`
+		Fields  string `json:"fields"`
+		Details string `json:"details"`
+	}
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		return nil, fmt.Errorf("parse timeline: %w", err)
+	}
+	for _, r := range rows {
+		e := TimelineEvent{Source: r.Source, WatchEvent: WatchEvent{Surface: r.Surface, Name: r.Name, Change: r.Change}}
+		e.Time, _ = time.Parse(time.RFC3339, r.Time)
+		if err := json.Unmarshal([]byte(r.Fields), &e.Fields); err != nil {
+			return nil, fmt.Errorf("parse timeline event %d fields: %w", r.ID, err)
+		}
+		if err := json.Unmarshal([]byte(r.Details), &e.Details); err != nil {
+			return nil, fmt.Errorf("parse timeline event %d details: %w", r.ID, err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// recordTimelineEvents stores watch events when the timeline is enabled.
+func recordTimelineEvents(source string, events []WatchEvent) error {
+	path, ok := timelineEnabled()
+	if !ok || len(events) == 0 {
+		return nil
+	}
+	_, err := runSQLite(path, timelineInsertSQL(source, events))
+	return err
+}
+
+// recordTimelineSnapshot stores what changed since the last recorded
+// snapshot and keeps snap as the new reference. The first snapshot
+// records every item as seen.
+func recordTimelineSnapshot(snap Snapshot) error {
+	path, ok := timelineEnabled()
+	if !ok {
+		return nil
+	}
+	prevJSON, err := runSQLite(path, "SELECT snapshot FROM state WHERE id = 1;\n")
+	if err != nil {
+		return err
+	}
+	var events []WatchEvent
+	if strings.TrimSpace(prevJSON) == "" {
+		events = watchEvents(Snapshot{}, snap, snap.CreatedAt)
+		for i := range events {
+			events[i].Change = "seen"
+		}
+	} else {
+		var prev Snapshot
+		if err := json.Unmarshal([]byte(prevJSON), &prev); err != nil {
+			return fmt.Errorf("parse recorded snapshot: %w", err)
+		}
+		events = watchEvents(prev, snap, snap.CreatedAt)
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	sql := strings.Replace(timelineInsertSQL("snapshot", events), "COMMIT;", "INSERT OR REPLACE INTO state (id, snapshot) VALUES (1, "+sqlQuote(string(data))+");\nCOMMIT;", 1)
+	_, err = runSQLite(path, sql)
+	return err
+}
+
+// describeTimelineEvent says what happened in a few words, e.g.
+// "disabled" or "sha256 changed".
+func describeTimelineEvent(e TimelineEvent) string {
+	switch e.Change {
+	case "seen":
+		return "first seen"
+	case "added":
+		return "appeared"
+	case "removed":
+		return "removed"
+	}
+	var parts []string
+	for _, f := range e.Fields {
+		switch {
+		case f.Field == "disabled" && f.New == "true":
+			parts = append(parts, "disabled")
+		case f.Field == "disabled" && f.New == "false":
+			parts = append(parts, "enabled")
+		case f.Field == "loaded" && f.New == "true":
+			parts = append(parts, "loaded")
+		case f.Field == "loaded" && f.New == "false":
+			parts = append(parts, "unloaded")
+		default:
+			parts = append(parts, f.Field+" changed")
+		}
+	}
+	if len(parts) == 0 {
+		return "changed"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printTimeline(events []TimelineEvent) {
+	if len(events) == 0 {
+		fmt.Println("No events recorded")
+		return
+	}
+	for _, e := range events {
+		fmt.Printf("%s  %-8s  %-28s [%s] %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Source, describeTimelineEvent(e), e.Surface, e.Name)
+		for _, f := range e.Fields {
+			if f.Field == "sha256" {
+				fmt.Printf("    sha256: %s -> %s\n", orDash(f.Old), orDash(f.New))
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimelineSQL(t *testing.T) {
+	sql := timelineInsertSQL("watch", []WatchEvent{{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Surface: "login items", Name: "Bob's App", Change: "added"}})
+	if !strings.Contains(sql, "VALUES ('2026-01-02T03:04:05Z', 'watch', 'login items', 'Bob''s App', 'added', 'null', 'null');") {
+		t.Fatalf("unexpected insert SQL:\n%s", sql)
+	}
+	q := timelineQuery("com.foo_bar", 10)
+	if !strings.Contains(q, `WHERE name = 'com.foo_bar' OR name LIKE 'com.foo\_bar (%' ESCAPE '\'`) || !strings.Contains(q, "LIMIT 10") {
+		t.Fatalf("unexpected query: %s", q)
+	}
+}
+
+func TestParseTimelineRows(t *testing.T) {
+	out := `[{"id":1,"time":"2026-01-02T03:04:05Z","source":"snapshot","surface":"launchd","name":"com.foo (user)","change":"changed","fields":"[{\"field\":\"disabled\",\"old\":\"false\",\"new\":\"true\"},{\"field\":\"sha256\",\"old\":\"aa\",\"new\":\"bb\"}]","details":"null"}]`
+	events, err := parseTimelineRows(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Source != "snapshot" || len(events[0].Fields) != 2 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if got := describeTimelineEvent(events[0]); got != "disabled, sha256 changed" {
+		t.Fatalf("describeTimelineEvent = %q", got)
+	}
+	if events, err := parseTimelineRows(""); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events for empty output, got %v, %v", events, err)
+	}
+	corrupt := `[{"id":7,"time":"2026-01-02T03:04:05Z","source":"watch","surface":"launchd","name":"com.foo","change":"changed","fields":"[{\"field\"","details":"null"}]`
+	if _, err := parseTimelineRows(corrupt); err == nil || !strings.Contains(err.Error(), "event 7") {
+		t.Fatalf("expected an error naming the corrupt row, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandTimeout is the global --timeout: how long one external command
+// (osascript, launchctl, systemextensionsctl, ...) may run before it is
+// killed. Zero waits forever. osascript in particular can block
+// indefinitely behind an Automation prompt nobody answers.
+var commandTimeout = time.Minute
+
+// timeoutFlag is the --timeout value as given, applied by setCommandTimeout
+// once the global flags are parsed.
+var timeoutFlag string
+
+// setCommandTimeout parses a --timeout value; "" keeps the default.
+func setCommandTimeout(value string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return usagef("--timeout must be a duration like 30s or 2m, not %q", value)
+	}
+	commandTimeout = d
+	return nil
+}
+
+// toolCommand prepares an external command that is killed once
+// --timeout passes. Its error must go through the returned done func,
+// which releases the timer, reports the duration under --verbose, and
+// turns a kill into an error that says what timed out:
+//
+//	cmd, done := toolCommand("launchctl", "list")
+//	out, err := cmd.Output()
+//	if err = done(err); err != nil {
+func toolCommand(name string, args ...string) (*exec.Cmd, func(error) error) {
+	return toolCommandContext(context.Background(), name, args...)
+}
+
+// toolCommandContext is toolCommand for a command that also ends with ctx.
+func toolCommandContext(ctx context.Context, name string, args ...string) (*exec.Cmd, func(error) error) {
+	cancel := context.CancelFunc(func() {})
+	if commandTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, commandTimeout)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	traced := traceCommand(cmd)
+	return cmd, func(err error) error {
+		traced()
+		defer cancel()
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &timeoutError{command: displayCommand(cmd.Args), after: commandTimeout}
+		}
+		return err
+	}
+}
+
+// timeoutError is returned for a command --timeout killed.
+type timeoutError struct {
+	command string
+	after   time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	msg := fmt.Sprintf("%s timed out after %s (raise it with --timeout)", e.command, e.after)
+	if strings.HasPrefix(e.command, "osascript") {
+		msg += "; System Events may be waiting for you to allow Automation in System Settings > Privacy & Security"
+	}
+	return msg
+}
+
+func (e *timeoutError) Unwrap() error { return context.DeadlineExceeded }
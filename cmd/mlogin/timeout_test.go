@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolCommandTimeout(t *testing.T) {
+	defer func(d time.Duration) { commandTimeout = d }(commandTimeout)
+	commandTimeout = 50 * time.Millisecond
+	cmd, done := toolCommand("sleep", "5")
+	err := done(cmd.Run())
+	var timeout *timeoutError
+	if !errors.As(err, &timeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "sleep 5 timed out after 50ms") {
+		t.Fatalf("unexpected message %q", err)
+	}
+	if exitCode(err) != exitExternal {
+		t.Fatalf("exitCode = %d, want %d", exitCode(err), exitExternal)
+	}
+}
+
+func TestParseGlobalFlagsTimeout(t *testing.T) {
+	defer func() { timeoutFlag = "" }()
+	args := parseGlobalFlags([]string{"--timeout", "5s", "fleet", "--timeout=30s", "--hosts", "a"})
+	if timeoutFlag != "5s" || strings.Join(args, " ") != "fleet --timeout=30s --hosts a" {
+		t.Fatalf("unexpected timeout %q and args %v", timeoutFlag, args)
+	}
+	if err := setCommandTimeout("soon"); err == nil {
+		t.Fatalf("expected an error for a bad duration")
+	}
+}
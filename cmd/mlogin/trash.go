@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// trashEntry records where a deleted plist came from so it can be restored.
+type trashEntry struct {
+	Label     string    `json:"label"`
+	Scope     string    `json:"scope"`
+	Path      string    `json:"path"`
+	Mode      uint32    `json:"mode"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Backup    string    `json:"backup"`
+}
+
+// trashDir is where deleted plists are kept until restored or cleaned up.
+func trashDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Application Support", "mlogin", "trash"), nil
+}
+
+// writeOK is access(2)'s W_OK.
+const writeOK = 0x2
+
+// moveToTrash moves the plist into the mlogin trash and writes a metadata
+// file next to it. It returns the backup location. A plist in a directory
+// the caller cannot write to, such as a root-owned /Library/LaunchDaemons,
+// is copied and then removed with the same escalation launchctl uses.
+func moveToTrash(label, scope, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	privileged := syscall.Access(filepath.Dir(path), writeOK) != nil
+	if privileged && (escalation == escalateNone || os.Geteuid() == 0) {
+		return "", fmt.Errorf("cannot remove %s: %s is not writable (try sudo)", path, filepath.Dir(path))
+	}
+	dir, err := trashDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	id := now.UTC().Format("20060102T150405.000000000Z") + "-" + label
+	backup := filepath.Join(dir, id+".plist")
+	if privileged {
+		if err := copyFile(path, backup); err != nil {
+			return "", err
+		}
+		if _, err := runPrivileged("/bin/rm", "-f", path); err != nil {
+			os.Remove(backup)
+			return "", fmt.Errorf("remove %s: %w", path, err)
+		}
+	} else if err := moveFile(path, backup); err != nil {
+		return "", err
+	}
+	entry := trashEntry{
+		Label:     label,
+		Scope:     scope,
+		Path:      path,
+		Mode:      uint32(info.Mode().Perm()),
+		DeletedAt: now,
+		Backup:    backup,
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), data, 0o600); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// moveFile renames src to dst, copying across volumes when needed. A copy
+// whose source cannot be removed is undone.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// copyFile copies src to a new file dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// listTrash returns trashed items, most recently deleted first.
+func listTrash() ([]trashEntry, error) {
+	dir, err := trashDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []trashEntry
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var e trashEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// restoreBackgroundItem moves the most recently trashed plist (optionally for
+// a specific label) back to its original location.
+func restoreBackgroundItem(c mlogin.Client, label string, load bool) error {
+	entries, err := listTrash()
+	if err != nil {
+		return err
+	}
+	var entry *trashEntry
+	for i := range entries {
+		if label == "" || entries[i].Label == label {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		if label != "" {
+			return fmt.Errorf("no deleted item for label %q in trash", label)
+		}
+		return errors.New("trash is empty")
+	}
+
+	if _, err := os.Stat(entry.Path); err == nil {
+		return fmt.Errorf("%s already exists; not overwriting", entry.Path)
+	}
+	if !dryRunf("move %s back to %s", entry.Backup, entry.Path) {
+		err := restoreTrashEntry(*entry)
+		recordAction("background restore", entry.Label, entry.Scope, entry.Backup, err)
+		if err != nil {
+			return err
+		}
+	}
+	successf("restored %s (%s)", entry.Label, entry.Path)
+
+	if !load {
+		return nil
+	}
+	return reloadBackgroundPlist(c, BackgroundItem{Label: entry.Label, Path: entry.Path, Scope: entry.Scope})
+}
+
+// restoreTrashEntry moves a deleted plist back into place with its original
+// mode and, for the system scope, root ownership.
+func restoreTrashEntry(entry trashEntry) error {
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0o755); err != nil {
+		return err
+	}
+	if err := moveFile(entry.Backup, entry.Path); err != nil {
+		return fmt.Errorf("restore %s: %w", entry.Path, err)
+	}
+	if err := os.Chmod(entry.Path, os.FileMode(entry.Mode)); err != nil {
+		return err
+	}
+	if entry.Scope == "system" {
+		if err := os.Chown(entry.Path, 0, 0); err != nil {
+			return fmt.Errorf("chown %s to root:wheel (try sudo): %w", entry.Path, err)
+		}
+	}
+	_ = os.Remove(strings.TrimSuffix(entry.Backup, ".plist") + ".json")
+	return nil
+}
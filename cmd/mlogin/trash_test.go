@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	agents := filepath.Join(home, "Library", "LaunchAgents")
+	if err := os.MkdirAll(agents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plist := filepath.Join(agents, "com.foo.agent.plist")
+	if err := os.WriteFile(plist, []byte("<plist/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := moveToTrash("com.foo.agent", "user", plist)
+	if err != nil {
+		t.Fatalf("moveToTrash: %v", err)
+	}
+	if _, err := os.Stat(plist); !os.IsNotExist(err) {
+		t.Fatalf("expected plist to be moved, stat err=%v", err)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Fatalf("expected backup at %s: %v", backup, err)
+	}
+
+	entries, err := listTrash()
+	if err != nil || len(entries) != 1 || entries[0].Label != "com.foo.agent" {
+		t.Fatalf("unexpected trash entries: %+v (err=%v)", entries, err)
+	}
+
+	if err := restoreBackgroundItem(mlogin.Client{Runner: &cannedRunner{}}, "com.foo.agent", false); err != nil {
+		t.Fatalf("restoreBackgroundItem: %v", err)
+	}
+	info, err := os.Stat(plist)
+	if err != nil {
+		t.Fatalf("expected restored plist: %v", err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("unexpected restored mode %v", info.Mode().Perm())
+	}
+	if entries, _ := listTrash(); len(entries) != 0 {
+		t.Fatalf("expected trash to be empty, got %+v", entries)
+	}
+}
+
+func TestTrashUnwritableDirFailsBeforeCopying(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to any directory")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	daemons := filepath.Join(home, "LaunchDaemons")
+	if err := os.MkdirAll(daemons, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plist := filepath.Join(daemons, "com.foo.daemon.plist")
+	if err := os.WriteFile(plist, []byte("<plist/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(daemons, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(daemons, 0o755)
+	defer func() { escalation = escalateSudo }()
+	escalation = escalateNone
+
+	if _, err := moveToTrash("com.foo.daemon", "system", plist); err == nil {
+		t.Fatalf("expected an error for an unwritable directory")
+	}
+	if entries, _ := listTrash(); len(entries) != 0 {
+		t.Fatalf("expected nothing in the trash, got %+v", entries)
+	}
+	if _, err := os.Stat(plist); err != nil {
+		t.Fatalf("expected plist to stay: %v", err)
+	}
+}
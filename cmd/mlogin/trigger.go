@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// describeTrigger renders what makes launchd start a job, for example
+// "every 1h", "daily 03:00", or "on path change".
+func describeTrigger(m map[string]any) string {
+	var parts []string
+	if v, ok := plistInt(m, "StartInterval"); ok && v > 0 {
+		parts = append(parts, "every "+humanSeconds(v))
+	}
+	switch cal := m["StartCalendarInterval"].(type) {
+	case map[string]any:
+		parts = append(parts, describeCalendar(cal))
+	case []any:
+		for _, e := range cal {
+			if d, ok := e.(map[string]any); ok {
+				parts = append(parts, describeCalendar(d))
+			}
+		}
+	}
+	if _, ok := m["WatchPaths"]; ok {
+		parts = append(parts, "on path change")
+	}
+	if _, ok := m["QueueDirectories"]; ok {
+		parts = append(parts, "on queue")
+	}
+	if v, _ := m["StartOnMount"].(bool); v {
+		parts = append(parts, "on mount")
+	}
+	if _, ok := m["Sockets"]; ok {
+		parts = append(parts, "on socket")
+	}
+	switch ka := m["KeepAlive"].(type) {
+	case bool:
+		if ka {
+			parts = append(parts, "always")
+		}
+	case map[string]any:
+		parts = append(parts, "keep alive (conditional)")
+	}
+	if v, _ := m["RunAtLoad"].(bool); v {
+		parts = append(parts, "at load")
+	}
+	if len(parts) == 0 {
+		if _, ok := m["MachServices"]; ok {
+			return "on demand"
+		}
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeCalendar renders one StartCalendarInterval dict. Missing fields
+// are wildcards in launchd, shown as "*".
+func describeCalendar(c map[string]any) string {
+	minute, hasMinute := plistInt(c, "Minute")
+	hour, hasHour := plistInt(c, "Hour")
+	day, hasDay := plistInt(c, "Day")
+	weekday, hasWeekday := plistInt(c, "Weekday")
+	month, hasMonth := plistInt(c, "Month")
+
+	clock := func() string {
+		h, mm := "*", "*"
+		if hasHour {
+			h = fmt.Sprintf("%02d", hour)
+		}
+		if hasMinute {
+			mm = fmt.Sprintf("%02d", minute)
+		}
+		return h + ":" + mm
+	}
+	switch {
+	case hasMonth:
+		d := "*"
+		if hasDay {
+			d = fmt.Sprint(day)
+		}
+		return fmt.Sprintf("yearly %d/%s %s", month, d, clock())
+	case hasDay:
+		return fmt.Sprintf("monthly day %d %s", day, clock())
+	case hasWeekday && weekday >= 0 && int(weekday) < len(weekdayNames):
+		return fmt.Sprintf("weekly %s %s", weekdayNames[weekday], clock())
+	case hasHour:
+		return "daily " + clock()
+	case hasMinute:
+		return fmt.Sprintf("hourly at :%02d", minute)
+	default:
+		return "every minute"
+	}
+}
+
+// humanSeconds formats a duration in seconds compactly: 90 -> "1m30s",
+// 3600 -> "1h", 86400 -> "1d".
+func humanSeconds(n int64) string {
+	units := []struct {
+		suffix string
+		size   int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+	var b strings.Builder
+	for _, u := range units {
+		if n >= u.size {
+			fmt.Fprintf(&b, "%d%s", n/u.size, u.suffix)
+			n %= u.size
+		}
+	}
+	if b.Len() == 0 {
+		return "0s"
+	}
+	return b.String()
+}
+
+// fillTriggers reads each item's plist and sets its Trigger description.
+func fillTriggers(items []BackgroundItem) {
+	var cache triggerCache
+	cache.fill(items)
+}
+
+// triggerCache remembers each plist's trigger by modification time, so a
+// refresh only reads the plists that changed since the last one.
+type triggerCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedTrigger
+}
+
+type cachedTrigger struct {
+	modTime time.Time
+	trigger string
+}
+
+// fill sets the Trigger of each item with a plist, reading only plists that
+// are new or modified.
+func (c *triggerCache) fill(items []BackgroundItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]cachedTrigger{}
+	}
+	for i := range items {
+		path := items[i].Path
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if e, ok := c.entries[path]; ok && e.modTime.Equal(info.ModTime()) {
+			items[i].Trigger = e.trigger
+			continue
+		}
+		plist, err := readPlist(path)
+		if err != nil {
+			continue
+		}
+		items[i].Trigger = describeTrigger(plist)
+		c.entries[path] = cachedTrigger{modTime: info.ModTime(), trigger: items[i].Trigger}
+	}
+}
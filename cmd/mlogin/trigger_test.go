@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestDescribeTrigger(t *testing.T) {
+	cases := []struct {
+		plist map[string]any
+		want  string
+	}{
+		{plist: map[string]any{"StartInterval": int64(3600)}, want: "every 1h"},
+		{plist: map[string]any{"StartInterval": int64(90), "RunAtLoad": true}, want: "every 1m30s, at load"},
+		{plist: map[string]any{"StartCalendarInterval": map[string]any{"Hour": int64(3), "Minute": int64(0)}}, want: "daily 03:00"},
+		{plist: map[string]any{"StartCalendarInterval": []any{
+			map[string]any{"Weekday": int64(1), "Hour": int64(9), "Minute": int64(30)},
+			map[string]any{"Minute": int64(15)},
+		}}, want: "weekly Mon 09:30, hourly at :15"},
+		{plist: map[string]any{"WatchPaths": []any{"/tmp/x"}}, want: "on path change"},
+		{plist: map[string]any{"KeepAlive": true}, want: "always"},
+		{plist: map[string]any{"MachServices": map[string]any{"com.foo.xpc": true}}, want: "on demand"},
+		{plist: map[string]any{}, want: "-"},
+	}
+	for _, tc := range cases {
+		if got := describeTrigger(tc.plist); got != tc.want {
+			t.Fatalf("describeTrigger(%v) = %q, want %q", tc.plist, got, tc.want)
+		}
+	}
+}
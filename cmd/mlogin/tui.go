@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
 	"golang.org/x/term"
 )
 
@@ -17,6 +18,8 @@ const (
 	tabLogin uiTab = iota
 	tabBackground
 	tabExtensions
+	tabCron
+	tabCount
 )
 
 type loginLoadedMsg struct {
@@ -35,6 +38,24 @@ type actionDoneMsg struct {
 	err    error
 }
 
+// blameDoneMsg reports a read-only lookup, so unlike actionDoneMsg it does
+// not trigger a refresh that would overwrite the status line.
+type blameDoneMsg struct {
+	status string
+	err    error
+}
+
+type backgroundInfoMsg struct {
+	info ServiceInfo
+	err  error
+}
+
+type cronLoadedMsg struct {
+	entries  []CronEntry
+	warnings []string
+	err      error
+}
+
 type extensionsLoadedMsg struct {
 	items []SystemExtensionItem
 	err   error
@@ -53,27 +74,81 @@ type uiModel struct {
 	bgRows     []int
 	extItems   []SystemExtensionItem
 	extRows    []int
+	cronItems  []CronEntry
+	cronRows   []int
 	warnings   []string
 
 	filter       string
 	filterActive bool
+	hideApple    bool
 	confirmMode  bool
 	confirmText  string
 	pendingBGDel *BackgroundItem
+	detail       *ServiceInfo
 	status       string
 	err          error
+
+	// client lists and changes items for the model's commands.
+	client mlogin.Client
+	// triggers keeps background triggers between refreshes.
+	triggers *triggerCache
+}
+
+// tuiDefaultKeys are the TUI's actions and their keys. The config's
+// tui.keys binds them to other keys.
+var tuiDefaultKeys = map[string]string{
+	"quit":         "q",
+	"next-tab":     "tab",
+	"prev-tab":     "shift+tab",
+	"refresh":      "r",
+	"filter":       "/",
+	"clear-filter": "c",
+	"delete":       "x",
+	"apple":        "a",
+	"info":         "i",
+	"blame":        "w",
+	"enable":       "e",
+	"disable":      "d",
 }
 
+// tuiKey maps a pressed key to the default key of the action the config
+// binds it to, so Update only deals in default keys.
+func tuiKey(pressed string) string {
+	for action, key := range config.TUI.Keys {
+		if key == pressed {
+			return tuiDefaultKeys[action]
+		}
+	}
+	return pressed
+}
+
+// tuiKeyName is the key action is bound to, for the help line.
+func tuiKeyName(action string) string {
+	if key, ok := config.TUI.Keys[action]; ok {
+		return key
+	}
+	return tuiDefaultKeys[action]
+}
+
+// tuiDryRun collects what TUI actions would do under --dry-run, for the
+// status line.
+var tuiDryRun = &dryRunBuffer{}
+
 func runTUI() error {
 	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
 		return fmt.Errorf("tui mode requires an interactive terminal")
 	}
-	p := tea.NewProgram(newUIModel(), tea.WithAltScreen())
+	if escalation == escalateSudo {
+		// sudo cannot prompt while the TUI owns the terminal.
+		escalation = escalateOsascript
+	}
+	dryRunOutput = tuiDryRun
+	p := tea.NewProgram(newUIModel(client()), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newUIModel() uiModel {
+func newUIModel(c mlogin.Client) uiModel {
 	t := table.New(
 		table.WithColumns([]table.Column{{Title: "Loading...", Width: 20}}),
 		table.WithRows(nil),
@@ -93,40 +168,51 @@ func newUIModel() uiModel {
 	t.SetStyles(styles)
 
 	return uiModel{
-		tab:    tabLogin,
-		table:  t,
-		status: "Loading login/background items...",
+		tab:       tabLogin,
+		table:     t,
+		hideApple: defaultHideApple(),
+		status:    "Loading login/background items...",
+		client:    c,
+		triggers:  &triggerCache{},
 	}
 }
 
 func (m uiModel) Init() tea.Cmd {
-	return tea.Batch(refreshLoginCmd(), refreshBackgroundCmd(), refreshExtensionsCmd())
+	return tea.Batch(refreshLoginCmd(m.client), refreshBackgroundCmd(m.client, m.triggers), refreshExtensionsCmd(m.client), refreshCronCmd())
 }
 
-func refreshLoginCmd() tea.Cmd {
+func refreshLoginCmd(c mlogin.Client) tea.Cmd {
 	return func() tea.Msg {
-		items, err := listLoginItems()
+		items, err := listLoginItems(c)
 		return loginLoadedMsg{items: items, err: err}
 	}
 }
 
-func refreshBackgroundCmd() tea.Cmd {
+func refreshBackgroundCmd(c mlogin.Client, triggers *triggerCache) tea.Cmd {
 	return func() tea.Msg {
-		items, warnings, err := listBackgroundItems("all")
+		items, warnings, err := listBackgroundItems(c, "all", false)
+		triggers.fill(items)
 		return backgroundLoadedMsg{items: items, warnings: warnings, err: err}
 	}
 }
 
-func refreshExtensionsCmd() tea.Cmd {
+func refreshExtensionsCmd(c mlogin.Client) tea.Cmd {
 	return func() tea.Msg {
-		items, err := listSystemExtensions()
+		items, err := listSystemExtensions(c)
 		return extensionsLoadedMsg{items: items, err: err}
 	}
 }
 
-func removeLoginCmd(path string) tea.Cmd {
+func refreshCronCmd() tea.Cmd {
+	return func() tea.Msg {
+		entries, warnings, err := listCronEntries()
+		return cronLoadedMsg{entries: entries, warnings: warnings, err: err}
+	}
+}
+
+func removeLoginCmd(c mlogin.Client, path string) tea.Cmd {
 	return func() tea.Msg {
-		err := removeLoginItem("", path)
+		err := removeLoginItem(c, "", path)
 		if err != nil {
 			return actionDoneMsg{err: err}
 		}
@@ -134,9 +220,10 @@ func removeLoginCmd(path string) tea.Cmd {
 	}
 }
 
-func toggleBackgroundCmd(label, scope string, enable bool) tea.Cmd {
+func toggleBackgroundCmd(c mlogin.Client, item BackgroundItem, enable bool) tea.Cmd {
 	return func() tea.Msg {
-		domain, err := launchDomain(scope)
+		label, scope := item.Label, item.Scope
+		domain, err := launchDomain(c, scope)
 		if err != nil {
 			return actionDoneMsg{err: err}
 		}
@@ -144,7 +231,9 @@ func toggleBackgroundCmd(label, scope string, enable bool) tea.Cmd {
 		if enable {
 			verb = "enable"
 		}
-		err = runLaunchctl(verb, domain+"/"+label)
+		previous := launchdPreviousState(c, domain, label)
+		err = toggleService(c, verb, domain+"/"+label)
+		recordAction("background "+verb, domain+"/"+label, scope, previous, err)
 		if err != nil {
 			return actionDoneMsg{err: err}
 		}
@@ -152,13 +241,33 @@ func toggleBackgroundCmd(label, scope string, enable bool) tea.Cmd {
 		if enable {
 			state = "enabled"
 		}
+		if !enable && managedWarning(item) != "" {
+			return actionDoneMsg{status: fmt.Sprintf("%s %s (managed by MDM; a profile will re-enable it)", state, label)}
+		}
 		return actionDoneMsg{status: fmt.Sprintf("%s %s", state, label)}
 	}
 }
 
-func deleteBackgroundCmd(item BackgroundItem) tea.Cmd {
+func backgroundInfoCmd(c mlogin.Client, item BackgroundItem) tea.Cmd {
 	return func() tea.Msg {
-		err := deleteBackgroundItem(item.Label, item.Path, item.Scope)
+		info, err := loadServiceInfo(c, item)
+		return backgroundInfoMsg{info: info, err: err}
+	}
+}
+
+func blameBackgroundCmd(c mlogin.Client, item BackgroundItem) tea.Cmd {
+	return func() tea.Msg {
+		reason, err := blameLaunchService(c, item)
+		if err != nil {
+			return blameDoneMsg{err: err}
+		}
+		return blameDoneMsg{status: fmt.Sprintf("%s last launched by: %s", item.Label, reason)}
+	}
+}
+
+func deleteBackgroundCmd(c mlogin.Client, item BackgroundItem) tea.Cmd {
+	return func() tea.Msg {
+		err := deleteBackgroundItem(c, item.Label, item.Path, item.Scope, false)
 		if err != nil {
 			return actionDoneMsg{err: err}
 		}
@@ -196,6 +305,24 @@ func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.rebuildTable(0)
 		return m, nil
+	case blameDoneMsg:
+		m.err = msg.err
+		m.status = msg.status
+		if msg.err != nil {
+			m.status = "Blame failed"
+		}
+		return m, nil
+	case backgroundInfoMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.status = "Failed to load details"
+			return m, nil
+		}
+		m.err = nil
+		m.detail = &msg.info
+		m.status = "Details for " + msg.info.Label
+		m.rebuildTable(m.table.Cursor())
+		return m, nil
 	case extensionsLoadedMsg:
 		if msg.err != nil {
 			m.err = msg.err
@@ -207,6 +334,20 @@ func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.rebuildTable(0)
 		return m, nil
+	case cronLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.status = "Failed to load cron jobs"
+		} else {
+			m.cronItems = msg.entries
+			m.status = fmt.Sprintf("Loaded %d cron jobs", len(msg.entries))
+			m.err = nil
+			if len(msg.warnings) > 0 {
+				m.status += " (" + strings.Join(msg.warnings, " | ") + ")"
+			}
+		}
+		m.rebuildTable(0)
+		return m, nil
 	case actionDoneMsg:
 		if msg.err != nil {
 			m.err = msg.err
@@ -215,13 +356,22 @@ func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.err = nil
 		m.status = msg.status
+		if dryRun {
+			// Nothing changed, so keep the status line instead of
+			// refreshing over it.
+			m.status = "Dry run: " + strings.Join(tuiDryRun.take(), "; ")
+			return m, nil
+		}
 		if m.tab == tabLogin {
-			return m, refreshLoginCmd()
+			return m, refreshLoginCmd(m.client)
 		}
 		if m.tab == tabExtensions {
-			return m, refreshExtensionsCmd()
+			return m, refreshExtensionsCmd(m.client)
+		}
+		if m.tab == tabCron {
+			return m, refreshCronCmd()
 		}
-		return m, refreshBackgroundCmd()
+		return m, refreshBackgroundCmd(m.client, m.triggers)
 	case tea.KeyMsg:
 		if m.confirmMode {
 			switch msg.String() {
@@ -234,7 +384,7 @@ func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.confirmMode = false
 					m.confirmText = ""
 					m.status = "Deleting background item..."
-					return m, deleteBackgroundCmd(item)
+					return m, deleteBackgroundCmd(m.client, item)
 				}
 				m.pendingBGDel = nil
 				m.confirmMode = false
@@ -274,28 +424,33 @@ func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		switch msg.String() {
+		key := tuiKey(msg.String())
+		switch key {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 		case "tab", "right", "l":
-			m.tab = (m.tab + 1) % 3
+			m.tab = (m.tab + 1) % tabCount
 			m.rebuildTable(0)
 			return m, nil
 		case "shift+tab", "left", "h":
-			m.tab = (m.tab + 2) % 3
+			m.tab = (m.tab + tabCount - 1) % tabCount
 			m.rebuildTable(0)
 			return m, nil
 		case "r":
 			if m.tab == tabLogin {
 				m.status = "Refreshing login items..."
-				return m, refreshLoginCmd()
+				return m, refreshLoginCmd(m.client)
 			}
 			if m.tab == tabExtensions {
 				m.status = "Refreshing system extensions..."
-				return m, refreshExtensionsCmd()
+				return m, refreshExtensionsCmd(m.client)
+			}
+			if m.tab == tabCron {
+				m.status = "Refreshing cron jobs..."
+				return m, refreshCronCmd()
 			}
 			m.status = "Refreshing background items..."
-			return m, refreshBackgroundCmd()
+			return m, refreshBackgroundCmd(m.client, m.triggers)
 		case "/", "f":
 			m.filterActive = true
 			m.status = "Filter mode: type to filter, enter/esc to finish"
@@ -314,27 +469,66 @@ func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				m.status = "Removing login item..."
-				return m, removeLoginCmd(item.Path)
+				return m, removeLoginCmd(m.client, item.Path)
 			}
 			if m.tab == tabBackground {
 				item, ok := m.selectedBackgroundItem()
 				if !ok {
 					return m, nil
 				}
+				if confirmationsOff() {
+					m.status = "Deleting background item..."
+					return m, deleteBackgroundCmd(m.client, item)
+				}
 				m.pendingBGDel = &item
 				m.confirmMode = true
-				m.confirmText = fmt.Sprintf("Delete %s and remove plist file? (y/n)", item.Label)
+				m.confirmText = fmt.Sprintf("Delete %s and move its plist to the mlogin trash? (y/n)", item.Label)
 				return m, nil
 			}
+		case "a":
+			if m.tab == tabBackground {
+				m.hideApple = !m.hideApple
+				m.rebuildTable(0)
+				m.status = "Showing Apple items"
+				if m.hideApple {
+					m.status = "Hiding Apple items"
+				}
+			}
+			return m, nil
+		case "w":
+			if m.tab == tabBackground {
+				item, ok := m.selectedBackgroundItem()
+				if !ok {
+					return m, nil
+				}
+				m.status = "Asking launchd why it last launched " + item.Label + "..."
+				return m, blameBackgroundCmd(m.client, item)
+			}
+			return m, nil
+		case "i":
+			if m.tab == tabBackground {
+				if m.detail != nil {
+					m.detail = nil
+					m.rebuildTable(m.table.Cursor())
+					return m, nil
+				}
+				item, ok := m.selectedBackgroundItem()
+				if !ok {
+					return m, nil
+				}
+				m.status = "Loading details..."
+				return m, backgroundInfoCmd(m.client, item)
+			}
+			return m, nil
 		case "e", "d":
 			if m.tab == tabBackground {
 				item, ok := m.selectedBackgroundItem()
 				if !ok {
 					return m, nil
 				}
-				enable := msg.String() == "e"
+				enable := key == "e"
 				m.status = "Applying background item change..."
-				return m, toggleBackgroundCmd(item.Label, item.Scope, enable)
+				return m, toggleBackgroundCmd(m.client, item, enable)
 			}
 		}
 	}
@@ -370,10 +564,14 @@ func (m *uiModel) selectedBackgroundItem() (BackgroundItem, bool) {
 
 func (m *uiModel) rebuildTable(cursor int) {
 	tableHeight := max(4, m.height-8)
+	if m.tab == tabBackground && m.detail != nil {
+		tableHeight = max(4, tableHeight-len(serviceInfoLines(*m.detail))-1)
+	}
 	m.table.SetHeight(tableHeight)
 	// Bubble table renders existing rows during SetColumns; clear rows first
 	// so tab switches across schemas don't panic on mismatched row widths.
 	m.table.SetRows(nil)
+	filter := tuiFilter(m.filter)
 
 	if m.tab == tabLogin {
 		nameW := max(20, m.width/5)
@@ -387,7 +585,7 @@ func (m *uiModel) rebuildTable(cursor int) {
 		rows := make([]table.Row, 0, len(m.loginItems))
 		m.loginRows = nil
 		for i, it := range m.loginItems {
-			if !matchesLoginFilter(it, m.filter) {
+			if !filter.match(loginFilterFields(it)...) {
 				continue
 			}
 			rows = append(rows, table.Row{it.Name, fmt.Sprintf("%t", it.Hidden), it.Path})
@@ -400,30 +598,68 @@ func (m *uiModel) rebuildTable(cursor int) {
 			kindW := 8
 			loadedW := 8
 			disabledW := 8
+			healthW := 8
+			triggerW := 16
 			labelW := max(22, m.width/4)
-			pathW := max(25, m.width-scopeW-kindW-loadedW-disabledW-labelW-12)
+			pathW := max(25, m.width-scopeW-kindW-loadedW-disabledW-healthW-triggerW-labelW-16)
 			m.table.SetColumns([]table.Column{
 				{Title: "Scope", Width: scopeW},
 				{Title: "Kind", Width: kindW},
 				{Title: "Loaded", Width: loadedW},
 				{Title: "Disabled", Width: disabledW},
+				{Title: "Health", Width: healthW},
+				{Title: "Trigger", Width: triggerW},
 				{Title: "Label", Width: labelW},
 				{Title: "Path", Width: pathW},
 			})
 			rows := make([]table.Row, 0, len(m.bgItems))
 			m.bgRows = nil
 			for i, it := range m.bgItems {
-				if !matchesBackgroundFilter(it, m.filter) {
+				if m.hideApple && isAppleLabel(it.Label) {
+					continue
+				}
+				if !filter.match(backgroundFilterFields(it)...) {
 					continue
 				}
 				disabled := "?"
 				if it.Disabled != nil {
 					disabled = fmt.Sprintf("%t", *it.Disabled)
 				}
-				rows = append(rows, table.Row{it.Scope, it.Kind, fmt.Sprintf("%t", it.Loaded), disabled, it.Label, it.Path})
+				health := it.Health
+				if health == "" {
+					health = "-"
+				}
+				trigger := it.Trigger
+				if trigger == "" {
+					trigger = "-"
+				}
+				rows = append(rows, table.Row{it.Scope, it.Kind, fmt.Sprintf("%t", it.Loaded), disabled, health, trigger, it.Label, it.Path})
 				m.bgRows = append(m.bgRows, i)
 			}
 			m.table.SetRows(rows)
+		} else if m.tab == tabCron {
+			sourceW := 16
+			userW := 10
+			lineW := 5
+			scheduleW := 18
+			commandW := max(30, m.width-sourceW-userW-lineW-scheduleW-10)
+			m.table.SetColumns([]table.Column{
+				{Title: "Source", Width: sourceW},
+				{Title: "User", Width: userW},
+				{Title: "Line", Width: lineW},
+				{Title: "Schedule", Width: scheduleW},
+				{Title: "Command", Width: commandW},
+			})
+			rows := make([]table.Row, 0, len(m.cronItems))
+			m.cronRows = nil
+			for i, e := range m.cronItems {
+				if !filter.match(cronFilterFields(e)...) {
+					continue
+				}
+				rows = append(rows, table.Row{e.Source, e.User, fmt.Sprintf("%d", e.Line), e.Schedule, e.Command})
+				m.cronRows = append(m.cronRows, i)
+			}
+			m.table.SetRows(rows)
 		} else {
 			catW := max(24, m.width/5)
 			enabledW := 7
@@ -444,7 +680,7 @@ func (m *uiModel) rebuildTable(cursor int) {
 			rows := make([]table.Row, 0, len(m.extItems))
 			m.extRows = nil
 			for i, it := range m.extItems {
-				if !matchesExtensionsFilter(it, m.filter) {
+				if !filter.match(extensionFilterFields(it)...) {
 					continue
 				}
 				rows = append(rows, table.Row{
@@ -485,22 +721,34 @@ func (m uiModel) View() string {
 	loginLabel := inactiveTab.Render("Login Items")
 	bgLabel := inactiveTab.Render("Background Items")
 	extLabel := inactiveTab.Render("System Extensions")
+	cronLabel := inactiveTab.Render("Cron")
 	if m.tab == tabLogin {
 		loginLabel = activeTab.Render("Login Items")
 	} else if m.tab == tabBackground {
 		bgLabel = activeTab.Render("Background Items")
+	} else if m.tab == tabCron {
+		cronLabel = activeTab.Render("Cron")
 	} else {
 		extLabel = activeTab.Render("System Extensions")
 	}
 
-	header := lipgloss.JoinHorizontal(lipgloss.Top, loginLabel, " ", bgLabel, " ", extLabel)
+	header := lipgloss.JoinHorizontal(lipgloss.Top, loginLabel, " ", bgLabel, " ", extLabel, " ", cronLabel)
 	content := m.table.View()
-	help := "Keys: tab switch | r refresh | / search | c clear | q quit"
+	if m.tab == tabBackground && m.detail != nil {
+		content += "\n\n" + base.Render(strings.Join(serviceInfoLines(*m.detail), "\n"))
+	}
+	keys := []string{"next-tab switch", "refresh refresh", "filter search", "clear-filter clear"}
 	if m.tab == tabLogin {
-		help = "Keys: tab switch | r refresh | / search | c clear | x delete | q quit"
+		keys = append(keys, "delete delete")
 	} else if m.tab == tabBackground {
-		help = "Keys: tab switch | r refresh | / search | c clear | e enable | d disable | x delete | q quit"
+		keys = append(keys, "apple apple", "info info", "blame blame", "enable enable", "disable disable", "delete delete")
+	}
+	keys = append(keys, "quit quit")
+	for i, k := range keys {
+		action, label, _ := strings.Cut(k, " ")
+		keys[i] = tuiKeyName(action) + " " + label
 	}
+	help := "Keys: " + strings.Join(keys, " | ")
 	filterLabel := "Filter: " + m.filter
 	if m.filter == "" {
 		filterLabel = "Filter: <none>"
@@ -532,35 +780,32 @@ func max(a, b int) int {
 	return b
 }
 
-func matchesLoginFilter(it LoginItem, q string) bool {
-	q = strings.TrimSpace(strings.ToLower(q))
-	if q == "" {
-		return true
-	}
-	return strings.Contains(strings.ToLower(it.Name), q) || strings.Contains(strings.ToLower(it.Path), q)
+// The filter fields of each tab; list --filter matches the same ones.
+
+func loginFilterFields(it LoginItem) []string {
+	return []string{it.Name, it.Path}
 }
 
-func matchesBackgroundFilter(it BackgroundItem, q string) bool {
-	q = strings.TrimSpace(strings.ToLower(q))
-	if q == "" {
-		return true
-	}
-	return strings.Contains(strings.ToLower(it.Label), q) ||
-		strings.Contains(strings.ToLower(it.Path), q) ||
-		strings.Contains(strings.ToLower(it.Scope), q) ||
-		strings.Contains(strings.ToLower(it.Kind), q)
+func backgroundFilterFields(it BackgroundItem) []string {
+	return []string{it.Label, it.Path, it.Scope, it.Kind}
+}
+
+func cronFilterFields(e CronEntry) []string {
+	return []string{e.Source, e.User, e.Schedule, e.Command}
+}
+
+func extensionFilterFields(it SystemExtensionItem) []string {
+	return []string{it.Category, it.TeamID, it.BundleID, it.Name, it.State}
 }
 
-func matchesExtensionsFilter(it SystemExtensionItem, q string) bool {
-	q = strings.TrimSpace(strings.ToLower(q))
-	if q == "" {
-		return true
+// tuiFilter parses the filter being typed; a regular expression that is
+// not complete yet matches as plain text.
+func tuiFilter(q string) textFilter {
+	f, err := parseTextFilter(q)
+	if err != nil {
+		return textFilter{sub: strings.TrimSpace(strings.ToLower(q))}
 	}
-	return strings.Contains(strings.ToLower(it.Category), q) ||
-		strings.Contains(strings.ToLower(it.TeamID), q) ||
-		strings.Contains(strings.ToLower(it.BundleID), q) ||
-		strings.Contains(strings.ToLower(it.Name), q) ||
-		strings.Contains(strings.ToLower(it.State), q)
+	return f
 }
 
 func trimLastRune(s string) string {
@@ -4,10 +4,11 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
 )
 
 func TestRebuildTableSwitchTabsDoesNotPanic(t *testing.T) {
-	m := newUIModel()
+	m := newUIModel(mlogin.Client{Runner: &cannedRunner{}})
 	m.width = 120
 	m.height = 30
 	m.loginItems = []LoginItem{{Name: "Raycast", Path: "/Applications/Raycast.app", Hidden: false}}
@@ -41,7 +42,7 @@ func TestRebuildTableSwitchTabsDoesNotPanic(t *testing.T) {
 }
 
 func TestFilterMapsRowSelectionToOriginalItems(t *testing.T) {
-	m := newUIModel()
+	m := newUIModel(mlogin.Client{Runner: &cannedRunner{}})
 	m.width = 120
 	m.height = 30
 	m.loginItems = []LoginItem{
@@ -76,7 +77,7 @@ func TestFilterMapsRowSelectionToOriginalItems(t *testing.T) {
 }
 
 func TestClearFilterKey(t *testing.T) {
-	m := newUIModel()
+	m := newUIModel(mlogin.Client{Runner: &cannedRunner{}})
 	m.width = 120
 	m.height = 30
 	m.filter = "abc"
@@ -95,7 +96,7 @@ func TestClearFilterKey(t *testing.T) {
 }
 
 func TestBackgroundDeleteStartsConfirmation(t *testing.T) {
-	m := newUIModel()
+	m := newUIModel(mlogin.Client{Runner: &cannedRunner{}})
 	m.width = 120
 	m.height = 30
 	m.tab = tabBackground
@@ -118,7 +119,7 @@ func TestBackgroundDeleteStartsConfirmation(t *testing.T) {
 }
 
 func TestBackgroundDeleteCancelConfirmation(t *testing.T) {
-	m := newUIModel()
+	m := newUIModel(mlogin.Client{Runner: &cannedRunner{}})
 	m.confirmMode = true
 	m.confirmText = "Delete?"
 	m.pendingBGDel = &BackgroundItem{Label: "com.foo.agent"}
@@ -145,3 +146,25 @@ func mustNotPanic(t *testing.T, fn func()) {
 	}()
 	fn()
 }
+
+func TestToggleAppleItems(t *testing.T) {
+	m := newUIModel(mlogin.Client{Runner: &cannedRunner{}})
+	m.width = 120
+	m.height = 30
+	m.tab = tabBackground
+	m.bgItems = []BackgroundItem{
+		{Label: "com.apple.foo", Path: "/Library/LaunchDaemons/com.apple.foo.plist", Scope: "system", Kind: "daemon"},
+		{Label: "org.cups.cupsd", Path: "/Library/LaunchDaemons/org.cups.cupsd.plist", Scope: "system", Kind: "daemon"},
+		{Label: "com.example.agent", Path: "/tmp/a.plist", Scope: "user", Kind: "agent"},
+	}
+	m.rebuildTable(0)
+	if len(m.table.Rows()) != 1 {
+		t.Fatalf("expected Apple items hidden by default, got %d rows", len(m.table.Rows()))
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	next := updated.(uiModel)
+	if len(next.table.Rows()) != 3 {
+		t.Fatalf("expected all rows after toggle, got %d", len(next.table.Rows()))
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// validateBackgroundPlist reports every problem that would make launchd
+// reject or misbehave with the plist at path in the given scope.
+func validateBackgroundPlist(path, scope string) ([]string, error) {
+	scope = strings.ToLower(scope)
+	switch scope {
+	case "user", "user-domain":
+		scope = "user"
+	case "system", "loginwindow":
+		scope = "system"
+	default:
+		return nil, usagef("--scope must be user, user-domain, loginwindow, or system")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	problems = append(problems, checkPlistPermissions(info, scope, os.Getuid())...)
+	if err := lintPlist(path); err != nil {
+		return append(problems, "syntax: "+err.Error()), nil
+	}
+	m, err := readPlist(path)
+	if err != nil {
+		return append(problems, err.Error()), nil
+	}
+	problems = append(problems, checkLaunchdKeys(m)...)
+	if program := plistProgram(m); program != "" {
+		problems = append(problems, checkProgram(program)...)
+	}
+	return problems, nil
+}
+
+// checkLaunchdKeys verifies the keys launchd requires are present and typed.
+func checkLaunchdKeys(m map[string]any) []string {
+	var problems []string
+	if v, ok := m["Label"]; !ok {
+		problems = append(problems, "missing required key Label")
+	} else if s, ok := v.(string); !ok || s == "" {
+		problems = append(problems, "Label must be a non-empty string")
+	}
+	_, hasProgram := m["Program"]
+	_, hasArgs := m["ProgramArguments"]
+	if !hasProgram && !hasArgs {
+		problems = append(problems, "missing Program or ProgramArguments")
+	}
+	if hasProgram {
+		if _, ok := m["Program"].(string); !ok {
+			problems = append(problems, "Program must be a string")
+		}
+	}
+	if hasArgs {
+		if args, ok := m["ProgramArguments"].([]any); !ok || len(args) == 0 {
+			problems = append(problems, "ProgramArguments must be a non-empty array")
+		}
+	}
+	return problems
+}
+
+func checkProgram(program string) []string {
+	if !strings.HasPrefix(program, "/") {
+		// launchd resolves relative programs via PATH; nothing to check on disk.
+		return nil
+	}
+	info, err := os.Stat(program)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{fmt.Sprintf("program %s does not exist", program)}
+		}
+		return []string{fmt.Sprintf("program %s: %v", program, err)}
+	}
+	if info.IsDir() {
+		return []string{fmt.Sprintf("program %s is a directory", program)}
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		return []string{fmt.Sprintf("program %s is not executable", program)}
+	}
+	return nil
+}
+
+// checkPlistPermissions enforces launchd's ownership rules: system jobs must
+// be root:wheel, user agents owned by the user, and neither may be writable
+// by group or others.
+func checkPlistPermissions(info os.FileInfo, scope string, uid int) []string {
+	var problems []string
+	perm := info.Mode().Perm()
+	if perm&0o022 != 0 {
+		problems = append(problems, fmt.Sprintf("plist mode %04o is group/world writable (want 0644)", perm))
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return problems
+	}
+	if scope == "system" {
+		if st.Uid != 0 || st.Gid != 0 {
+			problems = append(problems, fmt.Sprintf("plist owned by %d:%d (want root:wheel)", st.Uid, st.Gid))
+		}
+	} else if int(st.Uid) != uid && st.Uid != 0 {
+		problems = append(problems, fmt.Sprintf("plist owned by uid %d (want %d)", st.Uid, uid))
+	}
+	return problems
+}
+
+func runValidate(path, scope string) error {
+	problems, err := validateBackgroundPlist(path, scope)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Printf("ok: %s\n", path)
+		return nil
+	}
+	for _, p := range problems {
+		fmt.Printf("- %s\n", p)
+	}
+	return fmt.Errorf("%s: %d problem(s) found", path, len(problems))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckLaunchdKeys(t *testing.T) {
+	cases := []struct {
+		name  string
+		plist map[string]any
+		want  []string
+	}{
+		{
+			name:  "valid",
+			plist: map[string]any{"Label": "com.foo", "ProgramArguments": []any{"/bin/true"}},
+		},
+		{
+			name:  "missing everything",
+			plist: map[string]any{},
+			want:  []string{"missing required key Label", "missing Program or ProgramArguments"},
+		},
+		{
+			name:  "empty args",
+			plist: map[string]any{"Label": "com.foo", "ProgramArguments": []any{}},
+			want:  []string{"ProgramArguments must be a non-empty array"},
+		},
+	}
+
+	for _, tc := range cases {
+		got := checkLaunchdKeys(tc.plist)
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Fatalf("%s: checkLaunchdKeys = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCheckProgram(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(plain, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := checkProgram(exe); len(got) != 0 {
+		t.Fatalf("expected executable to pass, got %v", got)
+	}
+	if got := checkProgram(plain); len(got) != 1 || !strings.Contains(got[0], "not executable") {
+		t.Fatalf("expected not executable problem, got %v", got)
+	}
+	if got := checkProgram(filepath.Join(dir, "missing")); len(got) != 1 || !strings.Contains(got[0], "does not exist") {
+		t.Fatalf("expected missing problem, got %v", got)
+	}
+}
+
+func TestCheckPlistPermissionsWritable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.plist")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := checkPlistPermissions(info, "user", os.Getuid())
+	if len(got) != 1 || !strings.Contains(got[0], "writable") {
+		t.Fatalf("expected writable problem, got %v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// quiet is the global --quiet flag: commands that change something say
+// nothing when they succeed. Errors, warnings and requested output are
+// still printed.
+var quiet bool
+
+// verbose is the global --verbose flag: every launchctl and osascript
+// invocation is printed to stderr before it runs, and its duration after.
+var verbose bool
+
+// successf reports that a change was made, unless --quiet was given or
+// --dry-run made no change.
+func successf(format string, a ...any) {
+	if quiet || dryRun {
+		return
+	}
+	fmt.Printf(format+"\n", a...)
+}
+
+// traceCommand prints cmd when --verbose is on. Call the returned func once
+// the command has finished to print how long it took.
+func traceCommand(cmd *exec.Cmd) (done func()) {
+	if !verbose {
+		return func() {}
+	}
+	fmt.Fprintln(os.Stderr, "+", displayCommand(cmd.Args))
+	start := time.Now()
+	return func() {
+		fmt.Fprintf(os.Stderr, "  %s took %s\n", filepath.Base(cmd.Args[0]), time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// displayCommand renders args for a person to read: only arguments that
+// need it are quoted, and multi-line scripts are summarized.
+func displayCommand(args []string) string {
+	out := make([]string, 0, len(args))
+	for _, a := range args {
+		switch {
+		case strings.Contains(a, "\n"):
+			out = append(out, fmt.Sprintf("<script, %d lines>", strings.Count(a, "\n")+1))
+		case a == "" || strings.ContainsAny(a, " \t'\"\\$`*?[]{}()<>|&;#~"):
+			out = append(out, shellJoin([]string{a}))
+		default:
+			out = append(out, a)
+		}
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestDisplayCommand(t *testing.T) {
+	got := displayCommand([]string{"launchctl", "bootout", "gui/501", "/Users/me/Library/LaunchAgents/My Agent.plist"})
+	want := "launchctl bootout gui/501 '/Users/me/Library/LaunchAgents/My Agent.plist'"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	got = displayCommand([]string{"osascript", "-l", "JavaScript", "-e", "const se = Application('System Events');\nse.loginItems()"})
+	want = "osascript -l JavaScript -e <script, 2 lines>"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
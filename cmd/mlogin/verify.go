@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+)
+
+// ProgramVerification is the Gatekeeper view of a program: what spctl
+// says about it and whether it still carries a quarantine attribute.
+type ProgramVerification struct {
+	// Assessment is "accepted" or "rejected".
+	Assessment string `json:"assessment,omitempty"`
+	// Source is spctl's reason, e.g. "Notarized Developer ID" or "no usable
+	// signature".
+	Source     string `json:"source,omitempty"`
+	Notarized  bool   `json:"notarized"`
+	Quarantine string `json:"quarantine,omitempty"`
+	// Flags are the problems worth a closer look.
+	Flags []string `json:"flags,omitempty"`
+}
+
+// trustedSpctlSources are assessment sources for code Apple vouches for:
+// notarized, from the App Store, or part of macOS.
+var trustedSpctlSources = []string{"Notarized Developer ID", "Notarized", "Mac App Store", "Apple System", "Apple"}
+
+// verifyProgram runs a Gatekeeper assessment of program and reads its
+// com.apple.quarantine attribute.
+func verifyProgram(program string) ProgramVerification {
+	// spctl prints its verdict on stderr and exits 3 on rejection.
+	cmd, done := toolCommand("spctl", "--assess", "--type", "execute", "-vv", program)
+	out, err := cmd.CombinedOutput()
+	done(err)
+	v := ProgramVerification{}
+	v.Assessment, v.Source = parseSpctlAssessment(string(out), program)
+	cmd, done = toolCommand("xattr", "-p", "com.apple.quarantine", program)
+	if q, err := cmd.Output(); done(err) == nil {
+		v.Quarantine = strings.TrimSpace(string(q))
+	}
+	v.Notarized, v.Flags = judgeVerification(v.Source, v.Quarantine)
+	return v
+}
+
+// parseSpctlAssessment reads `spctl --assess -vv` output:
+//
+//	/path/to/program: accepted
+//	source=Notarized Developer ID
+//	origin=Developer ID Application: Example (ABCDE12345)
+func parseSpctlAssessment(out, program string) (string, string) {
+	var assessment, source string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, program+":"); ok {
+			assessment = strings.TrimSpace(rest)
+			continue
+		}
+		if v, ok := strings.CutPrefix(line, "source="); ok {
+			source = v
+		}
+	}
+	return assessment, source
+}
+
+// judgeVerification flags programs that are not notarized (or shipped by
+// Apple) and programs that were downloaded from the internet, naming the
+// app that downloaded them. quarantine is the raw attribute value,
+// "flags;timestamp;agent;uuid".
+func judgeVerification(source, quarantine string) (bool, []string) {
+	var flags []string
+	notarized := false
+	for _, s := range trustedSpctlSources {
+		if source == s {
+			notarized = true
+			break
+		}
+	}
+	if !notarized {
+		flags = append(flags, "not notarized")
+	}
+	if quarantine != "" {
+		flag := "quarantined (downloaded from the internet)"
+		if fields := strings.Split(quarantine, ";"); len(fields) > 2 && fields[2] != "" {
+			flag = "quarantined (downloaded by " + fields[2] + ")"
+		}
+		flags = append(flags, flag)
+	}
+	return notarized, flags
+}
+
+// fillVerification assesses the program of each item that has a plist.
+func fillVerification(items []BackgroundItem) {
+	for i := range items {
+		if items[i].Path == "" || items[i].Kind == "startup" {
+			continue
+		}
+		plist, err := readPlist(items[i].Path)
+		if err != nil {
+			continue
+		}
+		if program := plistProgram(plist); program != "" {
+			v := verifyProgram(program)
+			items[i].Verification = &v
+		}
+	}
+}
+
+// describeVerification renders v as one line for listings.
+func describeVerification(v ProgramVerification) string {
+	s := "gatekeeper: " + orDash(v.Assessment)
+	if v.Source != "" {
+		s += " (" + v.Source + ")"
+	}
+	if len(v.Flags) > 0 {
+		s += " ! " + strings.Join(v.Flags, ", ")
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSpctlAssessment(t *testing.T) {
+	out := "/usr/local/bin/agent: rejected\nsource=no usable signature\n"
+	assessment, source := parseSpctlAssessment(out, "/usr/local/bin/agent")
+	if assessment != "rejected" || source != "no usable signature" {
+		t.Fatalf("got %q %q", assessment, source)
+	}
+}
+
+func TestJudgeVerification(t *testing.T) {
+	notarized, flags := judgeVerification("Notarized Developer ID", "")
+	if !notarized || len(flags) != 0 {
+		t.Fatalf("expected notarized program without flags, got %t %q", notarized, flags)
+	}
+	notarized, flags = judgeVerification("no usable signature", "0083;65a1b2c3;Safari;1D1B9F6A-0000-0000-0000-000000000000")
+	if notarized {
+		t.Fatalf("expected unsigned program to be not notarized")
+	}
+	want := []string{"not notarized", "quarantined (downloaded by Safari)"}
+	if !slices.Equal(flags, want) {
+		t.Fatalf("flags = %q, want %q", flags, want)
+	}
+}
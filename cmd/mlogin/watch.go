@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// WatchEvent is one change seen by watch. Change is added, removed, or
+// modified; Details holds the item's fields (as they were, for removed
+// items).
+type WatchEvent struct {
+	Time    time.Time         `json:"time"`
+	Surface string            `json:"surface"`
+	Name    string            `json:"name"`
+	Change  string            `json:"change"`
+	Fields  []FieldChange     `json:"fields,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// watchHandler receives every event; watch prints events and may also
+// forward them elsewhere.
+type watchHandler func(WatchEvent) error
+
+func runWatch(args []string) error {
+	c := client()
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "emit one JSON object per event (NDJSON)")
+	interval := fs.Duration("interval", 2*time.Second, "how often to poll the LaunchAgents/LaunchDaemons directories for changed plists (they are stat-ed, not watched with file events)")
+	poll := fs.Duration("poll", 30*time.Second, "how often to re-read login items, BTM records, and launchd state")
+	notify := fs.Bool("notify", false, "also post a macOS notification for every change")
+	webhook := fs.String("webhook", "", "also POST every change to this URL")
+	webhookFormat := fs.String("webhook-format", "json", "webhook payload: json or slack")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *interval <= 0 || *poll <= 0 {
+		return usagef("--interval and --poll must be positive")
+	}
+	if err := checkWebhookFormat(*webhookFormat); err != nil {
+		return err
+	}
+	if outputFormat != "" && outputFormat != "json" && outputFormat != "ndjson" {
+		return fmt.Errorf("watch streams events; use --output ndjson")
+	}
+	handlers := []watchHandler{printWatchEvent}
+	if *jsonOut || outputFormat != "" {
+		enc := json.NewEncoder(os.Stdout)
+		handlers = []watchHandler{func(e WatchEvent) error {
+			v, err := withSchemaVersion(e)
+			if err != nil {
+				return err
+			}
+			return enc.Encode(v)
+		}}
+	}
+	if *notify {
+		handlers = append(handlers, func(e WatchEvent) error { return notifyWatchEvent(c, e) })
+	}
+	if *webhook != "" {
+		handlers = append(handlers, newWebhookHandler(*webhook, *webhookFormat))
+	}
+	if _, ok := timelineEnabled(); ok {
+		handlers = append(handlers, func(e WatchEvent) error { return recordTimelineEvents("watch", []WatchEvent{e}) })
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watch(ctx, c, *interval, *poll, handlers)
+}
+
+// watch polls until ctx is done. Plist directories are cheap to stat, so
+// they are checked every interval and launchd items are re-read as soon as
+// a file changes; login items and BTM records have no file to watch and are
+// re-read every poll.
+func watch(ctx context.Context, c mlogin.Client, interval, poll time.Duration, handlers []watchHandler) error {
+	dirs, err := launchDirs("all")
+	if err != nil {
+		return err
+	}
+	seenWarnings := map[string]bool{}
+	warnOnce := func(warnings []string) {
+		for _, w := range warnings {
+			if !seenWarnings[w] {
+				seenWarnings[w] = true
+				warn(w)
+			}
+		}
+	}
+
+	state, warnings := watchState(c, Snapshot{}, true)
+	warnOnce(warnings)
+	fingerprint := launchDirFingerprint(dirs)
+	lastPoll := time.Now()
+	fmt.Fprintln(os.Stderr, "watching for autostart changes (Ctrl-C to stop)")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			full := now.Sub(lastPoll) >= poll
+			current := launchDirFingerprint(dirs)
+			if !full && current == fingerprint {
+				continue
+			}
+			fingerprint = current
+			if full {
+				lastPoll = now
+			}
+			next, warnings := watchState(c, state, full)
+			warnOnce(warnings)
+			for _, e := range watchEvents(state, next, now) {
+				for _, h := range handlers {
+					if err := h(e); err != nil {
+						warn(err)
+					}
+				}
+			}
+			state = next
+		}
+	}
+}
+
+// watchState re-reads launchd items and, when full is set, login items and
+// BTM records; the parts not re-read are carried over from prev.
+func watchState(c mlogin.Client, prev Snapshot, full bool) (Snapshot, []string) {
+	next := prev
+	var warnings []string
+	items, itemWarnings, err := listBackgroundItems(c, "all", false)
+	if err != nil {
+		warnings = append(warnings, "background items: "+err.Error())
+	} else {
+		next.Background = withoutAppleItems(items)
+	}
+	warnings = append(warnings, itemWarnings...)
+	if !full {
+		return next, warnings
+	}
+	if login, err := listLoginItems(c); err != nil {
+		warnings = append(warnings, "login items: "+err.Error())
+	} else {
+		next.LoginItems = login
+	}
+	if entries, _, err := auditBTM(c); err != nil {
+		warnings = append(warnings, "background task management: "+err.Error())
+	} else {
+		next.Surfaces = []AuditSurface{{Surface: "background task management", Entries: entries}}
+	}
+	return next, warnings
+}
+
+// launchDirFingerprint summarizes the names, sizes, and modification times
+// of the plists in dirs, so any install, removal, or edit changes it.
+func launchDirFingerprint(dirs []mlogin.LaunchDir) string {
+	var parts []string
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d.Dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s/%s:%d:%d", d.Dir, e.Name(), info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n")
+}
+
+// watchEvents turns the differences between two states into events.
+func watchEvents(old, cur Snapshot, at time.Time) []WatchEvent {
+	before, after := snapshotEntries(old), snapshotEntries(cur)
+	var events []WatchEvent
+	for _, c := range diffSnapshots(old, cur) {
+		e := WatchEvent{Time: at.UTC(), Surface: c.Surface, Name: c.Name, Change: c.Change, Fields: c.Fields}
+		key := c.Surface + "\x00" + c.Name
+		switch c.Change {
+		case "added":
+			e.Details = after[key].fields
+		case "removed":
+			e.Details = before[key].fields
+		case "changed":
+			e.Change = "modified"
+			e.Details = after[key].fields
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func printWatchEvent(e WatchEvent) error {
+	styles := map[string]lipgloss.Style{
+		"added":    lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		"removed":  lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+		"modified": lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+	}
+	line := fmt.Sprintf("%s %s [%s] %s", e.Time.Local().Format("15:04:05"), e.Change, e.Surface, e.Name)
+	fmt.Println(styles[e.Change].Render(line))
+	for _, f := range e.Fields {
+		fmt.Printf("    %s: %s -> %s\n", f.Field, orDash(f.Old), orDash(f.New))
+	}
+	if path := e.Details["path"]; path != "" {
+		fmt.Printf("    path: %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+func TestLaunchDirFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	dirs := []mlogin.LaunchDir{{Scope: "user", Kind: "agent", Dir: dir}}
+	before := launchDirFingerprint(dirs)
+	if err := os.WriteFile(filepath.Join(dir, "com.example.agent.plist"), []byte("<plist/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	after := launchDirFingerprint(dirs)
+	if before == after {
+		t.Fatalf("expected fingerprint to change after adding a plist")
+	}
+	if launchDirFingerprint(dirs) != after {
+		t.Fatalf("expected fingerprint to be stable without changes")
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	old := Snapshot{LoginItems: []LoginItem{{Name: "Old", Path: "/Applications/Old.app"}}}
+	cur := Snapshot{LoginItems: []LoginItem{{Name: "New", Path: "/Applications/New.app"}}}
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := watchEvents(old, cur, at)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Name != "New" || events[0].Change != "added" || events[0].Details["path"] != "/Applications/New.app" {
+		t.Fatalf("unexpected added event: %+v", events[0])
+	}
+	if events[1].Name != "Old" || events[1].Change != "removed" || !events[1].Time.Equal(at) {
+		t.Fatalf("unexpected removed event: %+v", events[1])
+	}
+
+	cur.LoginItems = append(cur.LoginItems, LoginItem{Name: "Old", Path: "/Applications/Old.app", Hidden: true})
+	events = watchEvents(old, cur, at)
+	if len(events) != 2 || events[1].Change != "modified" || events[1].Fields[0].Field != "hidden" {
+		t.Fatalf("expected a modified event for Old, got %+v", events)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookEvent is the generic payload: the event plus the machine it
+// happened on, so one endpoint can collect events from many Macs.
+type webhookEvent struct {
+	Host string `json:"host"`
+	WatchEvent
+}
+
+func checkWebhookFormat(format string) error {
+	if format != "json" && format != "slack" {
+		return usagef("--webhook-format must be json or slack")
+	}
+	return nil
+}
+
+// newWebhookHandler returns a handler that POSTs each event to url.
+func newWebhookHandler(url, format string) watchHandler {
+	host, _ := os.Hostname()
+	return func(e WatchEvent) error {
+		body, err := webhookPayload(e, format, host)
+		if err != nil {
+			return err
+		}
+		return postWebhook(url, body)
+	}
+}
+
+// webhookPayload encodes e for the endpoint. The slack format is a plain
+// {"text": ...} message, which Slack incoming webhooks and most chat tools
+// that copy their API accept.
+func webhookPayload(e WatchEvent, format, host string) ([]byte, error) {
+	if format != "slack" {
+		return json.Marshal(webhookEvent{Host: host, WatchEvent: e})
+	}
+	text := fmt.Sprintf("*%s* on %s: `%s`", notificationTitle(e), orDash(host), e.Name)
+	if path := e.Details["path"]; path != "" {
+		text += "\n" + path
+	}
+	for _, f := range e.Fields {
+		text += fmt.Sprintf("\n%s: %s -> %s", f.Field, orDash(f.Old), orDash(f.New))
+	}
+	return json.Marshal(map[string]string{"text": text})
+}
+
+func postWebhook(url string, body []byte) error {
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
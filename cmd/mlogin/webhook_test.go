@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookPayload(t *testing.T) {
+	e := WatchEvent{Surface: "launchd", Name: "com.foo.agent (user)", Change: "added", Details: map[string]string{"kind": "agent", "path": "/Users/me/Library/LaunchAgents/com.foo.agent.plist"}}
+
+	data, err := webhookPayload(e, "json", "laptop-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generic map[string]any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if generic["host"] != "laptop-1" || generic["name"] != "com.foo.agent (user)" || generic["change"] != "added" {
+		t.Fatalf("unexpected json payload: %s", data)
+	}
+
+	data, err = webhookPayload(e, "slack", "laptop-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slack map[string]string
+	if err := json.Unmarshal(data, &slack); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(slack["text"], "*New LaunchAgent installed* on laptop-1: `com.foo.agent (user)`") {
+		t.Fatalf("unexpected slack payload: %s", data)
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "no such hook", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	if err := postWebhook(srv.URL, []byte(`{"text":"hi"}`)); err != nil {
+		t.Fatalf("postWebhook: %v", err)
+	}
+	if got != `{"text":"hi"}` {
+		t.Fatalf("unexpected body %q", got)
+	}
+	if err := postWebhook(srv.URL+"/fail", []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "no such hook") {
+		t.Fatalf("expected an error for a failing endpoint, got %v", err)
+	}
+}
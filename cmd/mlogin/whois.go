@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// WhoisReport answers "what is this thing and can I delete it" for a
+// launchd label, a plist or program path, or an app bundle identifier.
+type WhoisReport struct {
+	Query    string   `json:"query"`
+	Label    string   `json:"label,omitempty"`
+	Plist    string   `json:"plist,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Program  string   `json:"program,omitempty"`
+	App      string   `json:"app,omitempty"`
+	Signer   string   `json:"signer,omitempty"`
+	TeamID   string   `json:"team_id,omitempty"`
+	Packages []string `json:"packages,omitempty"`
+	State    string   `json:"state,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
+}
+
+func runWhois(args []string) error {
+	c := client()
+	var query string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		query, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("whois", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "output JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if query == "" && fs.NArg() > 0 {
+		query = fs.Arg(0)
+	}
+	if query == "" {
+		return usagef("usage: mlogin whois <label|path|bundle-id>")
+	}
+	report, err := whois(c, resolveAlias(query))
+	if err != nil {
+		return err
+	}
+	if *jsonOut || outputFormat != "" {
+		return writeOutput(report)
+	}
+	printWhoisReport(report)
+	return nil
+}
+
+// whois resolves query to a background item where possible and collects
+// who owns, signed, and installed it.
+func whois(c mlogin.Client, query string) (WhoisReport, error) {
+	r := WhoisReport{Query: query}
+	var item *BackgroundItem
+	switch {
+	case strings.HasSuffix(query, ".plist"):
+		label, err := readPlistLabel(query)
+		if err != nil {
+			return r, fmt.Errorf("read label from %s: %w", query, err)
+		}
+		item = &BackgroundItem{Label: label, Path: query, Scope: scopeForPlistPath(query)}
+	case strings.HasPrefix(query, "/"):
+		item = findItemByProgram(query)
+		r.Program = query
+	default:
+		matches, err := findBackgroundPlists(query, "all")
+		if err != nil {
+			return r, err
+		}
+		if len(matches) > 0 {
+			item = &matches[0]
+		} else if app := appForBundleID(query); app != "" {
+			r.App = app
+			r.Program = app
+		} else {
+			return r, fmt.Errorf("no launchd item, plist, or app found for %q", query)
+		}
+	}
+
+	var plist map[string]any
+	if item != nil {
+		r.Label, r.Plist, r.Scope = item.Label, item.Path, item.Scope
+		if r.Scope == "" {
+			r.Scope = "system"
+		}
+		item.Scope = r.Scope
+		var err error
+		if plist, err = readPlist(item.Path); err == nil && r.Program == "" {
+			r.Program = plistProgram(plist)
+		}
+		r.State = "not loaded"
+		if loaded, err := printLaunchService(c, *item); err == nil {
+			r.State = orDash(loaded.values["state"])
+		}
+		if domain, err := serviceDomain(c, *item); err == nil {
+			if disabled, err := getDisabledLabels(c, domain); err == nil {
+				r.Disabled = disabled[item.Label]
+			}
+		}
+	}
+	if r.App == "" {
+		r.App = owningApp(r.Program, plist)
+	}
+	if r.Program != "" {
+		if info, err := codesignInfo(r.Program); err == nil {
+			r.Signer = info["Authority"]
+			if team := info["TeamIdentifier"]; team != "not set" {
+				r.TeamID = team
+			}
+		}
+	}
+	for _, p := range []string{r.Plist, r.Program} {
+		if p == "" {
+			continue
+		}
+		if pkgs := pkgReceiptsFor(p); len(pkgs) > 0 {
+			r.Packages = pkgs
+			break
+		}
+	}
+	return r, nil
+}
+
+// findItemByProgram returns the launchd item whose plist runs program.
+func findItemByProgram(program string) *BackgroundItem {
+	dirs, err := launchDirs("all")
+	if err != nil {
+		return nil
+	}
+	for _, d := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(d.Dir, "*.plist"))
+		for _, p := range matches {
+			plist, err := readPlist(p)
+			if err != nil || plistProgram(plist) != program {
+				continue
+			}
+			return &BackgroundItem{Label: plistString(plist, "Label"), Path: p, Scope: d.Scope, Kind: d.Kind}
+		}
+	}
+	return nil
+}
+
+// owningApp returns the app bundle a program lives in or, for helpers
+// outside any bundle, the app named by the plist's
+// AssociatedBundleIdentifiers.
+func owningApp(program string, plist map[string]any) string {
+	if i := strings.Index(program, ".app/"); i >= 0 {
+		return program[:i+len(".app")]
+	}
+	if strings.HasSuffix(program, ".app") {
+		return program
+	}
+	ids := plistStrings(plist, "AssociatedBundleIdentifiers")
+	if id := plistString(plist, "AssociatedBundleIdentifiers"); id != "" {
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		if app := appForBundleID(id); app != "" {
+			return app
+		}
+	}
+	return ""
+}
+
+// appForBundleID asks Spotlight for the app with bundle identifier id.
+func appForBundleID(id string) string {
+	cmd, done := toolCommand("mdfind", "kMDItemCFBundleIdentifier == '"+strings.ReplaceAll(id, "'", "")+"'")
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return line
+}
+
+// pkgReceiptsFor returns the identifiers of the installer packages whose
+// receipts list path.
+func pkgReceiptsFor(path string) []string {
+	cmd, done := toolCommand("pkgutil", "--file-info", path)
+	out, err := cmd.Output()
+	err = done(err)
+	if err != nil {
+		return nil
+	}
+	return parsePkgutilFileInfo(string(out))
+}
+
+// parsePkgutilFileInfo extracts the pkgid lines of `pkgutil --file-info`,
+// which prints one block per package that installed the file:
+//
+//	volume: /
+//	path: Library/LaunchDaemons/com.example.daemon.plist
+//
+//	pkgid: com.example.pkg
+//	pkg-version: 1.2.3
+func parsePkgutilFileInfo(out string) []string {
+	var pkgs []string
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		if id, ok := strings.CutPrefix(strings.TrimSpace(s.Text()), "pkgid: "); ok {
+			pkgs = append(pkgs, id)
+		}
+	}
+	return pkgs
+}
+
+func printWhoisReport(r WhoisReport) {
+	row := func(k, v string) {
+		fmt.Printf("%-9s %s\n", k+":", orDash(v))
+	}
+	if r.Label != "" {
+		row("Label", r.Label)
+		row("Plist", r.Plist+" ("+r.Scope+")")
+		state := r.State
+		if r.Disabled {
+			state += ", disabled"
+		}
+		row("State", state)
+	}
+	row("Program", r.Program)
+	row("App", r.App)
+	row("Signer", r.Signer)
+	row("TeamID", r.TeamID)
+	row("Package", strings.Join(r.Packages, ", "))
+	switch {
+	case r.Label != "" && r.App == "" && len(r.Packages) == 0:
+		fmt.Println("No owning app or installer package found; the app that installed it may be gone.")
+	case r.Label != "" && len(r.Packages) > 0:
+		fmt.Printf("Installed by %s; prefer its uninstaller over deleting the plist.\n", r.Packages[0])
+	}
+}
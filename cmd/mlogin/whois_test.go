@@ -0,0 +1,35 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePkgutilFileInfo(t *testing.T) {
+	out := `volume: /
+path: Library/LaunchDaemons/com.example.daemon.plist
+
+pkgid: com.example.pkg.core
+pkg-version: 1.2.3
+install-time: 1700000000
+uid: 0
+gid: 0
+mode: 644
+
+pkgid: com.example.pkg.helper
+pkg-version: 1.2.3
+`
+	want := []string{"com.example.pkg.core", "com.example.pkg.helper"}
+	if got := parsePkgutilFileInfo(out); !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestOwningApp(t *testing.T) {
+	if got := owningApp("/Applications/Example.app/Contents/Library/LoginItems/Helper.app/Contents/MacOS/Helper", nil); got != "/Applications/Example.app" {
+		t.Fatalf("got %q", got)
+	}
+	if got := owningApp("/usr/local/bin/agent", map[string]any{}); got != "" {
+		t.Fatalf("expected no app, got %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/j4n-e4t/mlogin/pkg/mlogin"
+)
+
+// whyFinding is one reason a service is in its current state, with the
+// commands that would change it.
+type whyFinding struct {
+	Problem string   `json:"problem"`
+	Fix     []string `json:"fix,omitempty"`
+}
+
+// whyFacts are the observations `background why` diagnoses from. They are
+// gathered separately from diagnoseService so the diagnosis is testable.
+type whyFacts struct {
+	item     BackgroundItem
+	onDisk   bool
+	problems []string
+	trigger  string
+	domain   string
+	disabled bool
+	managed  string
+	loaded   *launchctlBlock
+}
+
+// diagnoseService explains the state of a service from facts, most
+// fundamental problem first.
+func diagnoseService(f whyFacts) []whyFinding {
+	label, scope, path := f.item.Label, f.item.Scope, shellJoin([]string{f.item.Path})
+	var findings []whyFinding
+	if !f.onDisk {
+		if f.loaded == nil {
+			return append(findings, whyFinding{
+				Problem: fmt.Sprintf("no plist for %s in any LaunchAgents/LaunchDaemons directory and not loaded in %s", label, f.domain),
+				Fix:     []string{"mlogin background list --include-apple --hide-apple=false"},
+			})
+		}
+		findings = append(findings, whyFinding{
+			Problem: fmt.Sprintf("loaded in %s but has no plist on disk (registered by an app, or the plist was removed after loading)", f.domain),
+			Fix:     []string{fmt.Sprintf("mlogin background unload --label %s --scope %s", label, scope)},
+		})
+	}
+	if f.disabled {
+		finding := whyFinding{
+			Problem: fmt.Sprintf("disabled in %s; launchd will not load it until it is enabled", f.domain),
+			Fix:     []string{fmt.Sprintf("mlogin background enable --label %s --scope %s", label, scope)},
+		}
+		if f.managed != "" {
+			finding.Problem += " (" + f.managed + ")"
+		}
+		findings = append(findings, finding)
+	}
+	for _, p := range f.problems {
+		findings = append(findings, whyFinding{Problem: p, Fix: fixForProblem(p, path, scope)})
+	}
+	if f.onDisk && f.loaded == nil {
+		findings = append(findings, whyFinding{
+			Problem: fmt.Sprintf("never bootstrapped into %s (not loaded)", f.domain),
+			Fix:     []string{fmt.Sprintf("mlogin background load --plist %s --scope %s", path, scope)},
+		})
+	}
+	if f.loaded == nil {
+		return findings
+	}
+	h := assessServiceHealth(f.item, f.loaded)
+	switch h.Health {
+	case "crash-loop", "flapping", "failing":
+		problem := fmt.Sprintf("%s: last exit code %s after %d run(s)", h.Health, orDash(h.LastExitCode), h.Runs)
+		if h.LastSignal != "" {
+			problem = fmt.Sprintf("%s: last terminated by %s after %d run(s)", h.Health, h.LastSignal, h.Runs)
+		}
+		if h.State == "spawn scheduled" {
+			problem += "; launchd is throttling respawns (ThrottleInterval)"
+		}
+		findings = append(findings, whyFinding{
+			Problem: problem,
+			Fix: []string{
+				fmt.Sprintf("mlogin background logs --label %s --scope %s", label, scope),
+				fmt.Sprintf("mlogin background kickstart --label %s --scope %s --force", label, scope),
+			},
+		})
+	case "ok":
+		if h.State != "running" && len(findings) == 0 && f.trigger != "" {
+			findings = append(findings, whyFinding{
+				Problem: fmt.Sprintf("loaded but not running; it waits for its trigger (%s)", f.trigger),
+				Fix:     []string{fmt.Sprintf("mlogin background kickstart --label %s --scope %s", label, scope)},
+			})
+		}
+	}
+	return findings
+}
+
+// fixForProblem suggests a remediation for a validateBackgroundPlist
+// problem. path is already shell-quoted.
+func fixForProblem(problem, path, scope string) []string {
+	switch {
+	case strings.Contains(problem, "writable"):
+		return []string{"chmod 644 " + path}
+	case strings.Contains(problem, "owned by") && scope == "system":
+		return []string{"sudo chown root:wheel " + path}
+	case strings.Contains(problem, "owned by"):
+		return []string{"chown $(id -u) " + path}
+	case strings.HasPrefix(problem, "program ") && strings.Contains(problem, "does not exist"):
+		return []string{"mlogin background prune --dry-run", fmt.Sprintf("mlogin background delete --plist %s", path)}
+	case strings.Contains(problem, "not executable"):
+		program := strings.TrimSuffix(strings.TrimPrefix(problem, "program "), " is not executable")
+		return []string{"chmod +x " + shellJoin([]string{program})}
+	default:
+		return []string{fmt.Sprintf("mlogin background validate --plist %s", path)}
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// gatherWhyFacts looks up everything diagnoseService needs for label.
+func gatherWhyFacts(c mlogin.Client, label, scope string) (whyFacts, error) {
+	matches, err := findBackgroundPlists(label, scope)
+	if err != nil {
+		return whyFacts{}, err
+	}
+	var f whyFacts
+	switch len(matches) {
+	case 0:
+		resolved := scope
+		if resolved == "all" {
+			resolved = "user"
+		}
+		f.item = BackgroundItem{Label: label, Scope: resolved, Kind: "registered"}
+	case 1:
+		f.item = matches[0]
+		f.onDisk = true
+	default:
+		paths := make([]string, 0, len(matches))
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+		}
+		return whyFacts{}, fmt.Errorf("label %q is ambiguous, found in: %s (pass --scope)", label, strings.Join(paths, ", "))
+	}
+
+	if f.onDisk {
+		problems, err := validateBackgroundPlist(f.item.Path, f.item.Scope)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		f.problems = problems
+		if plist, err := readPlist(f.item.Path); err == nil {
+			f.trigger = describeTrigger(plist)
+		}
+	}
+	f.domain, err = serviceDomain(c, f.item)
+	if err != nil {
+		return whyFacts{}, err
+	}
+	if disabled, err := getDisabledLabels(c, f.domain); err == nil {
+		f.disabled = disabled[label]
+	}
+	if f.disabled {
+		f.managed = managedWarning(f.item)
+	}
+	if loaded, err := printLaunchService(c, f.item); err == nil {
+		f.loaded = loaded
+	}
+	return f, nil
+}
+
+func runBackgroundWhy(c mlogin.Client, label, scope string, jsonOut bool) error {
+	if scope == "" {
+		scope = "all"
+	}
+	f, err := gatherWhyFacts(c, label, scope)
+	if err != nil {
+		return err
+	}
+	findings := diagnoseService(f)
+	if jsonOut || outputFormat != "" {
+		return writeOutput(findings)
+	}
+	state := "not loaded"
+	if f.loaded != nil {
+		state = orDash(f.loaded.values["state"])
+		if pid := f.loaded.values["pid"]; pid != "" {
+			state += " (pid " + pid + ")"
+		}
+	}
+	fmt.Printf("%s (%s %s in %s): %s\n", label, f.item.Scope, f.item.Kind, f.domain, state)
+	if len(findings) == 0 {
+		fmt.Println("No problems found")
+		return nil
+	}
+	for _, fd := range findings {
+		fmt.Printf("- %s\n", fd.Problem)
+		for _, fix := range fd.Fix {
+			fmt.Printf("    %s\n", fix)
+		}
+	}
+	return nil
+}
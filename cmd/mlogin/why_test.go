@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseService(t *testing.T) {
+	item := BackgroundItem{Label: "com.example.agent", Scope: "user", Kind: "agent", Path: "/Users/me/Library/LaunchAgents/com.example.agent.plist"}
+
+	t.Run("disabled and not loaded", func(t *testing.T) {
+		got := diagnoseService(whyFacts{item: item, onDisk: true, domain: "gui/501", disabled: true})
+		if len(got) != 2 {
+			t.Fatalf("expected 2 findings, got %+v", got)
+		}
+		if !strings.HasPrefix(got[0].Problem, "disabled in gui/501") || got[0].Fix[0] != "mlogin background enable --label com.example.agent --scope user" {
+			t.Fatalf("unexpected first finding: %+v", got[0])
+		}
+		if !strings.HasPrefix(got[1].Problem, "never bootstrapped") {
+			t.Fatalf("unexpected second finding: %+v", got[1])
+		}
+	})
+
+	t.Run("missing program", func(t *testing.T) {
+		loaded := parseLaunchctlPrint("gui/501/com.example.agent = {\n\tstate = not running\n\truns = 0\n}\n")
+		got := diagnoseService(whyFacts{item: item, onDisk: true, domain: "gui/501", loaded: loaded,
+			problems: []string{"program /opt/example/agent does not exist"}})
+		if len(got) != 1 || !strings.Contains(got[0].Fix[0], "prune") {
+			t.Fatalf("unexpected findings: %+v", got)
+		}
+	})
+
+	t.Run("throttled", func(t *testing.T) {
+		loaded := parseLaunchctlPrint("gui/501/com.example.agent = {\n\tstate = spawn scheduled\n\truns = 5\n\tlast exit code = 78: EX_CONFIG\n}\n")
+		got := diagnoseService(whyFacts{item: item, onDisk: true, domain: "gui/501", loaded: loaded})
+		if len(got) != 1 || !strings.Contains(got[0].Problem, "throttling") {
+			t.Fatalf("unexpected findings: %+v", got)
+		}
+	})
+
+	t.Run("healthy", func(t *testing.T) {
+		loaded := parseLaunchctlPrint("gui/501/com.example.agent = {\n\tstate = running\n\tpid = 42\n}\n")
+		if got := diagnoseService(whyFacts{item: item, onDisk: true, domain: "gui/501", loaded: loaded}); len(got) != 0 {
+			t.Fatalf("expected no findings, got %+v", got)
+		}
+	})
+}
+
+func TestFixForProblem(t *testing.T) {
+	got := fixForProblem("plist owned by 501:20 (want root:wheel)", "'/Library/LaunchDaemons/x.plist'", "system")
+	if got[0] != "sudo chown root:wheel '/Library/LaunchDaemons/x.plist'" {
+		t.Fatalf("unexpected fix: %v", got)
+	}
+	got = fixForProblem("program /opt/x is not executable", "'/tmp/x.plist'", "user")
+	if got[0] != "chmod +x '/opt/x'" {
+		t.Fatalf("unexpected fix: %v", got)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// writeYAML renders v as block-style YAML. Values are normalized through
+// encoding/json first, so anything that marshals to JSON is supported.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	var b strings.Builder
+	writeYAMLValue(&b, generic, 0)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeYAMLValue(b *strings.Builder, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(pad + yamlScalar(k) + ":")
+			writeYAMLChild(b, v[k], indent)
+		}
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(pad + "[]\n")
+			return
+		}
+		for _, e := range v {
+			b.WriteString(pad + "-")
+			writeYAMLChild(b, e, indent)
+		}
+	default:
+		b.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLChild writes a map value or list element that follows a "key:" or
+// "-" already on the current line.
+func writeYAMLChild(b *strings.Builder, v any, indent int) {
+	switch c := v.(type) {
+	case map[string]any:
+		if len(c) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLValue(b, c, indent+1)
+	case []any:
+		if len(c) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLValue(b, c, indent+1)
+	default:
+		b.WriteString(" " + yamlScalar(c) + "\n")
+	}
+}
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/.][A-Za-z0-9_ ./@+()-]*$`)
+
+// yamlScalar formats a scalar, quoting strings that YAML would otherwise
+// parse as another type or that contain special characters.
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		switch strings.ToLower(v) {
+		case "", "true", "false", "yes", "no", "on", "off", "null", "~":
+			return strconv.Quote(v)
+		}
+		if yamlPlain.MatchString(v) && !strings.HasSuffix(v, " ") {
+			return v
+		}
+		return strconv.Quote(v)
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
+
+// readYAML decodes the block-style YAML subset writeYAML produces, plus
+// comments, "- key: value" list items, and flow lists of scalars, into v via
+// encoding/json. JSON documents are accepted as is.
+func readYAML(data []byte, v any) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		return json.Unmarshal(data, v)
+	}
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		text := stripYAMLComment(strings.TrimRight(raw, " \t\r"))
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		lines = append(lines, yamlLine{n: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	var generic any
+	if len(lines) > 0 {
+		p := &yamlParser{lines: lines}
+		var err error
+		if generic, err = p.parseBlock(lines[0].indent); err != nil {
+			return err
+		}
+		if p.i < len(lines) {
+			return fmt.Errorf("yaml line %d: unexpected indentation", lines[p.i].n)
+		}
+	}
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type yamlLine struct {
+	n      int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// parseBlock parses the mapping or sequence whose entries start at indent.
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if isYAMLListItem(p.lines[p.i].text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseSeq(indent int) (any, error) {
+	list := []any{}
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent != indent || !isYAMLListItem(l.text) {
+			break
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(l.text, "-"))
+		switch {
+		case rest == "":
+			p.i++
+			v, err := p.parseChild(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		case yamlKeyValue(rest):
+			// "- key: value" starts a mapping indented past the dash.
+			p.lines[p.i] = yamlLine{n: l.n, indent: indent + len(l.text) - len(rest), text: rest}
+			v, err := p.parseMap(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		default:
+			p.i++
+			v, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("yaml line %d: %w", l.n, err)
+			}
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (any, error) {
+	m := map[string]any{}
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent || isYAMLListItem(l.text) || !yamlKeyValue(l.text) {
+			return nil, fmt.Errorf("yaml line %d: expected \"key: value\"", l.n)
+		}
+		key, rest, _ := strings.Cut(l.text, ":")
+		key = strings.TrimSpace(key)
+		if unq, err := strconv.Unquote(key); err == nil {
+			key = unq
+		}
+		rest = strings.TrimSpace(rest)
+		p.i++
+		if rest != "" {
+			v, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("yaml line %d: %w", l.n, err)
+			}
+			m[key] = v
+			continue
+		}
+		v, err := p.parseChild(indent, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// parseChild parses the block nested under a "key:" or "-" line at indent.
+// A list may sit at the same indentation as its key.
+func (p *yamlParser) parseChild(indent int, allowSameIndentList bool) (any, error) {
+	if p.i >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.i]
+	if next.indent > indent || (allowSameIndentList && next.indent == indent && isYAMLListItem(next.text)) {
+		return p.parseBlock(next.indent)
+	}
+	return nil, nil
+}
+
+func isYAMLListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKeyValue reports whether text is a "key:" or "key: value" entry rather
+// than a scalar that merely contains a colon.
+func yamlKeyValue(text string) bool {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		end := strings.IndexByte(text[1:], text[0])
+		return end >= 0 && strings.HasPrefix(text[end+2:], ":")
+	}
+	key, rest, ok := strings.Cut(text, ":")
+	return ok && key != "" && (rest == "" || strings.HasPrefix(rest, " "))
+}
+
+// stripYAMLComment drops a trailing "# comment" outside of quotes.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[:i], " \t")
+		}
+	}
+	return s
+}
+
+func parseYAMLScalar(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case s == "[]":
+		return []any{}, nil
+	case s == "{}":
+		return map[string]any{}, nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated list %s", s)
+		}
+		var list []any
+		for _, part := range strings.Split(s[1:len(s)-1], ",") {
+			v, err := parseYAMLScalar(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+	switch strings.ToLower(s) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	v := map[string]any{
+		"Label":            "com.example.agent",
+		"ProgramArguments": []any{"/usr/local/bin/agent", "--serve"},
+		"RunAtLoad":        true,
+		"StartInterval":    3600,
+		"Empty":            "",
+		"EnvironmentVariables": map[string]any{
+			"PATH": "/usr/bin:/bin",
+		},
+	}
+	var b strings.Builder
+	if err := writeYAML(&b, v); err != nil {
+		t.Fatalf("writeYAML: %v", err)
+	}
+	want := `Empty: ""
+EnvironmentVariables:
+  PATH: "/usr/bin:/bin"
+Label: com.example.agent
+ProgramArguments:
+  - /usr/local/bin/agent
+  - "--serve"
+RunAtLoad: true
+StartInterval: 3600
+`
+	if b.String() != want {
+		t.Fatalf("unexpected YAML:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	doc := `# desired state
+login_items:
+  - path: /Applications/Slack.app
+    hidden: true
+  - path: '/Applications/It''s Here.app'
+background:
+  enabled: [com.example.agent, com.example.sync]
+  disabled:
+  - com.adobe.*   # every Adobe agent
+policy:
+  unlisted_background: disable
+`
+	var got map[string]any
+	if err := readYAML([]byte(doc), &got); err != nil {
+		t.Fatalf("readYAML: %v", err)
+	}
+	items := got["login_items"].([]any)
+	first := items[0].(map[string]any)
+	if first["path"] != "/Applications/Slack.app" || first["hidden"] != true {
+		t.Fatalf("unexpected first login item: %v", first)
+	}
+	if items[1].(map[string]any)["path"] != "/Applications/It's Here.app" {
+		t.Fatalf("unexpected second login item: %v", items[1])
+	}
+	bg := got["background"].(map[string]any)
+	if enabled := bg["enabled"].([]any); len(enabled) != 2 || enabled[1] != "com.example.sync" {
+		t.Fatalf("unexpected enabled list: %v", bg["enabled"])
+	}
+	if disabled := bg["disabled"].([]any); len(disabled) != 1 || disabled[0] != "com.adobe.*" {
+		t.Fatalf("unexpected disabled list: %v", bg["disabled"])
+	}
+	if got["policy"].(map[string]any)["unlisted_background"] != "disable" {
+		t.Fatalf("unexpected policy: %v", got["policy"])
+	}
+}
+
+func TestReadYAMLRoundTrip(t *testing.T) {
+	v := map[string]any{
+		"Label":                "com.example.agent",
+		"ProgramArguments":     []any{"/usr/local/bin/agent", "--serve"},
+		"EnvironmentVariables": map[string]any{"PATH": "/usr/bin:/bin"},
+		"Sockets":              []any{map[string]any{"SockServiceName": "8080"}},
+	}
+	var b strings.Builder
+	if err := writeYAML(&b, v); err != nil {
+		t.Fatalf("writeYAML: %v", err)
+	}
+	var got map[string]any
+	if err := readYAML([]byte(b.String()), &got); err != nil {
+		t.Fatalf("readYAML: %v\n%s", err, b.String())
+	}
+	if got["ProgramArguments"].([]any)[1] != "--serve" || got["EnvironmentVariables"].(map[string]any)["PATH"] != "/usr/bin:/bin" {
+		t.Fatalf("unexpected round trip: %v", got)
+	}
+	if got["Sockets"].([]any)[0].(map[string]any)["SockServiceName"] != "8080" {
+		t.Fatalf("unexpected sockets: %v", got["Sockets"])
+	}
+}
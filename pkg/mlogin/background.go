@@ -0,0 +1,255 @@
+package mlogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BackgroundItem is a launchd job: one found as a plist in a LaunchAgents or
+// LaunchDaemons directory, or one registered at runtime without a plist.
+type BackgroundItem struct {
+	Label string `json:"label"`
+	Path  string `json:"path"`
+	// Scope is user or system; Kind is agent, daemon, or registered.
+	Scope    string `json:"scope"`
+	Kind     string `json:"kind"`
+	Loaded   bool   `json:"loaded"`
+	Disabled *bool  `json:"disabled,omitempty"`
+	// Health is set for loaded items, see LaunchStatus.Health.
+	Health string `json:"health,omitempty"`
+	// Provenance is apple for macOS's own jobs and homebrew for
+	// `brew services` ones.
+	Provenance string `json:"provenance,omitempty"`
+}
+
+// LaunchDir is a directory launchd loads plists from.
+type LaunchDir struct {
+	Scope      string
+	Kind       string
+	Dir        string
+	Provenance string
+}
+
+// AppleLaunchDirs hold macOS's own jobs on the SIP-protected system volume.
+// They are only ever listed, never modified.
+var AppleLaunchDirs = []LaunchDir{
+	{Scope: "system", Kind: "agent", Dir: "/System/Library/LaunchAgents", Provenance: "apple"},
+	{Scope: "system", Kind: "daemon", Dir: "/System/Library/LaunchDaemons", Provenance: "apple"},
+}
+
+// LaunchDirs returns the LaunchAgents/LaunchDaemons directories for scope
+// (user, system, or all), with home's LaunchAgents for the user scope.
+func LaunchDirs(scope, home string) []LaunchDir {
+	var dirs []LaunchDir
+	if scope == "user" || scope == "all" {
+		dirs = append(dirs, LaunchDir{Scope: "user", Kind: "agent", Dir: filepath.Join(home, "Library/LaunchAgents")})
+	}
+	if scope == "system" || scope == "all" {
+		dirs = append(dirs,
+			LaunchDir{Scope: "system", Kind: "agent", Dir: "/Library/LaunchAgents"},
+			LaunchDir{Scope: "system", Kind: "daemon", Dir: "/Library/LaunchDaemons"},
+		)
+	}
+	return dirs
+}
+
+// ListOptions selects what ListBackgroundItems reports.
+type ListOptions struct {
+	// Scope is user, system, or all.
+	Scope string
+	// Home is the user whose ~/Library/LaunchAgents is listed; empty means
+	// the caller's.
+	Home string
+	// UserDomain is the GUI domain agents run in, such as "gui/501". Empty
+	// means the caller's, read with `launchctl list` when there is no GUI
+	// session to print.
+	UserDomain string
+	// IncludeApple adds the /System/Library directories for the system
+	// scope.
+	IncludeApple bool
+}
+
+// ListBackgroundItems scans the launchd directories for opts.Scope and
+// merges in what launchd has loaded and disabled, sorted by scope and
+// label. State it could not read is reported in the warnings rather than
+// failing the listing.
+func (c Client) ListBackgroundItems(ctx context.Context, opts ListOptions) ([]BackgroundItem, []string, error) {
+	scope := strings.ToLower(opts.Scope)
+	if scope != "user" && scope != "system" && scope != "all" {
+		return nil, nil, errors.New("scope must be user, system, or all")
+	}
+	home := opts.Home
+	if home == "" && scope != "system" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		home = h
+	}
+	dirs := LaunchDirs(scope, home)
+	if opts.IncludeApple && scope != "user" {
+		dirs = append(dirs, AppleLaunchDirs...)
+	}
+
+	// Agents, including those installed system-wide in /Library/LaunchAgents,
+	// run in the user's GUI session; daemons run in the system domain.
+	loadedUser := map[string]LaunchStatus{}
+	disabledByScope := map[string]map[string]bool{}
+	warnings := []string{}
+	userDomain := opts.UserDomain
+	if userDomain == "" {
+		userDomain = fmt.Sprintf("gui/%d", os.Getuid())
+	}
+	st := c.readDomainState(ctx, userDomain)
+	if st.loadedErr == nil {
+		loadedUser = st.loaded
+	} else if opts.UserDomain == "" {
+		// Outside a GUI session the domain cannot be printed, but
+		// launchctl list still reports the caller's own jobs.
+		if labels, err := c.LoadedServices(ctx, ""); err == nil {
+			loadedUser = labels
+		}
+	}
+	if scope == "user" || scope == "all" {
+		if st.disabledErr != nil {
+			warnings = append(warnings, "could not read user disabled state: "+st.disabledErr.Error())
+		} else {
+			disabledByScope["user"] = st.disabled
+		}
+	}
+
+	loadedSystem := map[string]LaunchStatus{}
+	if scope == "system" || scope == "all" {
+		st := c.readDomainState(ctx, "system")
+		if st.loadedErr != nil {
+			warnings = append(warnings, "could not read system loaded state (try sudo): "+st.loadedErr.Error())
+		} else {
+			loadedSystem = st.loaded
+		}
+		if st.disabledErr != nil {
+			warnings = append(warnings, "could not read system disabled state (try sudo): "+st.disabledErr.Error())
+		} else {
+			disabledByScope["system"] = st.disabled
+		}
+	}
+
+	var items []BackgroundItem
+	seen := map[string]bool{}
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d.Dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("could not read %s: %v", d.Dir, err))
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".plist") {
+				continue
+			}
+			p := filepath.Join(d.Dir, e.Name())
+			label, err := c.plistLabel(ctx, p)
+			if err != nil || label == "" {
+				continue
+			}
+			item := BackgroundItem{
+				Label:      label,
+				Path:       p,
+				Scope:      d.Scope,
+				Kind:       d.Kind,
+				Provenance: d.Provenance,
+			}
+			loaded := loadedUser
+			if d.Kind == "daemon" {
+				loaded = loadedSystem
+			}
+			if item.Provenance == "" && IsHomebrewItem(label, p) {
+				item.Provenance = "homebrew"
+			}
+			if st, ok := loaded[label]; ok {
+				item.Loaded = true
+				item.Health = st.Health()
+			}
+			if disabled, exists := disabledByScope[d.Scope][label]; exists {
+				item.Disabled = &disabled
+			}
+			items = append(items, item)
+			seen[label] = true
+		}
+	}
+
+	// Services registered at runtime (SMAppService, transient jobs) have no
+	// plist in the scanned directories but are still loaded.
+	registered := func(scope string, loaded map[string]LaunchStatus) {
+		for label, st := range loaded {
+			if seen[label] || isTransientLaunchLabel(label) {
+				continue
+			}
+			item := BackgroundItem{Label: label, Scope: scope, Kind: "registered", Loaded: true, Health: st.Health()}
+			if disabled, exists := disabledByScope[scope][label]; exists {
+				item.Disabled = &disabled
+			}
+			items = append(items, item)
+			seen[label] = true
+		}
+	}
+	if scope == "user" || scope == "all" {
+		registered("user", loadedUser)
+	}
+	if scope == "system" || scope == "all" {
+		registered("system", loadedSystem)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Scope != items[j].Scope {
+			return items[i].Scope < items[j].Scope
+		}
+		return items[i].Label < items[j].Label
+	})
+	return items, warnings, nil
+}
+
+// isTransientLaunchLabel reports labels launchd creates for app instances and
+// anonymous processes rather than for background jobs.
+func isTransientLaunchLabel(label string) bool {
+	return strings.HasPrefix(label, "application.") ||
+		strings.HasPrefix(label, "[") ||
+		strings.HasPrefix(label, "0x") ||
+		strings.Contains(label, "anonymous")
+}
+
+// plistLabel reads the Label key of the plist at path.
+func (c Client) plistLabel(ctx context.Context, path string) (string, error) {
+	out, err := c.output(ctx, "/usr/libexec/PlistBuddy", "-c", "Print :Label", path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// domainState is what launchd has loaded and disabled in one domain.
+type domainState struct {
+	loaded      map[string]LaunchStatus
+	disabled    map[string]bool
+	loadedErr   error
+	disabledErr error
+}
+
+// readDomainState reads domain with a single `launchctl print`, which
+// reports both loaded services and disabled overrides. When that fails,
+// disabled state still comes from print-disabled, which needs no session.
+func (c Client) readDomainState(ctx context.Context, domain string) domainState {
+	d, err := c.PrintDomain(ctx, domain)
+	if err == nil {
+		return domainState{loaded: d.Services, disabled: d.Disabled}
+	}
+	st := domainState{loadedErr: err}
+	st.disabled, st.disabledErr = c.DisabledServices(ctx, domain)
+	return st
+}
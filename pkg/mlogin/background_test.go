@@ -0,0 +1,66 @@
+package mlogin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsTransientLaunchLabel(t *testing.T) {
+	for _, label := range []string{"application.com.apple.Safari.1234.5678", "[0x0-0x1a01a].com.google.Chrome", "0x7f8b1c40a0.anonymous.zsh"} {
+		if !isTransientLaunchLabel(label) {
+			t.Fatalf("expected %q to be transient", label)
+		}
+	}
+	if isTransientLaunchLabel("com.example.helper") {
+		t.Fatalf("expected com.example.helper to be kept")
+	}
+}
+
+func TestReadDomainState(t *testing.T) {
+	c := Client{Runner: cannedRunner{
+		"launchctl print system":           "system = {\n\tservices = {\n\t\t  0  0  com.example.daemon\n\t}\n\tdisabled services = {\n\t\t\"com.example.old\" => disabled\n\t}\n}\n",
+		"launchctl print-disabled gui/501": "disabled services = {\n\t\"com.example.agent\" => disabled\n}\n",
+	}}
+	st := c.readDomainState(context.Background(), "system")
+	if st.loadedErr != nil || st.disabledErr != nil || len(st.loaded) != 1 || !st.disabled["com.example.old"] {
+		t.Fatalf("unexpected system state %+v", st)
+	}
+	st = c.readDomainState(context.Background(), "gui/501")
+	if st.loadedErr == nil || st.disabledErr != nil || !st.disabled["com.example.agent"] {
+		t.Fatalf("unexpected gui state %+v", st)
+	}
+}
+
+func TestListBackgroundItems(t *testing.T) {
+	home := t.TempDir()
+	agents := filepath.Join(home, "Library/LaunchAgents")
+	if err := os.MkdirAll(agents, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plist := filepath.Join(agents, "com.example.agent.plist")
+	if err := os.WriteFile(plist, []byte("<plist/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := Client{Runner: cannedRunner{
+		"/usr/libexec/PlistBuddy -c Print :Label " + plist: "com.example.agent\n",
+		"launchctl print gui/501":                          "gui/501 = {\n\tservices = {\n\t\t412  0  com.example.agent\n\t\t-  0  com.example.registered\n\t\t-  0  application.com.apple.Safari.1.2\n\t}\n\tdisabled services = {\n\t\t\"com.example.registered\" => disabled\n\t}\n}\n",
+	}}
+	items, warnings, err := c.ListBackgroundItems(context.Background(), ListOptions{Scope: "user", Home: home, UserDomain: "gui/501"})
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("ListBackgroundItems: %v, %v", err, warnings)
+	}
+	if len(items) != 2 {
+		t.Fatalf("unexpected items %+v", items)
+	}
+	if it := items[0]; it.Label != "com.example.agent" || it.Path != plist || it.Kind != "agent" || !it.Loaded || it.Health != "running" || it.Disabled != nil {
+		t.Fatalf("unexpected plist item %+v", it)
+	}
+	if it := items[1]; it.Label != "com.example.registered" || it.Kind != "registered" || it.Disabled == nil || !*it.Disabled {
+		t.Fatalf("unexpected registered item %+v", it)
+	}
+	if _, _, err := c.ListBackgroundItems(context.Background(), ListOptions{Scope: "nobody"}); err == nil {
+		t.Fatalf("expected an error for an unknown scope")
+	}
+}
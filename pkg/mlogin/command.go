@@ -0,0 +1,123 @@
+package mlogin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnrecognizedOutput is returned when a tool's output does not have the
+// shape this package knows, typically after a macOS update changed it.
+var ErrUnrecognizedOutput = errors.New("unrecognized output")
+
+// ErrNotFound matches the error of a change whose target does not exist,
+// such as a launchd service that is not loaded or a login item that is not
+// there.
+var ErrNotFound = errors.New("not found")
+
+// notFoundError marks a tool's failure as being about a missing target
+// while keeping its message.
+type notFoundError struct{ err error }
+
+func (e notFoundError) Error() string        { return e.err.Error() }
+func (e notFoundError) Is(target error) bool { return target == ErrNotFound }
+func (e notFoundError) Unwrap() error        { return e.err }
+
+// Command is one invocation of a system tool.
+type Command struct {
+	Name string
+	Args []string
+	// Env holds variables added to the tool's environment.
+	Env map[string]string
+	// Changes marks a call that changes state rather than reading it, so
+	// a dry-run Runner can print it instead of running it.
+	Changes bool
+}
+
+// Runner runs system tools. Swapping it lets tests feed canned output and
+// lets a caller preview changes, or run the tools on another Mac.
+//
+// Run returns the tool's stdout, even when it fails. A tool that fails
+// returns a *CommandError; a context that ends first returns an error
+// wrapping the context's.
+type Runner interface {
+	Run(ctx context.Context, c Command) ([]byte, error)
+}
+
+// ExecRunner runs tools on this Mac.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(ctx context.Context, c Command) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	if len(c.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range c.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name, ctx.Err())
+		}
+		return out, &CommandError{Args: cmd.Args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return out, nil
+}
+
+// CommandError is a system tool that failed or could not be started.
+type CommandError struct {
+	// Args is the command line, starting with the tool's name.
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	args := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		// Scripts passed with -e would swamp the message.
+		if strings.Contains(a, "\n") {
+			a = "<script>"
+		}
+		args[i] = a
+	}
+	msg := fmt.Sprintf("%s: %v", strings.Join(args, " "), e.Err)
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// Client reads and changes autostart state by running system tools through
+// Runner. The zero Client runs them on this Mac.
+type Client struct {
+	Runner Runner
+}
+
+// Run runs cmd through the Client's Runner, for tools it has no method for.
+func (c Client) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	r := c.Runner
+	if r == nil {
+		r = ExecRunner{}
+	}
+	return r.Run(ctx, cmd)
+}
+
+func (c Client) output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return c.Run(ctx, Command{Name: name, Args: args})
+}
+
+// change runs a tool that changes state, marked so a dry-run Runner can
+// print it instead.
+func (c Client) change(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return c.Run(ctx, Command{Name: name, Args: args, Changes: true})
+}
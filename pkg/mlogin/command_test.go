@@ -0,0 +1,116 @@
+package mlogin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cannedRunner answers each command line with fixed output.
+type cannedRunner map[string]string
+
+func (r cannedRunner) Run(_ context.Context, c Command) ([]byte, error) {
+	args := append([]string{c.Name}, c.Args...)
+	out, ok := r[strings.Join(args, " ")]
+	if !ok {
+		return nil, &CommandError{Args: args, Err: errors.New("exit status 1")}
+	}
+	return []byte(out), nil
+}
+
+func TestClientRunner(t *testing.T) {
+	c := Client{Runner: cannedRunner{
+		"launchctl print-disabled system": "disabled services = {\n\t\"com.example.daemon\" => disabled\n}\n",
+		"systemextensionsctl list":        systemExtensionsFixtures["sonoma"],
+	}}
+	disabled, err := c.DisabledServices(context.Background(), "system")
+	if err != nil || !disabled["com.example.daemon"] {
+		t.Fatalf("DisabledServices = %v, %v", disabled, err)
+	}
+	exts, err := c.ListSystemExtensions(context.Background())
+	if err != nil || len(exts) != 2 {
+		t.Fatalf("ListSystemExtensions = %v, %v", exts, err)
+	}
+	var cmdErr *CommandError
+	if _, err := c.LoadedServices(context.Background(), ""); !errors.As(err, &cmdErr) {
+		t.Fatalf("expected a CommandError, got %v", err)
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	_, err := ExecRunner{}.Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "echo $WHO >&2; exit 3"}, Env: map[string]string{"WHO": "nope"}})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Stderr != "nope" {
+		t.Fatalf("expected a CommandError with stderr, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = ExecRunner{}.Run(ctx, Command{Name: "sleep", Args: []string{"5"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline as the error, got %v", err)
+	}
+	if msg := (&CommandError{Args: []string{"osascript", "-e", "a\nb"}, Err: errors.New("exit status 1")}).Error(); !strings.HasPrefix(msg, "osascript -e <script>:") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+// changeRunner records the commands it runs and fails each with stderr.
+type changeRunner struct {
+	cmds   []Command
+	stderr string
+}
+
+func (r *changeRunner) Run(_ context.Context, c Command) ([]byte, error) {
+	r.cmds = append(r.cmds, c)
+	return nil, &CommandError{Args: append([]string{c.Name}, c.Args...), Stderr: r.stderr, Err: errors.New("exit status 1")}
+}
+
+func TestClientChanges(t *testing.T) {
+	r := &changeRunner{stderr: `Could not find service "com.example.gone" in domain for port`}
+	c := Client{Runner: r}
+	ctx := context.Background()
+	if err := c.UnloadService(ctx, "gui/501/com.example.gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := c.Launchctl(ctx, "print", "gui/501/com.example.gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	r.stderr = "execution error: Error: no matching login item found (-2700)"
+	if err := c.RemoveLoginItem(ctx, "Slack", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	r.stderr = "Operation not permitted"
+	if err := c.DisableService(ctx, "system/com.example.daemon"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := c.AddLoginItem(ctx, "/Applications/Slack.app", true); err == nil {
+		t.Fatalf("expected the failure to be returned")
+	}
+
+	for i, c := range r.cmds {
+		if c.Changes != (i != 1) {
+			t.Fatalf("%s %v: Changes = %v", c.Name, c.Args, c.Changes)
+		}
+	}
+	if add := r.cmds[len(r.cmds)-1]; add.Env["ADD_PATH"] != "/Applications/Slack.app" || add.Env["ADD_HIDDEN"] != "true" {
+		t.Fatalf("unexpected login item environment %v", add.Env)
+	}
+	removes := 0
+	for _, c := range r.cmds {
+		if !strings.Contains(strings.Join(c.Args, " "), "REMOVE_NAME") {
+			continue
+		}
+		removes++
+		if name, ok := c.Env["REMOVE_NAME"]; !ok || name != "Slack" {
+			t.Fatalf("unexpected REMOVE_NAME in %v", c.Env)
+		}
+		if path, ok := c.Env["REMOVE_PATH"]; !ok || path != "" {
+			t.Fatalf("REMOVE_PATH must be set, even if empty: %v", c.Env)
+		}
+	}
+	if removes == 0 {
+		t.Fatal("RemoveLoginItem ran no command")
+	}
+}
@@ -0,0 +1,18 @@
+// Package mlogin reads and changes macOS autostart state the way the
+// mlogin command does, for Go programs (menu bar apps, agents) that want
+// to embed it rather than shell out to the CLI.
+//
+// A Client runs the system tools through its Runner, locally by default;
+// tests and previews plug in their own. Its methods take a context, which
+// bounds how long a tool may run; System Events in particular can hang
+// behind an Automation prompt. A tool that fails returns a *CommandError
+// with its stderr. The Parse functions work on output captured elsewhere,
+// e.g. on another Mac over ssh.
+//
+// ListBackgroundItems lists launchd jobs with what launchd has loaded and
+// disabled; login items and system extensions have their own listings.
+// The methods that change something (enabling a service, removing a login
+// item, ...) run their tool with Command.Changes set, and fail with an
+// error matching ErrNotFound when their target does not exist. The CLI's
+// audits, snapshots and reports build on these and stay in cmd/mlogin.
+package mlogin
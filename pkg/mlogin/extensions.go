@@ -0,0 +1,159 @@
+package mlogin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SystemExtensionItem is a row of `systemextensionsctl list`. The host app
+// fields are filled in by the CLI from sysextd's database.
+type SystemExtensionItem struct {
+	Category string `json:"category"`
+	Enabled  bool   `json:"enabled"`
+	Active   bool   `json:"active"`
+	TeamID   string `json:"team_id"`
+	BundleID string `json:"bundle_id"`
+	Version  string `json:"version,omitempty"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	// HostApp is the app bundle that installed the extension.
+	HostApp        string `json:"host_app,omitempty"`
+	HostAppVersion string `json:"host_app_version,omitempty"`
+	HostAppExists  *bool  `json:"host_app_exists,omitempty"`
+}
+
+var (
+	// sysextBundleRe finds the "bundle.id (version)" column, which every
+	// macOS release so far prints, regardless of column layout or language.
+	sysextBundleRe = regexp.MustCompile(`[A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)+ \([^()]*\)`)
+	sysextCountRe  = regexp.MustCompile(`^(\d+) `)
+)
+
+// ParseSystemExtensions parses `systemextensionsctl list` output. Rows are
+// recognized by their bundle column rather than by position, so header
+// wording, empty enabled/active columns, and space- instead of
+// tab-separated output all parse. A state that wraps onto following lines
+// is joined back together. If the summary line announces extensions but no
+// row could be parsed, it returns an error instead of an empty list.
+func ParseSystemExtensions(out string) ([]SystemExtensionItem, error) {
+	var items []SystemExtensionItem
+	expected := -1
+	category := ""
+	openState := false
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		raw := strings.TrimRight(s.Text(), " \t")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if openState {
+			last := &items[len(items)-1]
+			part, closed := strings.CutSuffix(line, "]")
+			last.State += " " + part
+			openState = !closed
+			continue
+		}
+		if strings.HasPrefix(line, "---") {
+			if parts := strings.Fields(strings.TrimLeft(line, "- ")); len(parts) > 0 {
+				category = parts[0]
+			}
+			continue
+		}
+		if expected < 0 {
+			if m := sysextCountRe.FindStringSubmatch(line); m != nil {
+				expected, _ = strconv.Atoi(m[1])
+				continue
+			}
+		}
+		item, ok := parseSystemExtensionRow(raw)
+		if !ok {
+			continue
+		}
+		item.Category = category
+		openState = strings.Contains(raw, "[") && !strings.HasSuffix(line, "]")
+		items = append(items, item)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if expected > 0 && len(items) == 0 {
+		return nil, fmt.Errorf("systemextensionsctl reported %d extension(s) but none could be parsed: %w", expected, ErrUnrecognizedOutput)
+	}
+	return items, nil
+}
+
+// parseSystemExtensionRow parses one extension row around its bundle
+// column: the enabled/active markers and Team ID come before it, the name
+// and bracketed state after.
+func parseSystemExtensionRow(line string) (SystemExtensionItem, bool) {
+	loc := sysextBundleRe.FindStringIndex(line)
+	if loc == nil {
+		return SystemExtensionItem{}, false
+	}
+	var item SystemExtensionItem
+	item.BundleID, item.Version = ParseBundleVersion(line[loc[0]:loc[1]])
+
+	before := line[:loc[0]]
+	if strings.Contains(before, "\t") {
+		cols := strings.Split(before, "\t")
+		item.Enabled = strings.TrimSpace(cols[0]) == "*"
+		item.Active = len(cols) > 1 && strings.TrimSpace(cols[1]) == "*"
+	} else {
+		// Without tabs the empty columns are lost; active implies enabled.
+		stars := strings.Count(before, "*")
+		item.Enabled = stars >= 1
+		item.Active = stars >= 2
+	}
+	if fields := strings.Fields(strings.ReplaceAll(before, "*", "")); len(fields) > 0 {
+		team := fields[len(fields)-1]
+		if team != "-" && team != "(null)" {
+			item.TeamID = team
+		}
+	}
+
+	after := strings.TrimSpace(line[loc[1]:])
+	name, state, hasState := strings.Cut(after, "[")
+	item.Name = strings.TrimSpace(name)
+	if hasState {
+		item.State = strings.TrimSpace(strings.TrimSuffix(state, "]"))
+	}
+	return item, true
+}
+
+// ParseBundleVersion splits "bundle.id (version)".
+func ParseBundleVersion(value string) (string, string) {
+	i := strings.LastIndex(value, " (")
+	if i == -1 || !strings.HasSuffix(value, ")") {
+		return value, ""
+	}
+	return value[:i], strings.TrimSuffix(strings.TrimPrefix(value[i+1:], "("), ")")
+}
+
+// ListSystemExtensions runs `systemextensionsctl list`.
+func (c Client) ListSystemExtensions(ctx context.Context) ([]SystemExtensionItem, error) {
+	out, err := c.output(ctx, "systemextensionsctl", "list")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSystemExtensions(string(out))
+}
+
+// UninstallSystemExtension asks sysextd to remove an extension and returns
+// what systemextensionsctl printed. macOS may only finish once the
+// extension's host app runs again, or after a reboot.
+func (c Client) UninstallSystemExtension(ctx context.Context, teamID, bundleID string) (string, error) {
+	out, err := c.change(ctx, "systemextensionsctl", "uninstall", teamID, bundleID)
+	msg := strings.TrimSpace(string(out))
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Stderr == "" {
+		// systemextensionsctl reports most failures on stdout.
+		cmdErr.Stderr = msg
+	}
+	return msg, err
+}
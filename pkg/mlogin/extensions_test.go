@@ -0,0 +1,88 @@
+package mlogin
+
+import (
+	"errors"
+	"testing"
+)
+
+// systemextensionsctl list output as printed by different macOS releases.
+var systemExtensionsFixtures = map[string]string{
+	"sonoma": "2 extension(s)\n" +
+		"--- com.apple.system_extension.network_extension\n" +
+		"enabled\tactive\tteamID\tbundleID (version)\tname\t[state]\n" +
+		"*\t*\tW5364U7YZB\tio.tailscale.ipn.macsys.network-extension (1.94.1/101.94.1)\tTailscale Network Extension\t[activated enabled]\n" +
+		"\t\tW5364U7YZB\tio.tailscale.ipn.macsys.network-extension (1.92.0/101.92.0)\tTailscale Network Extension\t[terminated waiting to uninstall on reboot]\n",
+	"sequoia": "2 extension(s)\n" +
+		"--- com.apple.system_extension.network_extension (Go to 'System Settings > General > Login Items & Extensions > Network Extensions' to modify these extensions)\n" +
+		"enabled\tactive\tteamID\tbundleID (version)\tname\t[state]\n" +
+		"*\t*\tW5364U7YZB\tio.tailscale.ipn.macsys.network-extension (1.94.1/101.94.1)\tTailscale Network Extension\t[activated enabled]\n" +
+		"--- com.apple.system_extension.endpoint_security\n" +
+		"enabled\tactive\tteamID\tbundleID (version)\tname\t[state]\n" +
+		"*\t\tABCDE12345\tcom.example.es (2.0/200)\tExample ES\t[activated waiting for user]\n",
+	"localized": "2 Erweiterung(en)\n" +
+		"--- com.apple.system_extension.network_extension\n" +
+		"aktiviert\taktiv\tTeam-ID\tBundle-ID (Version)\tName\t[Status]\n" +
+		"*\t*\tW5364U7YZB\tio.tailscale.ipn.macsys.network-extension (1.94.1/101.94.1)\tTailscale Network Extension\t[activated enabled]\n" +
+		"*\t*\tABCDE12345\tcom.example.vpn.tunnel (3.1/31)\tExample VPN\t[activated enabled]\n",
+	"spaces-wrapped": "2 extension(s)\n" +
+		"--- com.apple.system_extension.driver_extension\n" +
+		"enabled active teamID bundleID (version) name [state]\n" +
+		"* * ABCDE12345 com.example.driver (1.0/1) Example Driver [activated\n" +
+		"    enabled]\n" +
+		"    -          com.example.unsigned (0.1/1) Unsigned Driver [terminated waiting to uninstall on reboot]\n",
+}
+
+func TestParseSystemExtensions(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []SystemExtensionItem
+	}{
+		{"sonoma", []SystemExtensionItem{
+			{Category: "com.apple.system_extension.network_extension", Enabled: true, Active: true, TeamID: "W5364U7YZB", BundleID: "io.tailscale.ipn.macsys.network-extension", Version: "1.94.1/101.94.1", Name: "Tailscale Network Extension", State: "activated enabled"},
+			{Category: "com.apple.system_extension.network_extension", TeamID: "W5364U7YZB", BundleID: "io.tailscale.ipn.macsys.network-extension", Version: "1.92.0/101.92.0", Name: "Tailscale Network Extension", State: "terminated waiting to uninstall on reboot"},
+		}},
+		{"sequoia", []SystemExtensionItem{
+			{Category: "com.apple.system_extension.network_extension", Enabled: true, Active: true, TeamID: "W5364U7YZB", BundleID: "io.tailscale.ipn.macsys.network-extension", Version: "1.94.1/101.94.1", Name: "Tailscale Network Extension", State: "activated enabled"},
+			{Category: "com.apple.system_extension.endpoint_security", Enabled: true, TeamID: "ABCDE12345", BundleID: "com.example.es", Version: "2.0/200", Name: "Example ES", State: "activated waiting for user"},
+		}},
+		{"localized", []SystemExtensionItem{
+			{Category: "com.apple.system_extension.network_extension", Enabled: true, Active: true, TeamID: "W5364U7YZB", BundleID: "io.tailscale.ipn.macsys.network-extension", Version: "1.94.1/101.94.1", Name: "Tailscale Network Extension", State: "activated enabled"},
+			{Category: "com.apple.system_extension.network_extension", Enabled: true, Active: true, TeamID: "ABCDE12345", BundleID: "com.example.vpn.tunnel", Version: "3.1/31", Name: "Example VPN", State: "activated enabled"},
+		}},
+		{"spaces-wrapped", []SystemExtensionItem{
+			{Category: "com.apple.system_extension.driver_extension", Enabled: true, Active: true, TeamID: "ABCDE12345", BundleID: "com.example.driver", Version: "1.0/1", Name: "Example Driver", State: "activated enabled"},
+			{Category: "com.apple.system_extension.driver_extension", BundleID: "com.example.unsigned", Version: "0.1/1", Name: "Unsigned Driver", State: "terminated waiting to uninstall on reboot"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSystemExtensions(systemExtensionsFixtures[tt.fixture])
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fixture, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: expected %d items, got %d (%+v)", tt.fixture, len(tt.want), len(got), got)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("%s: item %d = %+v, want %+v", tt.fixture, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseSystemExtensionsUnparseable(t *testing.T) {
+	_, err := ParseSystemExtensions("1 extension(s)\nsomething entirely different\n")
+	if !errors.Is(err, ErrUnrecognizedOutput) {
+		t.Fatalf("expected ErrUnrecognizedOutput, got %v", err)
+	}
+}
+
+func TestParseBundleVersion(t *testing.T) {
+	bundle, version := ParseBundleVersion("io.tailscale.ipn.macsys.network-extension (1.94.1/101.94.1)")
+	if bundle != "io.tailscale.ipn.macsys.network-extension" {
+		t.Fatalf("unexpected bundle: %q", bundle)
+	}
+	if version != "1.94.1/101.94.1" {
+		t.Fatalf("unexpected version: %q", version)
+	}
+}
@@ -0,0 +1,57 @@
+package mlogin
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// homebrewLabelPrefix is the label prefix `brew services` uses for the
+// LaunchAgents/Daemons it installs.
+const homebrewLabelPrefix = "homebrew.mxcl."
+
+// IsHomebrewItem reports whether a background item is managed by
+// `brew services`, either by label or because its plist links into a
+// Homebrew prefix.
+func IsHomebrewItem(label, plistPath string) bool {
+	if strings.HasPrefix(label, homebrewLabelPrefix) {
+		return true
+	}
+	if plistPath == "" {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(plistPath)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(target, "/Cellar/") || strings.HasPrefix(target, "/opt/homebrew/")
+}
+
+// HomebrewFormula returns the formula behind a brew services item: from a
+// homebrew.mxcl. label, or from the Cellar directory its plist links into.
+// It returns "" when neither names one.
+func HomebrewFormula(label, plistPath string) string {
+	if strings.HasPrefix(label, homebrewLabelPrefix) {
+		return strings.TrimPrefix(label, homebrewLabelPrefix)
+	}
+	if plistPath == "" {
+		return ""
+	}
+	target, err := filepath.EvalSymlinks(plistPath)
+	if err != nil {
+		return ""
+	}
+	return cellarFormula(target)
+}
+
+// cellarFormula returns <formula> from a path under .../Cellar/<formula>/.
+func cellarFormula(path string) string {
+	_, rest, ok := strings.Cut(path, "/Cellar/")
+	if !ok {
+		return ""
+	}
+	formula, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return ""
+	}
+	return formula
+}
@@ -0,0 +1,41 @@
+package mlogin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsHomebrewItem(t *testing.T) {
+	if !IsHomebrewItem("homebrew.mxcl.postgresql@16", "") {
+		t.Fatalf("expected homebrew label to be detected")
+	}
+	if got := HomebrewFormula("homebrew.mxcl.postgresql@16", ""); got != "postgresql@16" {
+		t.Fatalf("unexpected formula %q", got)
+	}
+	if IsHomebrewItem("com.example.agent", "/nonexistent/com.example.agent.plist") {
+		t.Fatalf("expected non-homebrew item")
+	}
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "Cellar", "syncthing", "1.27.0", "com.github.syncthing.plist")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("<plist/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "com.github.syncthing.plist")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	if !IsHomebrewItem("com.github.syncthing", link) {
+		t.Fatalf("expected a plist linking into the Cellar to be detected")
+	}
+	if got := HomebrewFormula("com.github.syncthing", link); got != "syncthing" {
+		t.Fatalf("unexpected formula %q", got)
+	}
+	if got := HomebrewFormula("com.example.agent", "/nonexistent/com.example.agent.plist"); got != "" {
+		t.Fatalf("unexpected formula %q", got)
+	}
+}
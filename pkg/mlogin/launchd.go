@@ -0,0 +1,283 @@
+package mlogin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// LaunchStatus is a service's row in `launchctl list` or the services block
+// of `launchctl print <domain>`.
+type LaunchStatus struct {
+	PID      int
+	LastExit int
+	HasExit  bool
+}
+
+// Health summarizes a loaded service from its list row: running, ok (idle
+// after a clean exit), or failing (idle after a non-zero exit or signal).
+func (st LaunchStatus) Health() string {
+	switch {
+	case st.PID > 0:
+		return "running"
+	case st.HasExit && st.LastExit != 0:
+		return "failing"
+	default:
+		return "ok"
+	}
+}
+
+// ParseLaunchStatusRow parses "<pid> <status> <label>" where pid and status
+// may be "-" when the service is not running or has never exited.
+func ParseLaunchStatusRow(line string) (string, LaunchStatus, bool) {
+	parts := strings.Fields(line)
+	if len(parts) < 3 {
+		return "", LaunchStatus{}, false
+	}
+	var st LaunchStatus
+	if pid, err := strconv.Atoi(parts[0]); err == nil {
+		st.PID = pid
+	} else if parts[0] != "-" {
+		return "", LaunchStatus{}, false
+	}
+	if code, err := strconv.Atoi(parts[1]); err == nil {
+		st.LastExit = code
+		st.HasExit = true
+	}
+	return parts[len(parts)-1], st, true
+}
+
+// ParseLaunchctlList parses `launchctl list`, the services of the caller's
+// own session, keyed by label.
+func ParseLaunchctlList(out string) (map[string]LaunchStatus, error) {
+	labels := map[string]LaunchStatus{}
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "PID") {
+			continue
+		}
+		if label, st, ok := ParseLaunchStatusRow(line); ok {
+			labels[label] = st
+		}
+	}
+	return labels, s.Err()
+}
+
+// ParseLaunchctlPrintServices extracts labels from the "services = { ... }"
+// block of `launchctl print <domain>`, whose rows are "<pid> <status> <label>".
+func ParseLaunchctlPrintServices(out string) map[string]LaunchStatus {
+	labels := map[string]LaunchStatus{}
+	inServices := false
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !inServices {
+			inServices = line == "services = {"
+			continue
+		}
+		if line == "}" {
+			break
+		}
+		if label, st, ok := ParseLaunchStatusRow(line); ok {
+			labels[label] = st
+		}
+	}
+	return labels
+}
+
+// ParseLaunchctlDisabled parses `launchctl print-disabled <domain>`: true
+// for each label the domain has disabled, false for those it has
+// explicitly enabled.
+func ParseLaunchctlDisabled(out string) (map[string]bool, error) {
+	labels := map[string]bool{}
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !strings.Contains(line, "=>") {
+			continue
+		}
+		parts := strings.Split(line, "=>")
+		if len(parts) != 2 {
+			continue
+		}
+		label := strings.Trim(strings.TrimSpace(parts[0]), `"`)
+		state := strings.Trim(strings.TrimSpace(strings.TrimSuffix(parts[1], ";")), `"`)
+		if label == "" {
+			continue
+		}
+		labels[label] = state == "disabled" || state == "true"
+	}
+	return labels, s.Err()
+}
+
+// LaunchDomain is a launchd domain as `launchctl print <domain>` reports
+// it: its services and its enabled/disabled overrides, from one call.
+type LaunchDomain struct {
+	Services map[string]LaunchStatus
+	Disabled map[string]bool
+}
+
+// ParseLaunchctlPrintDomain reads the "services" and "disabled services"
+// blocks of `launchctl print <domain>`.
+func ParseLaunchctlPrintDomain(out string) (LaunchDomain, error) {
+	d := LaunchDomain{Services: map[string]LaunchStatus{}, Disabled: map[string]bool{}}
+	var block string
+	depth := 0
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case strings.HasSuffix(line, "= {"):
+			depth++
+			if depth == 2 {
+				block = strings.TrimSpace(strings.TrimSuffix(line, "= {"))
+			}
+			continue
+		case line == "}":
+			depth--
+			block = ""
+			continue
+		}
+		if depth != 2 {
+			continue
+		}
+		switch block {
+		case "services":
+			if label, st, ok := ParseLaunchStatusRow(line); ok {
+				d.Services[label] = st
+			}
+		case "disabled services":
+			disabled, err := ParseLaunchctlDisabled(line)
+			if err != nil {
+				return LaunchDomain{}, err
+			}
+			for label, v := range disabled {
+				d.Disabled[label] = v
+			}
+		}
+	}
+	return d, s.Err()
+}
+
+// PrintDomain reads domain ("system", "gui/501", ...) with one
+// `launchctl print`.
+func (c Client) PrintDomain(ctx context.Context, domain string) (LaunchDomain, error) {
+	out, err := c.output(ctx, "launchctl", "print", domain)
+	if err != nil {
+		return LaunchDomain{}, err
+	}
+	return ParseLaunchctlPrintDomain(string(out))
+}
+
+// LoadedServices returns the services launchd has loaded in domain
+// ("system", "gui/501", ...), or in the caller's own session when domain
+// is empty.
+func (c Client) LoadedServices(ctx context.Context, domain string) (map[string]LaunchStatus, error) {
+	if domain == "" {
+		out, err := c.output(ctx, "launchctl", "list")
+		if err != nil {
+			return nil, err
+		}
+		return ParseLaunchctlList(string(out))
+	}
+	out, err := c.output(ctx, "launchctl", "print", domain)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLaunchctlPrintServices(string(out)), nil
+}
+
+// DisabledServices returns the enabled/disabled overrides of domain, see
+// ParseLaunchctlDisabled.
+func (c Client) DisabledServices(ctx context.Context, domain string) (map[string]bool, error) {
+	out, err := c.output(ctx, "launchctl", "print-disabled", domain)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLaunchctlDisabled(string(out))
+}
+
+// Launchctl runs launchctl with args to read state, such as "blame" or
+// "procinfo", and returns its stdout. A service target launchd does not
+// know fails with an error matching ErrNotFound.
+func (c Client) Launchctl(ctx context.Context, args ...string) (string, error) {
+	out, err := c.output(ctx, "launchctl", args...)
+	return string(out), launchctlError(err)
+}
+
+// EnableService clears the disabled override of target
+// ("gui/501/com.example.agent"), so launchd loads it again at login or boot.
+func (c Client) EnableService(ctx context.Context, target string) error {
+	_, err := c.change(ctx, "launchctl", "enable", target)
+	return launchctlError(err)
+}
+
+// DisableService sets the disabled override of target. A loaded service
+// keeps running until it is unloaded.
+func (c Client) DisableService(ctx context.Context, target string) error {
+	_, err := c.change(ctx, "launchctl", "disable", target)
+	return launchctlError(err)
+}
+
+// LoadService bootstraps the plist at path into domain.
+func (c Client) LoadService(ctx context.Context, domain, path string) error {
+	_, err := c.change(ctx, "launchctl", "bootstrap", domain, path)
+	return launchctlError(err)
+}
+
+// UnloadService boots target out of its domain, stopping it.
+func (c Client) UnloadService(ctx context.Context, target string) error {
+	_, err := c.change(ctx, "launchctl", "bootout", target)
+	return launchctlError(err)
+}
+
+// KickstartService starts target now, or restarts it with kill, and
+// returns its PID when launchctl reports one.
+func (c Client) KickstartService(ctx context.Context, target string, kill bool) (int, error) {
+	args := []string{"kickstart", "-p"}
+	if kill {
+		args = append(args, "-k")
+	}
+	out, err := c.change(ctx, "launchctl", append(args, target)...)
+	if err != nil {
+		return 0, launchctlError(err)
+	}
+	pid, _ := parseKickstartPID(string(out))
+	return pid, nil
+}
+
+// parseKickstartPID extracts the PID printed by `launchctl kickstart -p`.
+func parseKickstartPID(out string) (int, bool) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+// launchctlNoService is the status launchctl exits with when the service
+// target does not exist.
+const launchctlNoService = 113
+
+// launchctlError marks a launchctl failure about a missing service as
+// ErrNotFound.
+func launchctlError(err error) error {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return err
+	}
+	var exitErr *exec.ExitError
+	if strings.Contains(cmdErr.Stderr, "Could not find service") ||
+		errors.As(cmdErr.Err, &exitErr) && exitErr.ExitCode() == launchctlNoService {
+		return notFoundError{err: err}
+	}
+	return err
+}
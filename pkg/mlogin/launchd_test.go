@@ -0,0 +1,106 @@
+package mlogin
+
+import "testing"
+
+func TestParseLaunchctlPrintServices(t *testing.T) {
+	out := `system = {
+	type = system
+	services = {
+		       0      -    com.apple.foo
+		     412      0    com.example.daemon
+	}
+
+	unmanaged processes = {
+		com.apple.bar
+	}
+}`
+	labels := ParseLaunchctlPrintServices(out)
+	if len(labels) != 2 {
+		t.Fatalf("unexpected labels: %v", labels)
+	}
+	if st, ok := labels["com.example.daemon"]; !ok || st.PID != 412 || st.Health() != "running" {
+		t.Fatalf("unexpected com.example.daemon status: %+v", st)
+	}
+	if st, ok := labels["com.apple.foo"]; !ok || st.Health() != "ok" {
+		t.Fatalf("unexpected com.apple.foo status: %+v", st)
+	}
+}
+
+func TestParseLaunchStatusRow(t *testing.T) {
+	label, st, ok := ParseLaunchStatusRow("-\t78\tcom.example.crashy")
+	if !ok || label != "com.example.crashy" || st.PID != 0 || st.LastExit != 78 {
+		t.Fatalf("unexpected row: %q %+v %v", label, st, ok)
+	}
+	if st.Health() != "failing" {
+		t.Fatalf("expected failing, got %q", st.Health())
+	}
+	if _, _, ok := ParseLaunchStatusRow("PID\tStatus\tLabel"); ok {
+		t.Fatalf("header row should not parse")
+	}
+}
+
+func TestParseLaunchctlDisabled(t *testing.T) {
+	out := `disabled services = {
+		"com.example.agent" => disabled
+		"com.example.helper" => enabled
+	}`
+	labels, err := ParseLaunchctlDisabled(out)
+	if err != nil {
+		t.Fatalf("ParseLaunchctlDisabled: %v", err)
+	}
+	if len(labels) != 2 || !labels["com.example.agent"] || labels["com.example.helper"] {
+		t.Fatalf("unexpected labels: %v", labels)
+	}
+}
+
+func TestParseLaunchctlPrintDomain(t *testing.T) {
+	out := `gui/501 = {
+	type = gui
+	handle = 501
+	services = {
+		     612      0    com.example.agent
+		       0     78    com.example.crashy
+	}
+
+	endpoints = {
+		"com.example.agent.xpc" = {
+			port = 0x1234
+		}
+	}
+
+	disabled services = {
+		"com.example.crashy" => disabled
+		"com.example.helper" => enabled
+	}
+}`
+	d, err := ParseLaunchctlPrintDomain(out)
+	if err != nil {
+		t.Fatalf("ParseLaunchctlPrintDomain: %v", err)
+	}
+	if len(d.Services) != 2 || d.Services["com.example.agent"].PID != 612 || d.Services["com.example.crashy"].Health() != "failing" {
+		t.Fatalf("unexpected services: %+v", d.Services)
+	}
+	if len(d.Disabled) != 2 || !d.Disabled["com.example.crashy"] || d.Disabled["com.example.helper"] {
+		t.Fatalf("unexpected disabled: %v", d.Disabled)
+	}
+}
+
+func TestParseKickstartPID(t *testing.T) {
+	cases := []struct {
+		out string
+		pid int
+		ok  bool
+	}{
+		{out: "4242\n", pid: 4242, ok: true},
+		{out: "service spawned with pid: 917\n", pid: 917, ok: true},
+		{out: "", ok: false},
+		{out: "not running", ok: false},
+	}
+
+	for _, tc := range cases {
+		pid, ok := parseKickstartPID(tc.out)
+		if pid != tc.pid || ok != tc.ok {
+			t.Fatalf("parseKickstartPID(%q) = %d, %v; want %d, %v", tc.out, pid, ok, tc.pid, tc.ok)
+		}
+	}
+}
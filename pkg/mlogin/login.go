@@ -0,0 +1,113 @@
+package mlogin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LoginItem is an app System Events opens at login.
+type LoginItem struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Hidden bool   `json:"hidden"`
+}
+
+// LoginItemsScript is the JavaScript for Automation that ListLoginItems
+// runs with `osascript -l JavaScript`. It prints the login items as JSON,
+// which ParseLoginItems reads.
+const LoginItemsScript = `
+ObjC.import('Cocoa');
+const se = Application('System Events');
+const items = se.loginItems();
+const out = items.map((item) => {
+  return {
+    name: item.name(),
+    path: item.path(),
+    hidden: item.hidden()
+  };
+});
+	$.NSFileHandle.fileHandleWithStandardOutput.writeData($(JSON.stringify(out) + "\n").dataUsingEncoding($.NSUTF8StringEncoding));
+`
+
+// ParseLoginItems reads LoginItemsScript's output, sorted by name.
+func ParseLoginItems(out string) ([]LoginItem, error) {
+	var items []LoginItem
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &items); err != nil {
+		return nil, fmt.Errorf("parse login items: %w", err)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+	return items, nil
+}
+
+// ListLoginItems asks System Events for the login items. It fails until
+// the calling process may control System Events (Privacy & Security >
+// Automation), and can wait on that prompt until ctx ends.
+func (c Client) ListLoginItems(ctx context.Context) ([]LoginItem, error) {
+	out, err := c.output(ctx, "osascript", "-l", "JavaScript", "-e", LoginItemsScript)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLoginItems(string(out))
+}
+
+// addLoginItemScript adds the app at ADD_PATH, replacing any login item
+// for the same path.
+const addLoginItemScript = `
+ObjC.import('stdlib');
+const se = Application('System Events');
+const path = $.getenv('ADD_PATH');
+const existing = se.loginItems.whose({path: path})();
+for (const item of existing) {
+  item.delete();
+}
+se.loginItems.push(se.LoginItem({path: path, hidden: $.getenv('ADD_HIDDEN') === 'true'}));
+`
+
+// removeLoginItemScript deletes every login item named REMOVE_NAME or at
+// REMOVE_PATH; an empty one matches nothing.
+const removeLoginItemScript = `
+ObjC.import('stdlib');
+const se = Application('System Events');
+const name = $.getenv('REMOVE_NAME');
+const path = $.getenv('REMOVE_PATH');
+let removed = 0;
+const all = se.loginItems();
+for (const item of all) {
+  const matchesName = name !== '' && item.name() === name;
+  const matchesPath = path !== '' && item.path() === path;
+  if (matchesName || matchesPath) {
+    item.delete();
+    removed += 1;
+  }
+}
+if (removed === 0) {
+  throw new Error('no matching login item found');
+}
+`
+
+// AddLoginItem opens the app at path, which must be absolute, at login,
+// replacing an existing login item for it.
+func (c Client) AddLoginItem(ctx context.Context, path string, hidden bool) error {
+	env := map[string]string{"ADD_PATH": path, "ADD_HIDDEN": fmt.Sprint(hidden)}
+	_, err := c.Run(ctx, Command{Name: "osascript", Args: []string{"-l", "JavaScript", "-e", addLoginItemScript}, Env: env, Changes: true})
+	return err
+}
+
+// RemoveLoginItem removes the login items called name or at path; either
+// may be empty. When none match, the error matches ErrNotFound.
+func (c Client) RemoveLoginItem(ctx context.Context, name, path string) error {
+	// Both are always set: $.getenv throws for a variable that is unset.
+	env := map[string]string{"REMOVE_NAME": name, "REMOVE_PATH": path}
+	_, err := c.Run(ctx, Command{Name: "osascript", Args: []string{"-l", "JavaScript", "-e", removeLoginItemScript}, Env: env, Changes: true})
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "no matching login item") {
+		return notFoundError{err: err}
+	}
+	return err
+}